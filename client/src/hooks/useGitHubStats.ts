@@ -1,14 +1,14 @@
 import { useQuery } from '@tanstack/react-query'
 import api from '../services/api'
-import type { UserStats } from '../types/github'
+import type { CompareMode, UserStats } from '../types/github'
 
 export type StatsPeriod = 'week' | 'month' | 'year'
 
-export function useGitHubStats(period: StatsPeriod = 'year') {
+export function useGitHubStats(period: StatsPeriod = 'year', compare?: CompareMode) {
   return useQuery({
-    queryKey: ['user-stats', period],
+    queryKey: ['user-stats', period, compare],
     queryFn: async () => {
-      const { data } = await api.get<UserStats>('/user/stats', { params: { period } })
+      const { data } = await api.get<UserStats>('/user/stats', { params: { period, compare } })
       return data
     },
   })
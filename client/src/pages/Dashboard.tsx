@@ -11,6 +11,7 @@ import CommitsTimeline from '../components/dashboard/CommitsTimeline'
 import AnimatedCard from '../components/ui/AnimatedCard'
 import BeamsBackground from '../components/ui/BeamsBackground'
 import { templateRenderer } from '../core/templateInstance'
+import type { Delta, SyncProgress } from '../types/github'
 
 const PERIOD_LABELS: Record<StatsPeriod, string> = {
   week: 'Неделя',
@@ -18,6 +19,32 @@ const PERIOD_LABELS: Record<StatsPeriod, string> = {
   year: 'Год',
 }
 
+function syncProgressLabel(p?: SyncProgress): string {
+  switch (p?.stage) {
+    case 'repos':
+      return `Репозитории: ${p.repos ?? 0}...`
+    case 'manifests':
+      return 'Зависимости...'
+    case 'collaboration':
+      return 'Коллеги и ревью...'
+    case 'events':
+      return `События: ${p.events ?? 0}...`
+    default:
+      return 'Синхронизация...'
+  }
+}
+
+function TrendBadge({ delta }: { delta?: Delta }) {
+  if (!delta || delta.abs === 0) return null
+  const up = delta.abs > 0
+  const text = delta.percent !== null ? `${up ? '+' : ''}${delta.percent}%` : `${up ? '+' : ''}${delta.abs}`
+  return (
+    <span className={`ml-2 text-sm font-medium ${up ? 'text-emerald-400' : 'text-rose-400'}`}>
+      {up ? '▲' : '▼'} {text}
+    </span>
+  )
+}
+
 function getApiErrorMessage(err: unknown): string {
   const e = err as { response?: { data?: { error?: string; detail?: string } }; message?: string }
   return e?.response?.data?.error ?? e?.response?.data?.detail ?? (e?.message as string) ?? 'Неизвестная ошибка'
@@ -27,24 +54,36 @@ export default function Dashboard() {
   const queryClient = useQueryClient()
   const { user } = useAuth()
   const [period, setPeriod] = useState<StatsPeriod>('year')
-  const { data: stats, isLoading, error } = useGitHubStats(period)
+  const { data: stats, isLoading, error } = useGitHubStats(period, 'previous')
+  // Синхронизация идёт в worker: POST /user/sync отвечает 202, ход приходит по websocket
+  const [syncJob, setSyncJob] = useState<{ id: string; progress?: SyncProgress; error?: string } | null>(null)
   const onWsMessage = useCallback(
-    (event: string) => {
+    (event: string, data: unknown) => {
+      const p = data as SyncProgress
       if (event === 'stats_updated') {
         queryClient.invalidateQueries({ queryKey: ['user-stats'] })
         queryClient.invalidateQueries({ queryKey: ['user'] })
+      } else if (event === 'sync_started' || event === 'sync_progress') {
+        setSyncJob({ id: p.job_id, progress: p })
+      } else if (event === 'sync_finished') {
+        setSyncJob(null)
+      } else if (event === 'sync_failed') {
+        setSyncJob({ id: p.job_id, error: p.error })
       }
     },
     [queryClient],
   )
   useWebSocket(user?.id ?? null, onWsMessage)
   const sync = useMutation({
-    mutationFn: () => api.post('/user/sync'),
-    onSuccess: () => {
-      queryClient.invalidateQueries({ queryKey: ['user-stats'] })
-      queryClient.invalidateQueries({ queryKey: ['user'] })
-    },
+    // 409 sync_in_progress — синхронизация уже идёт (другая вкладка, плановая): следим за ней
+    mutationFn: () =>
+      api.post<{ job_id: string }>('/user/sync', undefined, {
+        validateStatus: (s) => s === 202 || s === 409,
+      }),
+    onSuccess: (res) => setSyncJob({ id: res.data.job_id }),
   })
+  const syncing = sync.isPending || (syncJob !== null && !syncJob.error)
+  const syncError = sync.isError ? getApiErrorMessage(sync.error) : syncJob?.error
 
   const hasNoData = stats && stats.total_repos === 0 && stats.contribution_sum === 0
 
@@ -91,13 +130,13 @@ export default function Dashboard() {
         </div>
       </div>
 
-      {sync.isError && (
+      {syncError && (
         <AnimatedCard className="border-error/50 bg-error/10">
-          <p className="mb-2 text-error">Ошибка синхронизации: {getApiErrorMessage(sync.error)}</p>
+          <p className="mb-2 text-error">Ошибка синхронизации: {syncError}</p>
           <p className="mb-4 text-sm text-slate-400">Проверьте доступ к GitHub или попробуйте позже.</p>
           <button
             onClick={() => sync.mutate()}
-            disabled={sync.isPending}
+            disabled={syncing}
             className="rounded-lg bg-primary px-4 py-2 text-white hover:bg-primary/90 disabled:opacity-50"
           >
             Повторить
@@ -105,17 +144,17 @@ export default function Dashboard() {
         </AnimatedCard>
       )}
 
-      {hasNoData && !sync.isError && (
+      {hasNoData && !syncError && (
         <AnimatedCard className="border-primary/50 bg-primary/10">
           <p className="mb-4 text-slate-300">
             Данные с GitHub ещё не загружены. Нажмите «Синхронизировать», чтобы подтянуть репозитории и статистику.
           </p>
           <button
             onClick={() => sync.mutate()}
-            disabled={sync.isPending}
+            disabled={syncing}
             className="rounded-lg bg-primary px-4 py-2 font-medium text-white hover:bg-primary/90 disabled:opacity-50"
           >
-            {sync.isPending ? 'Синхронизация...' : 'Синхронизировать с GitHub'}
+            {syncing ? syncProgressLabel(syncJob?.progress) : 'Синхронизировать с GitHub'}
           </button>
         </AnimatedCard>
       )}
@@ -135,7 +174,10 @@ export default function Dashboard() {
         </AnimatedCard>
         <AnimatedCard>
           <p className="text-sm text-slate-400">Контрибуции ({PERIOD_LABELS[period].toLowerCase()})</p>
-          <p className="text-2xl font-bold text-white">{stats.contribution_sum}</p>
+          <p className="text-2xl font-bold text-white">
+            {stats.contribution_sum}
+            <TrendBadge delta={stats.comparison?.deltas.contributions} />
+          </p>
         </AnimatedCard>
       </div>
       <AnimatedCard>
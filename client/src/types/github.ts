@@ -5,11 +5,14 @@ export interface User {
   email?: string
   avatar_url?: string
   last_synced_at?: string
+  reauth_required: boolean
+  timezone: string
   created_at: string
   updated_at: string
 }
 
 export interface UserStats {
+  period: PeriodRange
   total_repos: number
   total_stars: number
   total_forks: number
@@ -18,11 +21,123 @@ export interface UserStats {
   top_repos: Repo[]
   daily_stats: DailyStats[]
   contribution_sum: number
+  totals: PeriodTotals
+  streaks: Streaks
+  work_pattern: WorkPattern
+  goals: GoalProgress[]
+  comparison?: PeriodComparison
+  tech_stack: TechStack
+}
+
+export interface PeriodTotals {
+  contributions: number
+  commits: number
+  prs: number
+  issues: number
+  stars_received: number
+  active_days: number
+}
+
+export interface PeriodRange {
+  name: string
+  from: string
+  to: string
+}
+
+export interface WorkPattern {
+  weekend_ratio: number
+  after_hours_ratio: number
+  timed_contributions: number
+}
+
+export interface Punchcard extends WorkPattern {
+  period: PeriodRange
+  matrix: number[][]
+}
+
+export type CompareMode = 'previous' | 'same_period_last_year'
+
+export interface PeriodSnapshot {
+  from: string
+  to: string
+  totals: PeriodTotals
+  languages: LanguageShare[]
+}
+
+export interface LanguageShare {
+  language: string
+  contributions: number
+  percent: number
+}
+
+export interface Delta {
+  abs: number
+  percent: number | null
+}
+
+export interface LanguageDelta {
+  language: string
+  current: number
+  previous: number
+  delta: number
+}
+
+export interface PeriodComparison {
+  compare: CompareMode
+  current: PeriodSnapshot
+  previous: PeriodSnapshot
+  deltas: {
+    contributions: Delta
+    commits: Delta
+    prs: Delta
+    issues: Delta
+    stars_received: Delta
+    active_days: Delta
+    languages: LanguageDelta[]
+  }
+}
+
+export interface Streak {
+  start?: string
+  end?: string
+  length: number
+  days: number
+}
+
+export interface Streaks {
+  current: Streak
+  longest: Streak
+  history: Streak[]
+  min_length: number
+  rest_weekends: boolean
+}
+
+export interface StreakSettings {
+  rest_weekends: boolean
+  min_length: number
+}
+
+export interface RestDay {
+  date: string
+  note?: string
+}
+
+export interface TechItem {
+  name: string
+  ecosystem: string
+  repos: number
+}
+
+export interface TechStack {
+  frameworks: TechItem[]
+  libraries: TechItem[]
 }
 
 export interface ContributionDay {
   date: string
   count: number
+  // 'week' — сырые дни уже удалены, точка за неделю с понедельника date
+  granularity?: 'week'
 }
 
 export interface LanguageStats {
@@ -42,6 +157,18 @@ export interface Repo {
   forks: number
   language: string
   is_private: boolean
+  is_fork: boolean
+  is_archived: boolean
+  is_template: boolean
+  topics: string[]
+  license?: string
+  size_kb: number
+  open_issues: number
+  homepage?: string
+  default_branch?: string
+  created_at?: string
+  pushed_at?: string
+  updated_at?: string
 }
 
 export interface DailyStats {
@@ -51,4 +178,288 @@ export interface DailyStats {
   prs: number
   issues: number
   stars_received: number
+  granularity?: 'week'
+}
+
+export interface Collaborator {
+  login: string
+  avatar_url?: string
+  shared_repos: number
+  shared_commits: number
+  reviews_given: number
+  reviews_received: number
+  pull_requests: number
+  weight: number
+}
+
+export interface CollaborationGraph {
+  nodes: { id: string; login: string; avatar_url?: string; is_self: boolean; weight: number }[]
+  edges: { source: string; target: string; repos: number; commits: number; reviews: number; prs: number; weight: number }[]
+}
+
+export type SyncScheduleMode = 'adaptive' | 'interval' | 'cron'
+
+export interface SyncSchedule {
+  mode: SyncScheduleMode
+  interval_minutes?: number
+  cron?: string
+  timezone: string
+  next_run_at?: string
+  last_enqueued_at?: string
+  effective_interval_minutes?: number
+}
+
+export interface SyncJob {
+  id: string
+  type: string
+  status: 'pending' | 'running' | 'done' | 'failed'
+  attempts: number
+  max_attempts: number
+  last_error?: string
+  run_at: string
+  created_at: string
+  processed_at?: string
+}
+
+export interface SyncProgress {
+  job_id: string
+  stage?: 'repos' | 'manifests' | 'collaboration' | 'events'
+  page?: number
+  repos?: number
+  events?: number
+  error?: string
+}
+
+export interface WorkerStatus {
+  leader: { name: string; holder: string; acquired_at: string; renewed_at: string; expires_at: string } | null
+  alive: boolean
+}
+
+export interface BackfillState {
+  status: 'none' | 'pending' | 'running' | 'done' | 'failed'
+  years?: number
+  range_start?: string
+  range_end?: string
+  cursor?: string
+  chunks_done?: number
+  chunks_total?: number
+  commits_found?: number
+  job_id?: string
+  last_error?: string
+  started_at?: string
+  finished_at?: string
+}
+
+export interface ReportSubscription {
+  id: string
+  format: 'pdf' | 'markdown'
+  period: 'week' | 'month' | 'year'
+  cadence: 'daily' | 'weekly' | 'monthly'
+  recipients: string[]
+  active: boolean
+  next_run_at?: string
+  last_sent_at?: string
+  created_at: string
+}
+
+export interface ReportDelivery {
+  id: string
+  subscription_id: string
+  status: 'pending' | 'sent' | 'failed'
+  recipients: string[]
+  attempts: number
+  error?: string
+  job_id?: string
+  created_at: string
+  sent_at?: string
+}
+
+export type SignalKind = 'late_night_spike' | 'weekend_spike' | 'activity_drop' | 'pr_volume'
+
+export interface ActivitySignal {
+  id?: string
+  kind: SignalKind
+  severity: 'medium' | 'high'
+  window_start: string
+  window_end: string
+  value: number
+  baseline: number
+  stddev: number
+  z_score: number
+  explanation: string
+  created_at?: string
+}
+
+export interface SignalsReport {
+  active: ActivitySignal[]
+  history: ActivitySignal[]
+}
+
+export type GoalMetric = 'commits' | 'contributions' | 'prs_opened' | 'prs_merged' | 'issues' | 'active_days'
+
+export interface Goal {
+  id: string
+  metric: GoalMetric
+  target: number
+  period: 'week' | 'month'
+  active: boolean
+  created_at: string
+}
+
+export interface GoalProgress {
+  goal_id: string
+  metric: GoalMetric
+  target: number
+  period: 'week' | 'month'
+  period_start: string
+  period_end: string
+  value: number
+  percent: number
+  met: boolean
+}
+
+export interface GoalPeriod {
+  period_start: string
+  period_end: string
+  value: number
+  target: number
+  status: 'met' | 'missed' | 'in_progress'
+  reached_at?: string
+}
+
+export type AchievementMetric = 'stars' | 'longest_streak' | 'languages' | 'external_merged_prs' | 'repos'
+
+export interface Achievement {
+  id: string
+  title: string
+  description: string
+  badge: string
+  metric: AchievementMetric
+  threshold: number
+  progress: number
+  percent: number
+  unlocked_at?: string
+}
+
+export interface Achievements {
+  earned: Achievement[]
+  locked: Achievement[]
+}
+
+export type TeamRole = 'owner' | 'admin' | 'member'
+
+export interface Team {
+  id: string
+  name: string
+  owner_id: string
+  role: TeamRole
+  members: number
+  created_at: string
+}
+
+export interface TeamMember {
+  user_id: string
+  username: string
+  avatar_url?: string
+  role: TeamRole
+  joined_at: string
+}
+
+export interface TeamInvitation {
+  id: string
+  team_id: string
+  team_name: string
+  username: string
+  role: Exclude<TeamRole, 'owner'>
+  invited_by: string
+  status: 'pending' | 'accepted' | 'declined'
+  created_at: string
+  responded_at?: string
+}
+
+export interface TeamMemberStats {
+  user_id: string
+  username: string
+  role: TeamRole
+  totals: PeriodTotals
+  total_repos: number
+  total_stars: number
+  current_streak: number
+  longest_streak: number
+  work_pattern: WorkPattern
+  share: number
+}
+
+export interface TeamStats extends UserStats {
+  team: Team
+  members: TeamMemberStats[]
+}
+
+export type ProfileSection = 'totals' | 'contributions' | 'repos' | 'languages' | 'streaks' | 'achievements'
+
+export interface ProfileVisibility {
+  public: boolean
+  sections: ProfileSection[]
+  updated_at?: string
+}
+
+export interface PublicTotals {
+  contributions: number
+  commits: number
+  prs: number
+  issues: number
+  active_days: number
+  repos: number
+  stars: number
+}
+
+export interface PublicProfile {
+  username: string
+  avatar_url?: string
+  period: PeriodRange
+  sections: ProfileSection[]
+  totals?: PublicTotals
+  contributions?: ContributionDay[]
+  top_repos?: Repo[]
+  languages?: LanguageStats[]
+  streaks?: Streaks
+  achievements?: Achievement[]
+}
+
+export interface ComparedTotals {
+  contributions: number
+  commits: number
+  prs: number
+  issues: number
+  reviews: number
+  active_days: number
+}
+
+export interface ComparedSeries {
+  values: number[]
+  normalized: number[]
+}
+
+export interface ComparedStreaks {
+  current: number
+  longest: number
+}
+
+export interface ComparedUser {
+  username: string
+  avatar_url?: string
+  access: 'self' | 'team' | 'public'
+  sections: ProfileSection[]
+  totals?: ComparedTotals
+  series?: ComparedSeries
+  languages?: number[]
+  streaks?: ComparedStreaks
+}
+
+export interface Comparison {
+  period: PeriodRange
+  granularity: 'day' | 'week' | 'month'
+  buckets: string[]
+  languages: string[]
+  users: ComparedUser[]
 }
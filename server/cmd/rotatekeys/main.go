@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"github.com/joho/godotenv"
+	"github.com/devsync/server/internal/config"
+	"github.com/devsync/server/internal/infrastructure/database"
+	"github.com/devsync/server/internal/infrastructure/secrets"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+// Ротация ключа шифрования токенов:
+//  1. добавить новый ключ в TOKEN_ENCRYPTION_KEYS и сделать его активным, старый оставить в списке;
+//  2. перезапустить server и worker — новые записи шифруются новым ключом, старые читаются старым;
+//  3. запустить rotatekeys — все строки перешифровываются под активный ключ;
+//  4. убрать старый ключ из конфигурации.
+func main() {
+	batch := flag.Int("batch", 100, "users per batch")
+	flag.Parse()
+
+	_ = godotenv.Overload(".env")
+	_ = godotenv.Overload("../.env")
+	cfg := config.Load()
+	keys, err := secrets.Load(cfg.Encryption.Keys, cfg.Encryption.KeyFile, cfg.Encryption.ActiveKey, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	pool, err := database.NewPool(ctx, cfg.Database.URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	n, err := user.NewRepository(pool, keys).RotateTokens(ctx, *batch)
+	if err != nil {
+		log.Fatalf("rotatekeys: %v (rotated %d users before failure)", err, n)
+	}
+	log.Printf("rotatekeys: re-encrypted tokens of %d users with key %s", n, keys.ActiveKeyID())
+}
@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // часовые пояса пользователей в alpine-образе без zoneinfo
 
 	"github.com/joho/godotenv"
 	"github.com/devsync/server/internal/app"
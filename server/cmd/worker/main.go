@@ -2,16 +2,34 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // часовые пояса пользователей в alpine-образе без zoneinfo
 	"github.com/devsync/server/internal/config"
 	"github.com/devsync/server/internal/infrastructure/database"
+	"github.com/devsync/server/internal/infrastructure/events"
+	"github.com/devsync/server/internal/infrastructure/queue"
+	"github.com/devsync/server/internal/infrastructure/lease"
+	"github.com/devsync/server/internal/infrastructure/secrets"
 	"github.com/devsync/server/internal/domain/user"
 	"github.com/devsync/server/internal/domain/stats"
 	"github.com/devsync/server/internal/domain/github"
+	"github.com/devsync/server/internal/domain/collab"
+	"github.com/devsync/server/internal/domain/schedule"
+	"github.com/devsync/server/internal/domain/backfill"
+	"github.com/devsync/server/internal/domain/report"
+	"github.com/devsync/server/internal/domain/signals"
+	"github.com/devsync/server/internal/domain/goals"
+	"github.com/devsync/server/internal/domain/achievements"
+	"github.com/devsync/server/internal/infrastructure/mail"
+	"github.com/devsync/server/pkg/pdf"
+	githublib "github.com/devsync/server/pkg/github"
 )
 
 func main() {
@@ -23,51 +41,158 @@ func main() {
 	}
 	defer pool.Close()
 
-	userRepo := user.NewRepository(pool)
+	keys, err := secrets.Load(cfg.Encryption.Keys, cfg.Encryption.KeyFile, cfg.Encryption.ActiveKey, cfg.Encryption.Disabled)
+	if err != nil {
+		log.Fatal(err)
+	}
+	userRepo := user.NewRepository(pool, keys)
 	userSvc := user.NewService(userRepo)
 	repoRepo := stats.NewRepoRepository(pool)
 	contribRepo := stats.NewContributionRepository(pool)
 	dailyRepo := stats.NewDailyStatsRepository(pool)
-	syncSvc := github.NewSyncService(userSvc, repoRepo, contribRepo, dailyRepo)
+	activityRepo := stats.NewActivityRepository(pool)
+	depRepo := stats.NewDependencyRepository(pool)
+	collabRepo := collab.NewRepository(pool)
+	var notifier github.Notifier
+	if pub, err := events.NewPublisher(cfg.Redis.URL); err != nil {
+		log.Printf("worker: events disabled: %v", err)
+	} else {
+		defer pub.Close()
+		notifier = pub
+	}
+	// Один бюджет на все горутины процесса
+	ghLimits := githublib.NewLimits(githublib.NewBudget(cfg.Worker.GitHubRequestsPerHour, cfg.Worker.GitHubBurst))
+	backfillRepo := backfill.NewRepository(pool)
+	leases := lease.NewPostgres(pool)
+	syncSvc := github.NewSyncService(userSvc, repoRepo, contribRepo, dailyRepo, activityRepo, depRepo, collabRepo, backfillRepo, cfg.GitHub.OAuth2(), ghLimits, leases, notifier)
 
-	// Первый запуск сразу после старта
-	runSync(ctx, userSvc, syncSvc)
+	signalSvc := signals.NewService(signals.NewRepository(pool), userSvc, notifier)
+	goalSvc := goals.NewService(goals.NewRepository(pool), userSvc, notifier)
+	rollupRepo := stats.NewRollupRepository(pool)
+	statsSvc := stats.NewService(repoRepo, contribRepo, dailyRepo, activityRepo, depRepo, rollupRepo, stats.NewStreakRepository(pool), userSvc)
+	achievementSvc := achievements.NewService(achievements.NewRepository(pool), statsSvc, syncSvc, notifier)
 
-	ticker := time.NewTicker(24 * time.Hour)
-	defer ticker.Stop()
-	go func() {
-		for range ticker.C {
-			runSync(ctx, userSvc, syncSvc)
+	jobs := queue.NewPostgres(pool)
+	backfillSvc := backfill.NewService(backfillRepo, jobs, cfg.Worker.BackfillYears)
+	hostname, _ := os.Hostname()
+	workerID := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	proc := queue.NewProcessor(jobs, workerID)
+	proc.Concurrency = cfg.Worker.Concurrency
+	proc.Register(queue.TypeSyncUser, func(ctx context.Context, job *queue.Job) error {
+		if job.UserID == nil {
+			return queue.Permanent(errors.New("sync_user job without user_id"))
 		}
-	}()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("worker stopping")
-}
-
-func runSync(ctx context.Context, userSvc user.Service, syncSvc github.SyncService) {
-	ids, err := userSvc.ListIDsWithToken(ctx)
-	if err != nil {
-		log.Printf("worker: list users: %v", err)
-		return
-	}
-	if len(ids) == 0 {
-		log.Println("worker: no users to sync")
-		return
-	}
-	log.Printf("worker: syncing %d users", len(ids))
-	for i, id := range ids {
-		if err := syncSvc.SyncUser(ctx, id); err != nil {
-			log.Printf("worker: sync user %s: %v", id, err)
-		} else {
-			log.Printf("worker: synced user %s (%d/%d)", id, i+1, len(ids))
+		err := syncSvc.SyncUser(ctx, *job.UserID, job.ID)
+		if err == nil {
+			// Сигналы, цели и достижения — по свежим данным; их ошибки синхронизацию не проваливают
+			if _, err := signalSvc.Check(ctx, *job.UserID); err != nil {
+				log.Printf("worker: signals for user %s: %v", job.UserID, err)
+			}
+			if err := goalSvc.Track(ctx, *job.UserID); err != nil {
+				log.Printf("worker: goals for user %s: %v", job.UserID, err)
+			}
+			if _, err := achievementSvc.Evaluate(ctx, *job.UserID); err != nil {
+				log.Printf("worker: achievements for user %s: %v", job.UserID, err)
+			}
+		}
+		if errors.Is(err, github.ErrSyncInProgress) {
+			// Пользователя уже синхронизирует другая задача — эта выполнится после неё
+			return queue.Postpone(err, time.Now().Add(30*time.Second))
+		}
+		if errors.Is(err, github.ErrReauthRequired) {
+			// Повторять бессмысленно до следующего входа пользователя
+			return queue.Permanent(err)
 		}
-		// Пауза между пользователями, чтобы не упереться в лимит GitHub API
-		if i < len(ids)-1 {
-			time.Sleep(3 * time.Second)
+		if githublib.IsRateLimited(err) {
+			log.Printf("worker: user %s hit GitHub rate limit, will retry", job.UserID)
 		}
+		return err
+	})
+	proc.Register(queue.TypeRebuildActivity, func(ctx context.Context, job *queue.Job) error {
+		if job.UserID == nil {
+			return queue.Permanent(errors.New("rebuild_activity job without user_id"))
+		}
+		if err := statsSvc.RebuildActivity(ctx, *job.UserID); err != nil {
+			return err
+		}
+		if notifier != nil {
+			notifier.BroadcastToUser(*job.UserID, github.EventStatsUpdated, nil)
+		}
+		return nil
+	})
+	proc.Register(queue.TypeBackfill, func(ctx context.Context, job *queue.Job) error {
+		if job.UserID == nil {
+			return queue.Permanent(errors.New("backfill job without user_id"))
+		}
+		res, err := syncSvc.Backfill(ctx, *job.UserID, job.ID)
+		if errors.Is(err, github.ErrReauthRequired) {
+			backfillSvc.Fail(context.Background(), *job.UserID, err)
+			return queue.Permanent(err)
+		}
+		if err != nil {
+			if job.Attempts >= job.MaxAttempts && ctx.Err() == nil {
+				backfillSvc.Fail(context.Background(), *job.UserID, err)
+			}
+			return err
+		}
+		if !res.Done {
+			// Продолжение — отдельной задачей: между порциями успевают пройти синхронизации
+			if _, err := backfillSvc.Continue(ctx, *job.UserID, res.ResumeAt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if cfg.SMTP.Host == "" {
+		log.Println("worker: SMTP_HOST not set, report deliveries will fail")
 	}
-	log.Println("worker: sync run done")
+	mailer := mail.NewSMTP(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.User, cfg.SMTP.Password, cfg.SMTP.From)
+	reportRepo := report.NewRepository(pool)
+	reportSvc := report.NewService(reportRepo, userSvc, statsSvc, goalSvc, achievementSvc, nil, nil, pdf.NewGenerator(), jobs, mailer)
+	proc.Register(queue.TypeGenerateReport, func(ctx context.Context, job *queue.Job) error {
+		deliveryID, err := report.PayloadDelivery(job)
+		if err != nil {
+			return queue.Permanent(err)
+		}
+		return reportSvc.Deliver(ctx, deliveryID, job.Attempts >= job.MaxAttempts)
+	})
+	retention := stats.NewRetention(rollupRepo, cfg.Worker.StatsRetentionDays)
+	proc.Register(queue.TypeRollupStats, func(ctx context.Context, job *queue.Job) error {
+		return retention.Run(ctx)
+	})
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Планировщики ставят в очередь синхронизации и отчёты, которым пора, и ночное обслуживание статистики.
+	// Работают только на реплике-лидере; задачи из очереди берут все реплики.
+	scheduler := schedule.NewScheduler(schedule.NewRepository(pool), jobs)
+	reportScheduler := report.NewScheduler(reportRepo, reportSvc)
+	elector := lease.NewElector(leases, lease.Scheduler, workerID, 30*time.Second)
+	electorDone := make(chan struct{})
+	go func() {
+		defer close(electorDone)
+		elector.Run(ctx, func(ctx context.Context) {
+			loops := []func(context.Context){
+				func(ctx context.Context) { scheduler.Run(ctx, time.Minute) },
+				func(ctx context.Context) { reportScheduler.Run(ctx, time.Minute) },
+				func(ctx context.Context) { retention.Schedule(ctx, jobs, time.Hour) },
+			}
+			var wg sync.WaitGroup
+			for _, loop := range loops {
+				wg.Add(1)
+				go func(loop func(context.Context)) {
+					defer wg.Done()
+					loop(ctx)
+				}(loop)
+			}
+			wg.Wait()
+		})
+	}()
+
+	log.Printf("worker: processing jobs with %d workers", proc.Concurrency)
+	proc.Run(ctx)
+	<-electorDone // аренда освобождена — другая реплика сразу станет лидером
+	// Сюда попадаем после SIGTERM, когда все начатые синхронизации прерваны и вернулись в очередь
+	log.Println("worker stopped")
 }
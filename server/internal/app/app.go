@@ -6,12 +6,25 @@ import (
 	"log"
 	"net/http"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/oauth2"
 	"github.com/devsync/server/internal/config"
+	"github.com/devsync/server/internal/infrastructure/cache"
 	"github.com/devsync/server/internal/infrastructure/database"
+	"github.com/devsync/server/internal/infrastructure/events"
+	"github.com/devsync/server/internal/infrastructure/queue"
+	"github.com/devsync/server/internal/infrastructure/lease"
+	"github.com/devsync/server/internal/infrastructure/secrets"
 	"github.com/devsync/server/internal/domain/user"
+	"github.com/devsync/server/internal/domain/collab"
+	"github.com/devsync/server/internal/domain/schedule"
+	"github.com/devsync/server/internal/domain/backfill"
+	"github.com/devsync/server/internal/domain/report"
+	"github.com/devsync/server/internal/domain/signals"
+	"github.com/devsync/server/internal/domain/goals"
+	"github.com/devsync/server/internal/domain/achievements"
+	"github.com/devsync/server/internal/domain/team"
+	"github.com/devsync/server/internal/domain/profile"
+	"github.com/devsync/server/internal/domain/compare"
 	"github.com/devsync/server/internal/domain/stats"
-	"github.com/devsync/server/internal/domain/github"
 	httptransport "github.com/devsync/server/internal/transport/http"
 	httphandlers "github.com/devsync/server/internal/transport/http/handlers"
 	"github.com/devsync/server/internal/transport/websocket"
@@ -21,6 +34,7 @@ import (
 type App struct {
 	cfg    *config.Config
 	server *http.Server
+	stop   context.CancelFunc
 }
 
 func New(ctx context.Context, cfg *config.Config) (*App, error) {
@@ -29,35 +43,69 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("database: %w", err)
 	}
 
-	userRepo := user.NewRepository(pool)
+	keys, err := secrets.Load(cfg.Encryption.Keys, cfg.Encryption.KeyFile, cfg.Encryption.ActiveKey, cfg.Encryption.Disabled)
+	if err != nil {
+		return nil, fmt.Errorf("token keys: %w", err)
+	}
+	if keys == nil {
+		log.Println("TOKEN_ENCRYPTION_DISABLED is set: GitHub tokens are stored unencrypted")
+	}
+	userRepo := user.NewRepository(pool, keys)
 	userSvc := user.NewService(userRepo)
 
 	repoRepo := stats.NewRepoRepository(pool)
 	contribRepo := stats.NewContributionRepository(pool)
 	dailyRepo := stats.NewDailyStatsRepository(pool)
-	statsSvc := stats.NewService(repoRepo, contribRepo, dailyRepo)
-
-	syncSvc := github.NewSyncService(userSvc, repoRepo, contribRepo, dailyRepo)
-
-	oauthCfg := &oauth2.Config{
-		ClientID:     cfg.GitHub.ClientID,
-		ClientSecret: cfg.GitHub.ClientSecret,
-		RedirectURL:  cfg.GitHub.RedirectURL,
-		Endpoint:     oauth2.Endpoint{
-			AuthURL:  "https://github.com/login/oauth/authorize",
-			TokenURL: "https://github.com/login/oauth/access_token",
-		},
-		Scopes: []string{"read:user", "user:email", "repo"},
-	}
+	activityRepo := stats.NewActivityRepository(pool)
+	depRepo := stats.NewDependencyRepository(pool)
+	statsSvc := stats.NewService(repoRepo, contribRepo, dailyRepo, activityRepo, depRepo, stats.NewRollupRepository(pool), stats.NewStreakRepository(pool), userSvc)
+	collabRepo := collab.NewRepository(pool)
+	collabSvc := collab.NewService(collabRepo, userSvc)
+	scheduleSvc := schedule.NewService(schedule.NewRepository(pool), userSvc)
+
+	oauthCfg := cfg.GitHub.OAuth2()
+
 
 	wsHub := websocket.NewHub()
-	authHandler := httphandlers.NewAuthHandler(oauthCfg, cfg.JWT.Secret, cfg.JWT.ExpireHours, userSvc)
-	userHandler := httphandlers.NewUserHandler(userSvc, statsSvc, syncSvc, wsHub)
-	statsHandler := httphandlers.NewStatsHandler(statsSvc)
+	// Синхронизации выполняет worker; сервер только ставит задачи в очередь
+	jobs := queue.NewPostgres(pool)
+	backfillSvc := backfill.NewService(backfill.NewRepository(pool), jobs, cfg.Worker.BackfillYears)
+	authHandler := httphandlers.NewAuthHandler(oauthCfg, cfg.JWT.Secret, cfg.JWT.ExpireHours, userSvc, backfillSvc)
+	leases := lease.NewPostgres(pool)
+	userHandler := httphandlers.NewUserHandler(userSvc, statsSvc, jobs, leases, wsHub)
+	// Итоги периодов и события goal_progress пишет worker после синхронизации
+	goalSvc := goals.NewService(goals.NewRepository(pool), userSvc, nil)
+	// Достижения проверяет worker после синхронизации; сервер показывает сохранённый прогресс
+	achievementSvc := achievements.NewService(achievements.NewRepository(pool), statsSvc, nil, nil)
+	statsHandler := httphandlers.NewStatsHandler(statsSvc, goalSvc)
+	teamSvc := team.NewService(team.NewRepository(pool), userSvc, statsSvc)
+	// Публичные профили кэшируются в Redis; без него каждый запрос считается заново
+	var profileCache cache.Cache
+	if rc, err := cache.NewRedis(cfg.Redis.URL); err != nil {
+		log.Printf("public profile cache disabled: %v", err)
+	} else {
+		profileCache = rc
+	}
+	profileSvc := profile.NewService(profile.NewRepository(pool), userSvc, statsSvc, achievementSvc, profileCache)
+	compareSvc := compare.NewService(compare.NewRepository(pool), userSvc, statsSvc, teamSvc, profileSvc)
 	pdfGen := pdf.NewGenerator()
-	reportsHandler := httphandlers.NewReportsHandler(userSvc, statsSvc, pdfGen)
+	// Письма отправляет worker; сервер только создаёт подписки и ставит отправки в очередь
+	reportSvc := report.NewService(report.NewRepository(pool), userSvc, statsSvc, goalSvc, achievementSvc, teamSvc, compareSvc, pdfGen, jobs, nil)
+	reportsHandler := httphandlers.NewReportsHandler(reportSvc)
+	collabHandler := httphandlers.NewCollaborationHandler(collabSvc)
+	scheduleHandler := httphandlers.NewScheduleHandler(scheduleSvc)
+	workerHandler := httphandlers.NewWorkerHandler(leases)
+	backfillHandler := httphandlers.NewBackfillHandler(backfillSvc)
+	goalsHandler := httphandlers.NewGoalsHandler(goalSvc)
+	// Новые сигналы сохраняет и рассылает worker после синхронизации; сервер только показывает их
+	signalsHandler := httphandlers.NewSignalsHandler(signals.NewService(signals.NewRepository(pool), userSvc, nil))
 
-	router := httptransport.NewRouter(authHandler, userHandler, statsHandler, reportsHandler, cfg.JWT.Secret, wsHub)
+	achievementsHandler := httphandlers.NewAchievementsHandler(achievementSvc)
+	teamsHandler := httphandlers.NewTeamsHandler(teamSvc, reportSvc)
+	profileHandler := httphandlers.NewProfileHandler(profileSvc)
+	compareHandler := httphandlers.NewCompareHandler(compareSvc)
+
+	router := httptransport.NewRouter(authHandler, userHandler, statsHandler, reportsHandler, collabHandler, scheduleHandler, workerHandler, backfillHandler, signalsHandler, goalsHandler, achievementsHandler, teamsHandler, profileHandler, compareHandler, cfg.JWT.Secret, wsHub)
 
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
@@ -67,7 +115,15 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 	addr := ":" + cfg.Server.Port
 	server := &http.Server{Addr: addr, Handler: engine}
 
-	return &App{cfg: cfg, server: server}, nil
+	// События из worker (reauth_required и т.п.) приходят через Redis
+	relayCtx, stop := context.WithCancel(context.Background())
+	go func() {
+		if err := events.Relay(relayCtx, cfg.Redis.URL, wsHub); err != nil {
+			log.Printf("events relay disabled: %v", err)
+		}
+	}()
+
+	return &App{cfg: cfg, server: server, stop: stop}, nil
 }
 
 func (a *App) Run() error {
@@ -76,5 +132,6 @@ func (a *App) Run() error {
 }
 
 func (a *App) Shutdown(ctx context.Context) error {
+	a.stop()
 	return a.server.Shutdown(ctx)
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"golang.org/x/oauth2"
 )
 
 type Config struct {
@@ -11,6 +12,9 @@ type Config struct {
 	Redis    RedisConfig
 	GitHub   GitHubConfig
 	JWT      JWTConfig
+	Encryption EncryptionConfig
+	Worker   WorkerConfig
+	SMTP     SMTPConfig
 }
 
 type ServerConfig struct {
@@ -31,6 +35,50 @@ type GitHubConfig struct {
 	RedirectURL  string
 }
 
+// OAuth2 — конфигурация OAuth-приложения GitHub (логин на сервере, refresh токенов в worker).
+func (g GitHubConfig) OAuth2() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     g.ClientID,
+		ClientSecret: g.ClientSecret,
+		RedirectURL:  g.RedirectURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://github.com/login/oauth/authorize",
+			TokenURL: "https://github.com/login/oauth/access_token",
+		},
+		Scopes: []string{"read:user", "user:email", "repo"},
+	}
+}
+
+// EncryptionConfig — мастер-ключи для шифрования токенов GitHub в БД.
+// Keys: "v2:base64,v1:base64"; KeyFile: по ключу "id:base64" на строку; ActiveKey — id для новых записей
+// (по умолчанию первый указанный). Старые ключи оставляют в списке, пока не отработает cmd/rotatekeys.
+// Без ключей server и worker не стартуют; хранить токены открыто можно только явно, через Disabled.
+type EncryptionConfig struct {
+	Keys      string
+	KeyFile   string
+	ActiveKey string
+	Disabled  bool
+}
+
+// WorkerConfig — параллелизм worker и общий бюджет запросов к GitHub API.
+// Бюджет действует в пределах процесса; лимит каждого токена (5000/ч) отслеживается отдельно.
+type WorkerConfig struct {
+	Concurrency           int
+	GitHubRequestsPerHour int // 0 — без общего ограничения
+	GitHubBurst           int
+	BackfillYears         int // сколько лет истории загружать новым пользователям
+	StatsRetentionDays    int // сколько дней хранить сырые contributions и daily_stats; 0 — бессрочно
+}
+
+// SMTPConfig — отправка отчётов по почте. Для локальной проверки подойдёт MailHog (localhost:1025, без пароля).
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	From     string
+}
+
 type JWTConfig struct {
 	Secret     string
 	ExpireHours int
@@ -72,6 +120,26 @@ func Load() *Config {
 			Secret:      getEnv("JWT_SECRET", "devsync-jwt-secret"),
 			ExpireHours: jwtExpire,
 		},
+		Encryption: EncryptionConfig{
+			Keys:      os.Getenv("TOKEN_ENCRYPTION_KEYS"),
+			KeyFile:   os.Getenv("TOKEN_ENCRYPTION_KEY_FILE"),
+			ActiveKey: os.Getenv("TOKEN_ENCRYPTION_ACTIVE_KEY"),
+			Disabled:  getEnvBool("TOKEN_ENCRYPTION_DISABLED"),
+		},
+		SMTP: SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     getEnv("SMTP_PORT", "587"),
+			User:     os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("FROM_EMAIL"),
+		},
+		Worker: WorkerConfig{
+			Concurrency:           getEnvInt("WORKER_CONCURRENCY", 4),
+			GitHubRequestsPerHour: getEnvInt("GITHUB_REQUESTS_PER_HOUR", 15000),
+			GitHubBurst:           getEnvInt("GITHUB_REQUEST_BURST", 50),
+			BackfillYears:         getEnvInt("BACKFILL_YEARS", 3),
+			StatsRetentionDays:    getEnvInt("STATS_RETENTION_DAYS", 730),
+		},
 	}
 }
 
@@ -81,3 +149,15 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvBool(key string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(key))
+	return v
+}
+
+func getEnvInt(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
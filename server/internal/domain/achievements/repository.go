@@ -0,0 +1,83 @@
+package achievements
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Record — сохранённый прогресс по одному достижению.
+type Record struct {
+	Achievement string
+	Progress    int
+	UnlockedAt  *time.Time
+}
+
+// Facts — метрики из данных синхронизации по собственным репозиториям (звёзды, языки, репозитории).
+type Facts struct {
+	Stars     int
+	Languages int
+	Repos     int
+}
+
+type Repository interface {
+	Facts(ctx context.Context, userID uuid.UUID) (*Facts, error)
+	List(ctx context.Context, userID uuid.UUID) ([]Record, error)
+	// Save обновляет прогресс; true — достижение открыто этим вызовом. Открытое остаётся открытым.
+	Save(ctx context.Context, userID uuid.UUID, achievement string, progress, threshold int) (bool, error)
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+func (r *repo) Facts(ctx context.Context, userID uuid.UUID) (*Facts, error) {
+	// /user/repos отдаёт и чужие репозитории (коллаборатор, член организации) — считаем только свои
+	var f Facts
+	err := r.pool.QueryRow(ctx, `SELECT COALESCE(SUM(r.stars), 0), COUNT(DISTINCT r.language), COUNT(r.id)
+		FROM repositories r JOIN users u ON u.id = r.user_id
+		WHERE r.user_id = $1 AND NOT r.is_fork
+			AND LOWER(split_part(r.full_name, '/', 1)) = LOWER(u.username)`, userID).Scan(&f.Stars, &f.Languages, &f.Repos)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *repo) List(ctx context.Context, userID uuid.UUID) ([]Record, error) {
+	rows, err := r.pool.Query(ctx, `SELECT achievement, progress, unlocked_at
+		FROM user_achievements WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Record
+	for rows.Next() {
+		var rec Record
+		if err := rows.Scan(&rec.Achievement, &rec.Progress, &rec.UnlockedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, rec)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) Save(ctx context.Context, userID uuid.UUID, achievement string, progress, threshold int) (bool, error) {
+	var unlocked bool
+	err := r.pool.QueryRow(ctx, `WITH prev AS (
+			SELECT unlocked_at FROM user_achievements WHERE user_id = $1 AND achievement = $2)
+		INSERT INTO user_achievements (user_id, achievement, progress, unlocked_at)
+		VALUES ($1, $2, $3, CASE WHEN $3::int >= $4::int THEN NOW() END)
+		ON CONFLICT (user_id, achievement) DO UPDATE SET
+			progress = EXCLUDED.progress,
+			unlocked_at = COALESCE(user_achievements.unlocked_at, EXCLUDED.unlocked_at),
+			updated_at = NOW()
+		RETURNING unlocked_at IS NOT NULL AND (SELECT unlocked_at FROM prev) IS NULL`,
+		userID, achievement, progress, threshold).Scan(&unlocked)
+	return unlocked, err
+}
@@ -0,0 +1,34 @@
+package achievements
+
+// Метрики, по которым считаются достижения.
+const (
+	MetricStars       = "stars"          // звёзды на собственных (не форкнутых) репозиториях
+	MetricStreak      = "longest_streak" // самая длинная серия дней с вкладами
+	MetricLanguages   = "languages"      // основные языки собственных репозиториев
+	MetricExternalPRs = "external_merged_prs"
+	MetricRepos       = "repos"
+)
+
+// Rule — достижение открывается, когда метрика Metric достигает Threshold.
+type Rule struct {
+	ID          string
+	Title       string
+	Description string
+	Badge       string
+	Metric      string
+	Threshold   int
+}
+
+// Rules — все достижения в порядке показа. ID хранятся в БД: менять их нельзя.
+var Rules = []Rule{
+	{ID: "first_external_pr", Title: "First Contribution", Description: "First merged pull request to someone else's repository", Badge: "🤝", Metric: MetricExternalPRs, Threshold: 1},
+	{ID: "external_prs_10", Title: "Open Source Regular", Description: "10 merged pull requests to other people's repositories", Badge: "🌍", Metric: MetricExternalPRs, Threshold: 10},
+	{ID: "stars_100", Title: "Rising Star", Description: "100 stars across your repositories", Badge: "⭐", Metric: MetricStars, Threshold: 100},
+	{ID: "stars_1000", Title: "Constellation", Description: "1000 stars across your repositories", Badge: "🌟", Metric: MetricStars, Threshold: 1000},
+	{ID: "streak_7", Title: "Week Streak", Description: "Contributions 7 days in a row", Badge: "🔥", Metric: MetricStreak, Threshold: 7},
+	{ID: "streak_30", Title: "Month Streak", Description: "Contributions 30 days in a row", Badge: "📅", Metric: MetricStreak, Threshold: 30},
+	{ID: "streak_100", Title: "Unstoppable", Description: "Contributions 100 days in a row", Badge: "💯", Metric: MetricStreak, Threshold: 100},
+	{ID: "languages_5", Title: "Polyglot", Description: "Repositories in 5 languages", Badge: "🗣️", Metric: MetricLanguages, Threshold: 5},
+	{ID: "languages_10", Title: "Babel", Description: "Repositories in 10 languages", Badge: "🧩", Metric: MetricLanguages, Threshold: 10},
+	{ID: "repos_10", Title: "Builder", Description: "10 repositories of your own", Badge: "🏗️", Metric: MetricRepos, Threshold: 10},
+}
@@ -0,0 +1,134 @@
+package achievements
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+// EventUnlocked — websocket-событие об открытом достижении (из worker — через Redis).
+const EventUnlocked = "achievement_unlocked"
+
+type Notifier interface {
+	BroadcastToUser(userID uuid.UUID, event string, data interface{})
+}
+
+// StreakProvider — серии считает stats.Service (с учётом выходных и отпусков пользователя).
+type StreakProvider interface {
+	GetStreaks(ctx context.Context, userID uuid.UUID) (*models.Streaks, error)
+}
+
+// ExternalPRCounter — число смёрженных PR в чужие репозитории (github.SyncService, запрос к GitHub).
+type ExternalPRCounter interface {
+	MergedExternalPRs(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+type Service interface {
+	// List — полученные и закрытые достижения с прогрессом на момент последней проверки.
+	List(ctx context.Context, userID uuid.UUID) (*models.Achievements, error)
+	// Evaluate проверяет правила после синхронизации и рассылает событие о каждом новом достижении.
+	Evaluate(ctx context.Context, userID uuid.UUID) ([]models.Achievement, error)
+}
+
+type service struct {
+	repo     Repository
+	streaks  StreakProvider
+	external ExternalPRCounter
+	notifier Notifier
+}
+
+// NewService: external и notifier могут быть nil — сервер только показывает сохранённый прогресс.
+func NewService(repo Repository, streaks StreakProvider, external ExternalPRCounter, notifier Notifier) Service {
+	return &service{repo: repo, streaks: streaks, external: external, notifier: notifier}
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) (*models.Achievements, error) {
+	records, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]Record, len(records))
+	for _, rec := range records {
+		byID[rec.Achievement] = rec
+	}
+	out := &models.Achievements{Earned: []models.Achievement{}, Locked: []models.Achievement{}}
+	for _, rule := range Rules {
+		rec := byID[rule.ID]
+		a := achievement(rule, rec.Progress)
+		a.UnlockedAt = rec.UnlockedAt
+		if a.UnlockedAt != nil {
+			// Открытое достижение не закрывается, даже если метрика потом упала (удалили репозиторий)
+			a.Percent = 100
+			out.Earned = append(out.Earned, a)
+		} else {
+			out.Locked = append(out.Locked, a)
+		}
+	}
+	sort.SliceStable(out.Earned, func(i, j int) bool { return out.Earned[i].UnlockedAt.After(*out.Earned[j].UnlockedAt) })
+	sort.SliceStable(out.Locked, func(i, j int) bool { return out.Locked[i].Percent > out.Locked[j].Percent })
+	return out, nil
+}
+
+func (s *service) Evaluate(ctx context.Context, userID uuid.UUID) ([]models.Achievement, error) {
+	metrics, err := s.metrics(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var unlocked []models.Achievement
+	for _, rule := range Rules {
+		value, ok := metrics[rule.Metric]
+		if !ok {
+			continue
+		}
+		fresh, err := s.repo.Save(ctx, userID, rule.ID, value, rule.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		if !fresh {
+			continue
+		}
+		a := achievement(rule, value)
+		unlocked = append(unlocked, a)
+		if s.notifier != nil {
+			s.notifier.BroadcastToUser(userID, EventUnlocked, a)
+		}
+	}
+	return unlocked, nil
+}
+
+// metrics собирает значения метрик; метрика, которую не удалось посчитать, пропускается вместе с её правилами.
+func (s *service) metrics(ctx context.Context, userID uuid.UUID) (map[string]int, error) {
+	facts, err := s.repo.Facts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]int{
+		MetricStars:     facts.Stars,
+		MetricLanguages: facts.Languages,
+		MetricRepos:     facts.Repos,
+	}
+	if st, err := s.streaks.GetStreaks(ctx, userID); err != nil {
+		log.Printf("achievements: streaks for user %s: %v", userID, err)
+	} else {
+		out[MetricStreak] = st.Longest.Length
+	}
+	if s.external != nil {
+		if n, err := s.external.MergedExternalPRs(ctx, userID); err != nil {
+			log.Printf("achievements: external PRs for user %s: %v", userID, err)
+		} else {
+			out[MetricExternalPRs] = n
+		}
+	}
+	return out, nil
+}
+
+func achievement(rule Rule, progress int) models.Achievement {
+	return models.Achievement{
+		ID: rule.ID, Title: rule.Title, Description: rule.Description, Badge: rule.Badge,
+		Metric: rule.Metric, Threshold: rule.Threshold, Progress: progress,
+		Percent: math.Min(100, math.Round(float64(progress)*1000/float64(rule.Threshold))/10),
+	}
+}
@@ -0,0 +1,156 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// State — прогресс загрузки истории. Идём от RangeEnd назад помесячно; Cursor — начало последнего
+// обработанного месяца, с него продолжаем после перезапуска.
+type State struct {
+	UserID       uuid.UUID  `json:"-"`
+	Status       string     `json:"status"`
+	Years        int        `json:"years"`
+	RangeStart   *time.Time `json:"range_start,omitempty"`
+	RangeEnd     *time.Time `json:"range_end,omitempty"`
+	Cursor       *time.Time `json:"cursor,omitempty"`
+	ChunksDone   int        `json:"chunks_done"`
+	ChunksTotal  int        `json:"chunks_total"`
+	CommitsFound int        `json:"commits_found"`
+	LastJobID    *uuid.UUID `json:"job_id,omitempty"`
+	LastError    *string    `json:"last_error,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+type RepoRef struct {
+	GitHubID int64
+	FullName string
+}
+
+type Repository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*State, error) // nil — загрузка не запускалась
+	// Init создаёт состояние, если его нет; false — уже было.
+	Init(ctx context.Context, userID uuid.UUID, years int) (bool, error)
+	// Reset начинает загрузку заново (ручной перезапуск).
+	Reset(ctx context.Context, userID uuid.UUID, years int) error
+	SetJob(ctx context.Context, userID, jobID uuid.UUID) error
+	Begin(ctx context.Context, userID uuid.UUID, start, end time.Time, chunks int) error
+	Checkpoint(ctx context.Context, userID uuid.UUID, cursor time.Time, commits int) error
+	Finish(ctx context.Context, userID uuid.UUID, cause error) error
+	// EventsStart — время самого раннего события из Events API; раньше него данных нет, дальше — есть.
+	EventsStart(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	// PrivateRepos — приватные репозитории, жившие в [from, to): их коммиты поиск не находит.
+	PrivateRepos(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]RepoRef, error)
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+func (r *repo) Get(ctx context.Context, userID uuid.UUID) (*State, error) {
+	s := &State{UserID: userID}
+	err := r.pool.QueryRow(ctx, `SELECT status, years, range_start, range_end, cursor, chunks_done, chunks_total,
+			commits_found, last_job_id, last_error, started_at, finished_at
+		FROM backfill_state WHERE user_id = $1`, userID,
+	).Scan(&s.Status, &s.Years, &s.RangeStart, &s.RangeEnd, &s.Cursor, &s.ChunksDone, &s.ChunksTotal,
+		&s.CommitsFound, &s.LastJobID, &s.LastError, &s.StartedAt, &s.FinishedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *repo) Init(ctx context.Context, userID uuid.UUID, years int) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `INSERT INTO backfill_state (user_id, years) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING`, userID, years)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *repo) Reset(ctx context.Context, userID uuid.UUID, years int) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO backfill_state (user_id, years) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET status = 'pending', years = EXCLUDED.years,
+			range_start = NULL, range_end = NULL, cursor = NULL, chunks_done = 0, chunks_total = 0,
+			commits_found = 0, last_error = NULL, started_at = NULL, finished_at = NULL, updated_at = NOW()`,
+		userID, years)
+	return err
+}
+
+func (r *repo) SetJob(ctx context.Context, userID, jobID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE backfill_state SET last_job_id = $2, updated_at = NOW() WHERE user_id = $1`, userID, jobID)
+	return err
+}
+
+func (r *repo) Begin(ctx context.Context, userID uuid.UUID, start, end time.Time, chunks int) error {
+	_, err := r.pool.Exec(ctx, `UPDATE backfill_state SET status = 'running', range_start = $2, range_end = $3,
+			cursor = $3, chunks_total = $4, started_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1`, userID, start, end, chunks)
+	return err
+}
+
+func (r *repo) Checkpoint(ctx context.Context, userID uuid.UUID, cursor time.Time, commits int) error {
+	_, err := r.pool.Exec(ctx, `UPDATE backfill_state SET status = 'running', cursor = $2,
+			chunks_done = chunks_done + 1, commits_found = commits_found + $3, last_error = NULL, updated_at = NOW()
+		WHERE user_id = $1`, userID, cursor, commits)
+	return err
+}
+
+func (r *repo) Finish(ctx context.Context, userID uuid.UUID, cause error) error {
+	status, msg := StatusDone, (*string)(nil)
+	if cause != nil {
+		status = StatusFailed
+		m := cause.Error()
+		msg = &m
+	}
+	_, err := r.pool.Exec(ctx, `UPDATE backfill_state SET status = $2, last_error = $3, finished_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1`, userID, status, msg)
+	return err
+}
+
+func (r *repo) EventsStart(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var t *time.Time
+	err := r.pool.QueryRow(ctx, `SELECT MIN(occurred_at) FROM activity_events
+		WHERE user_id = $1 AND type NOT IN ('BackfillCommit', 'CalendarDay')`, userID).Scan(&t)
+	return t, err
+}
+
+func (r *repo) PrivateRepos(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]RepoRef, error) {
+	rows, err := r.pool.Query(ctx, `SELECT github_id, full_name FROM repositories
+		WHERE user_id = $1 AND is_private
+			AND (repo_created_at IS NULL OR repo_created_at < $3) AND (pushed_at IS NULL OR pushed_at >= $2)
+		ORDER BY pushed_at DESC NULLS LAST`, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []RepoRef
+	for rows.Next() {
+		var ref RepoRef
+		if err := rows.Scan(&ref.GitHubID, &ref.FullName); err != nil {
+			return nil, err
+		}
+		out = append(out, ref)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,104 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/infrastructure/queue"
+)
+
+const MaxYears = 10
+
+var (
+	ErrInvalidYears = errors.New("years must be between 1 and 10")
+	ErrRunning      = errors.New("backfill already running")
+)
+
+type Service interface {
+	Status(ctx context.Context, userID uuid.UUID) (*State, error)
+	// Start запускает загрузку истории заново за years лет (0 — по умолчанию).
+	Start(ctx context.Context, userID uuid.UUID, years int) (uuid.UUID, error)
+	// EnsureStarted запускает загрузку при первом входе; повторные входы ничего не делают.
+	EnsureStarted(ctx context.Context, userID uuid.UUID) error
+	// Continue ставит следующую порцию загрузки на время at.
+	Continue(ctx context.Context, userID uuid.UUID, at time.Time) (uuid.UUID, error)
+	Fail(ctx context.Context, userID uuid.UUID, cause error) error
+}
+
+type service struct {
+	repo         Repository
+	jobs         queue.Queue
+	defaultYears int
+}
+
+func NewService(repo Repository, jobs queue.Queue, defaultYears int) Service {
+	if defaultYears <= 0 || defaultYears > MaxYears {
+		defaultYears = 3
+	}
+	return &service{repo: repo, jobs: jobs, defaultYears: defaultYears}
+}
+
+func (s *service) Status(ctx context.Context, userID uuid.UUID) (*State, error) {
+	st, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		return &State{UserID: userID, Status: "none"}, nil
+	}
+	return st, nil
+}
+
+func (s *service) Start(ctx context.Context, userID uuid.UUID, years int) (uuid.UUID, error) {
+	if years == 0 {
+		years = s.defaultYears
+	}
+	if years < 1 || years > MaxYears {
+		return uuid.Nil, ErrInvalidYears
+	}
+	st, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if st != nil && st.Status == StatusRunning && st.LastJobID != nil {
+		// Перезапуск посреди загрузки испортил бы чекпоинт
+		return *st.LastJobID, ErrRunning
+	}
+	if err := s.repo.Reset(ctx, userID, years); err != nil {
+		return uuid.Nil, err
+	}
+	return s.enqueue(ctx, userID, time.Now(), queue.PriorityNormal)
+}
+
+func (s *service) EnsureStarted(ctx context.Context, userID uuid.UUID) error {
+	created, err := s.repo.Init(ctx, userID, s.defaultYears)
+	if err != nil || !created {
+		return err
+	}
+	// Даём первой обычной синхронизации пройти раньше: она определяет, с какого момента есть события
+	_, err = s.enqueue(ctx, userID, time.Now().Add(5*time.Minute), queue.PriorityLow)
+	return err
+}
+
+func (s *service) Continue(ctx context.Context, userID uuid.UUID, at time.Time) (uuid.UUID, error) {
+	return s.enqueue(ctx, userID, at, queue.PriorityLow)
+}
+
+func (s *service) Fail(ctx context.Context, userID uuid.UUID, cause error) error {
+	return s.repo.Finish(ctx, userID, cause)
+}
+
+func (s *service) enqueue(ctx context.Context, userID uuid.UUID, at time.Time, priority int) (uuid.UUID, error) {
+	jobID, err := s.jobs.Enqueue(ctx, queue.EnqueueParams{
+		Type:     queue.TypeBackfill,
+		UserID:   &userID,
+		Priority: priority,
+		RunAt:    at,
+		DedupKey: queue.UserKey(queue.TypeBackfill, userID),
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return jobID, s.repo.SetJob(ctx, userID, jobID)
+}
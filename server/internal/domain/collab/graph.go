@@ -0,0 +1,191 @@
+package collab
+
+import (
+	"sort"
+	"strings"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+// Вес связи: общий коммит — 1, ревью — 3, PR с ревью — 2.
+const (
+	weightCommit = 1
+	weightReview = 3
+	weightPR     = 2
+)
+
+// maxContributorsPerRepo ограничивает число пар в крупных репозиториях (O(n²)).
+const maxContributorsPerRepo = 30
+
+type edge struct {
+	a, b    string // a < b
+	repos   map[uuid.UUID]struct{}
+	commits int
+	reviews int
+	prs     int
+	// направленные ревью: reviewsAB — a ревьюил b
+	reviewsAB int
+	reviewsBA int
+}
+
+func (e *edge) weight() int {
+	return e.commits*weightCommit + e.reviews*weightReview + e.prs*weightPR
+}
+
+type graph struct {
+	self    string
+	edges   map[[2]string]*edge
+	avatars map[string]string
+	logins  map[string]string // lower → как на GitHub
+}
+
+// buildGraph собирает взвешенный граф совместной работы по всем репозиториям пользователя.
+func buildGraph(self string, contributors []ContributorRow, prs []PullRequestRow, reviews []ReviewRow) *graph {
+	g := &graph{
+		self:    strings.ToLower(self),
+		edges:   make(map[[2]string]*edge),
+		avatars: make(map[string]string),
+		logins:  map[string]string{strings.ToLower(self): self},
+	}
+	byRepo := make(map[uuid.UUID][]ContributorRow)
+	for _, c := range contributors {
+		g.remember(c.Login, c.AvatarURL)
+		byRepo[c.RepoID] = append(byRepo[c.RepoID], c)
+	}
+	for repoID, list := range byRepo {
+		sort.Slice(list, func(i, j int) bool { return list[i].Contributions > list[j].Contributions })
+		if len(list) > maxContributorsPerRepo {
+			list = list[:maxContributorsPerRepo]
+		}
+		for i := 0; i < len(list); i++ {
+			for j := i + 1; j < len(list); j++ {
+				e := g.edge(list[i].Login, list[j].Login)
+				if e == nil {
+					continue
+				}
+				e.repos[repoID] = struct{}{}
+				e.commits += min(list[i].Contributions, list[j].Contributions)
+			}
+		}
+	}
+	authors := make(map[uuid.UUID]map[int]string)
+	for _, pr := range prs {
+		g.remember(pr.AuthorLogin, pr.AuthorAvatar)
+		if authors[pr.RepoID] == nil {
+			authors[pr.RepoID] = make(map[int]string)
+		}
+		authors[pr.RepoID][pr.Number] = pr.AuthorLogin
+	}
+	for _, rv := range reviews {
+		g.remember(rv.ReviewerLogin, rv.ReviewerAvatar)
+		author, ok := authors[rv.RepoID][rv.PRNumber]
+		if !ok {
+			continue
+		}
+		e := g.edge(rv.ReviewerLogin, author)
+		if e == nil {
+			continue
+		}
+		e.repos[rv.RepoID] = struct{}{}
+		e.reviews += rv.Reviews
+		e.prs++
+		if strings.ToLower(rv.ReviewerLogin) == e.a {
+			e.reviewsAB += rv.Reviews
+		} else {
+			e.reviewsBA += rv.Reviews
+		}
+	}
+	return g
+}
+
+func (g *graph) remember(login, avatar string) {
+	key := strings.ToLower(login)
+	if _, ok := g.logins[key]; !ok {
+		g.logins[key] = login
+	}
+	if avatar != "" {
+		g.avatars[key] = avatar
+	}
+}
+
+func (g *graph) edge(x, y string) *edge {
+	x, y = strings.ToLower(x), strings.ToLower(y)
+	if x == y || x == "" || y == "" {
+		return nil
+	}
+	if x > y {
+		x, y = y, x
+	}
+	key := [2]string{x, y}
+	e, ok := g.edges[key]
+	if !ok {
+		e = &edge{a: x, b: y, repos: make(map[uuid.UUID]struct{})}
+		g.edges[key] = e
+	}
+	return e
+}
+
+// collaborators — связи пользователя, по убыванию веса.
+func (g *graph) collaborators(limit int) []models.Collaborator {
+	out := []models.Collaborator{}
+	for _, e := range g.edges {
+		var other string
+		var given, received int
+		switch g.self {
+		case e.a:
+			other, given, received = e.b, e.reviewsAB, e.reviewsBA
+		case e.b:
+			other, given, received = e.a, e.reviewsBA, e.reviewsAB
+		default:
+			continue
+		}
+		out = append(out, models.Collaborator{
+			Login:           g.logins[other],
+			AvatarURL:       g.avatars[other],
+			SharedRepos:     len(e.repos),
+			SharedCommits:   e.commits,
+			ReviewsGiven:    given,
+			ReviewsReceived: received,
+			PullRequests:    e.prs,
+			Weight:          e.weight(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Weight != out[j].Weight {
+			return out[i].Weight > out[j].Weight
+		}
+		return out[i].Login < out[j].Login
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// export — пользователь, его top-N коллег и все связи между ними.
+func (g *graph) export(limit int) *models.CollaborationGraph {
+	top := g.collaborators(limit)
+	selfWeight := 0
+	for _, c := range top {
+		selfWeight += c.Weight
+	}
+	nodes := []models.GraphNode{{ID: g.self, Login: g.logins[g.self], AvatarURL: g.avatars[g.self], IsSelf: true, Weight: selfWeight}}
+	included := map[string]bool{g.self: true}
+	for _, c := range top {
+		id := strings.ToLower(c.Login)
+		included[id] = true
+		nodes = append(nodes, models.GraphNode{ID: id, Login: c.Login, AvatarURL: c.AvatarURL, Weight: c.Weight})
+	}
+	edges := []models.GraphEdge{}
+	for _, e := range g.edges {
+		if !included[e.a] || !included[e.b] || e.weight() == 0 {
+			continue
+		}
+		edges = append(edges, models.GraphEdge{
+			Source: e.a, Target: e.b, Repos: len(e.repos),
+			Commits: e.commits, Reviews: e.reviews, PRs: e.prs, Weight: e.weight(),
+		})
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].Weight > edges[j].Weight })
+	return &models.CollaborationGraph{Nodes: nodes, Edges: edges}
+}
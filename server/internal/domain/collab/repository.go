@@ -0,0 +1,181 @@
+package collab
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	ReposToScan(ctx context.Context, userID uuid.UUID, limit int) ([]ScanRepo, error)
+	ReplaceRepo(ctx context.Context, userID, repoID uuid.UUID, data RepoData) error
+	Contributors(ctx context.Context, userID uuid.UUID) ([]ContributorRow, error)
+	PullRequests(ctx context.Context, userID uuid.UUID) ([]PullRequestRow, error)
+	Reviews(ctx context.Context, userID uuid.UUID) ([]ReviewRow, error)
+}
+
+type ScanRepo struct {
+	ID       uuid.UUID
+	FullName string
+}
+
+// RepoData — всё собранное по одному репозиторию за синхронизацию.
+type RepoData struct {
+	Contributors []ContributorRow
+	PullRequests []PullRequestRow
+	Reviews      []ReviewRow
+}
+
+type ContributorRow struct {
+	RepoID        uuid.UUID
+	Login         string
+	AvatarURL     string
+	Contributions int
+}
+
+type PullRequestRow struct {
+	RepoID      uuid.UUID
+	Number      int
+	AuthorLogin string
+	AuthorAvatar string
+	Merged      bool
+	CreatedAt   *time.Time
+}
+
+type ReviewRow struct {
+	RepoID         uuid.UUID
+	PRNumber       int
+	ReviewerLogin  string
+	ReviewerAvatar string
+	Reviews        int
+	LastState      string
+	SubmittedAt    *time.Time
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+func (r *repo) ReposToScan(ctx context.Context, userID uuid.UUID, limit int) ([]ScanRepo, error) {
+	query := `SELECT id, full_name FROM repositories
+		WHERE user_id = $1 AND NOT is_fork AND COALESCE(size_kb, 0) > 0
+			AND (collab_scanned_at IS NULL OR (pushed_at IS NOT NULL AND pushed_at > collab_scanned_at))
+		ORDER BY pushed_at DESC NULLS LAST LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []ScanRepo
+	for rows.Next() {
+		var row ScanRepo
+		if err := rows.Scan(&row.ID, &row.FullName); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// ReplaceRepo заменяет участников репозитория и дописывает PR/ревью (старые PR, выпавшие
+// из последней страницы GitHub, остаются в истории).
+func (r *repo) ReplaceRepo(ctx context.Context, userID, repoID uuid.UUID, data RepoData) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM repo_contributors WHERE repo_id = $1`, repoID); err != nil {
+		return err
+	}
+	for _, c := range data.Contributors {
+		_, err := tx.Exec(ctx, `INSERT INTO repo_contributors (user_id, repo_id, login, avatar_url, contributions)
+			VALUES ($1, $2, $3, $4, $5) ON CONFLICT (repo_id, login) DO UPDATE SET contributions = EXCLUDED.contributions`,
+			userID, repoID, c.Login, c.AvatarURL, c.Contributions)
+		if err != nil {
+			return err
+		}
+	}
+	for _, pr := range data.PullRequests {
+		_, err := tx.Exec(ctx, `INSERT INTO pull_requests (user_id, repo_id, number, author_login, author_avatar_url, merged, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (repo_id, number) DO UPDATE SET merged = EXCLUDED.merged`,
+			userID, repoID, pr.Number, pr.AuthorLogin, pr.AuthorAvatar, pr.Merged, pr.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	for _, rv := range data.Reviews {
+		_, err := tx.Exec(ctx, `INSERT INTO pull_request_reviews (user_id, repo_id, pr_number, reviewer_login, reviewer_avatar_url, reviews, last_state, submitted_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (repo_id, pr_number, reviewer_login) DO UPDATE SET
+				reviews = EXCLUDED.reviews, last_state = EXCLUDED.last_state, submitted_at = EXCLUDED.submitted_at`,
+			userID, repoID, rv.PRNumber, rv.ReviewerLogin, rv.ReviewerAvatar, rv.Reviews, rv.LastState, rv.SubmittedAt)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE repositories SET collab_scanned_at = NOW() WHERE id = $1`, repoID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *repo) Contributors(ctx context.Context, userID uuid.UUID) ([]ContributorRow, error) {
+	rows, err := r.pool.Query(ctx, `SELECT repo_id, login, COALESCE(avatar_url, ''), contributions
+		FROM repo_contributors WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []ContributorRow
+	for rows.Next() {
+		var row ContributorRow
+		if err := rows.Scan(&row.RepoID, &row.Login, &row.AvatarURL, &row.Contributions); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func (r *repo) PullRequests(ctx context.Context, userID uuid.UUID) ([]PullRequestRow, error) {
+	rows, err := r.pool.Query(ctx, `SELECT repo_id, number, author_login, COALESCE(author_avatar_url, ''), merged, created_at
+		FROM pull_requests WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []PullRequestRow
+	for rows.Next() {
+		var row PullRequestRow
+		if err := rows.Scan(&row.RepoID, &row.Number, &row.AuthorLogin, &row.AuthorAvatar, &row.Merged, &row.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func (r *repo) Reviews(ctx context.Context, userID uuid.UUID) ([]ReviewRow, error) {
+	rows, err := r.pool.Query(ctx, `SELECT repo_id, pr_number, reviewer_login, COALESCE(reviewer_avatar_url, ''), reviews, COALESCE(last_state, ''), submitted_at
+		FROM pull_request_reviews WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []ReviewRow
+	for rows.Next() {
+		var row ReviewRow
+		if err := rows.Scan(&row.RepoID, &row.PRNumber, &row.ReviewerLogin, &row.ReviewerAvatar, &row.Reviews, &row.LastState, &row.SubmittedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
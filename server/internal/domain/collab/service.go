@@ -0,0 +1,62 @@
+package collab
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+type Service interface {
+	TopCollaborators(ctx context.Context, userID uuid.UUID, limit int) ([]models.Collaborator, error)
+	Graph(ctx context.Context, userID uuid.UUID, limit int) (*models.CollaborationGraph, error)
+}
+
+type service struct {
+	repo    Repository
+	userSvc user.Service
+}
+
+func NewService(repo Repository, userSvc user.Service) Service {
+	return &service{repo: repo, userSvc: userSvc}
+}
+
+func (s *service) TopCollaborators(ctx context.Context, userID uuid.UUID, limit int) ([]models.Collaborator, error) {
+	g, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return g.collaborators(limit), nil
+}
+
+func (s *service) Graph(ctx context.Context, userID uuid.UUID, limit int) (*models.CollaborationGraph, error) {
+	g, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return g.export(limit), nil
+}
+
+func (s *service) load(ctx context.Context, userID uuid.UUID) (*graph, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	contributors, err := s.repo.Contributors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := s.repo.PullRequests(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := s.repo.Reviews(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	g := buildGraph(u.Username, contributors, prs, reviews)
+	if u.AvatarURL != nil {
+		g.remember(u.Username, *u.AvatarURL)
+	}
+	return g, nil
+}
@@ -0,0 +1,36 @@
+package compare
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	// Reviews — ревью PR пользователя за дни [from, to] по времени последнего ревью. Приватные
+	// репозитории учитываются только с withPrivate и только синхронизированные самим пользователем.
+	Reviews(ctx context.Context, userID uuid.UUID, username string, loc *time.Location, from, to time.Time, withPrivate bool) (int, error)
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+func (r *repo) Reviews(ctx context.Context, userID uuid.UUID, username string, loc *time.Location, from, to time.Time, withPrivate bool) (int, error) {
+	// Один репозиторий могут синхронизировать несколько пользователей — PR считается один раз
+	var n int
+	err := r.pool.QueryRow(ctx, `SELECT COALESCE(SUM(reviews), 0) FROM (
+			SELECT DISTINCT ON (repo.github_id, rv.pr_number) rv.reviews, rv.submitted_at
+			FROM pull_request_reviews rv JOIN repositories repo ON repo.id = rv.repo_id
+			WHERE LOWER(rv.reviewer_login) = LOWER($2)
+				AND (NOT COALESCE(repo.is_private, false) OR ($6 AND repo.user_id = $1))
+			ORDER BY repo.github_id, rv.pr_number, rv.submitted_at DESC NULLS LAST) r
+		WHERE (submitted_at AT TIME ZONE $3)::date BETWEEN $4::date AND $5::date`,
+		userID, username, loc.String(), from.Format(dateLayout), to.Format(dateLayout), withPrivate).Scan(&n)
+	return n, err
+}
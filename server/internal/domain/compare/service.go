@@ -0,0 +1,364 @@
+package compare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/profile"
+	"github.com/devsync/server/internal/domain/stats"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+// Как пользователь попал в сравнение: сам, коллега по команде или открытый профиль.
+const (
+	AccessSelf   = "self"
+	AccessTeam   = "team"
+	AccessPublic = "public"
+)
+
+const (
+	MinUsers = 2
+	MaxUsers = 10
+	// maxLanguages — сколько языков в общем списке сравнения.
+	maxLanguages = 8
+	dateLayout   = "2006-01-02"
+)
+
+var (
+	ErrInvalidUsers = fmt.Errorf("users must list %d to %d GitHub logins", MinUsers, MaxUsers)
+	// ErrNotFound — пользователя нет или его статистика недоступна: причину не раскрываем.
+	ErrNotFound = errors.New("user not found")
+)
+
+// fullSections — что видно о себе и о коллегах по команде.
+var fullSections = []string{profile.SectionTotals, profile.SectionContributions, profile.SectionLanguages, profile.SectionStreaks}
+
+type StatsProvider interface {
+	GetUserStatsWithPeriod(ctx context.Context, userID uuid.UUID, q stats.PeriodQuery) (*models.UserStats, error)
+}
+
+// TeamChecker — общие команды дают доступ ко всей статистике, как в /api/teams/:id/stats.
+type TeamChecker interface {
+	SharesTeam(ctx context.Context, userID, otherID uuid.UUID) (bool, error)
+}
+
+// PublicStatsProvider — открытые разделы публичного профиля (profile.Service).
+type PublicStatsProvider interface {
+	Stats(ctx context.Context, username string, q stats.PeriodQuery) (*models.PublicProfile, error)
+}
+
+type Service interface {
+	// Compare сравнивает пользователей за один период глазами viewerID
+	// (ErrInvalidUsers, ErrNotFound, stats.ErrInvalidPeriod).
+	Compare(ctx context.Context, viewerID uuid.UUID, usernames []string, q stats.PeriodQuery) (*models.Comparison, error)
+}
+
+type service struct {
+	repo       Repository
+	userSvc    user.Service
+	statsSvc   StatsProvider
+	teamSvc    TeamChecker
+	profileSvc PublicStatsProvider
+}
+
+func NewService(repo Repository, userSvc user.Service, statsSvc StatsProvider, teamSvc TeamChecker, profileSvc PublicStatsProvider) Service {
+	return &service{repo: repo, userSvc: userSvc, statsSvc: statsSvc, teamSvc: teamSvc, profileSvc: profileSvc}
+}
+
+// member — пользователь сравнения с вкладами по дням до выравнивания.
+type member struct {
+	models.ComparedUser
+	from, to  time.Time
+	days      []models.ContributionDay
+	languages []models.LanguageStats
+}
+
+func (s *service) Compare(ctx context.Context, viewerID uuid.UUID, usernames []string, q stats.PeriodQuery) (*models.Comparison, error) {
+	names, err := ParseUsers(usernames)
+	if err != nil {
+		return nil, err
+	}
+	// Период проверяется до запросов по участникам; разрешает его каждый в своём часовом поясе
+	p, err := stats.ResolvePeriod(q, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]*member, 0, len(names))
+	for _, name := range names {
+		m, err := s.member(ctx, viewerID, name, q)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	// Ряды строятся по объединению периодов: у all и у соседей по поясам начало может отличаться
+	p.From, p.To = members[0].from, members[0].to
+	for _, m := range members[1:] {
+		if m.from.Before(p.From) {
+			p.From = m.from
+		}
+		if m.to.After(p.To) {
+			p.To = m.to
+		}
+	}
+	out := &models.Comparison{Period: p.Range(), Users: make([]models.ComparedUser, 0, len(members))}
+	out.Granularity = granularity(p.From, p.To)
+	out.Buckets = buckets(out.Granularity, p.From, p.To)
+	out.Languages = commonLanguages(members)
+	for _, m := range members {
+		if m.days != nil {
+			m.Series = series(out.Granularity, out.Buckets, m.days)
+		}
+		if m.languages != nil {
+			m.Languages = shares(out.Languages, m.languages)
+		}
+		out.Users = append(out.Users, m.ComparedUser)
+	}
+	return out, nil
+}
+
+// ParseUsers — логины из ?users=alice,bob (можно повторять параметр), без пустых и повторов.
+func ParseUsers(values []string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimPrefix(strings.TrimSpace(name), "@")
+			if name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) < MinUsers || len(names) > MaxUsers {
+		return nil, ErrInvalidUsers
+	}
+	return names, nil
+}
+
+// member собирает данные пользователя с тем доступом, который есть у viewerID.
+func (s *service) member(ctx context.Context, viewerID uuid.UUID, name string, q stats.PeriodQuery) (*member, error) {
+	u, err := s.userSvc.GetByUsername(ctx, name)
+	if errors.Is(err, user.ErrNotFound) {
+		return s.public(ctx, name, q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	access := AccessSelf
+	if u.ID != viewerID {
+		ok, err := s.teamSvc.SharesTeam(ctx, viewerID, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return s.public(ctx, name, q)
+		}
+		access = AccessTeam
+	}
+
+	st, err := s.statsSvc.GetUserStatsWithPeriod(ctx, u.ID, q)
+	if err != nil {
+		return nil, err
+	}
+	m := &member{ComparedUser: models.ComparedUser{Username: u.Username, AvatarURL: u.AvatarURL, Access: access, Sections: fullSections}}
+	if err := m.period(st.Period); err != nil {
+		return nil, err
+	}
+	reviews, err := s.repo.Reviews(ctx, u.ID, u.Username, u.Location(), m.from, m.to, true)
+	if err != nil {
+		return nil, err
+	}
+	m.Totals = &models.ComparedTotals{Contributions: st.Totals.Contributions, Commits: st.Totals.Commits, PRs: st.Totals.PRs,
+		Issues: st.Totals.Issues, Reviews: reviews, ActiveDays: st.Totals.ActiveDays}
+	m.days = st.Contributions
+	if m.days == nil {
+		m.days = []models.ContributionDay{}
+	}
+	m.languages = st.Languages
+	if m.languages == nil {
+		m.languages = []models.LanguageStats{}
+	}
+	m.Streaks = &models.ComparedStreaks{Current: st.Streaks.Current.Length, Longest: st.Streaks.Longest.Length}
+	return m, nil
+}
+
+// public — пользователь, который виден только через открытый профиль.
+func (s *service) public(ctx context.Context, name string, q stats.PeriodQuery) (*member, error) {
+	pp, err := s.profileSvc.Stats(ctx, name, q)
+	if errors.Is(err, profile.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m := &member{ComparedUser: models.ComparedUser{Username: pp.Username, AvatarURL: pp.AvatarURL, Access: AccessPublic, Sections: []string{}}}
+	if err := m.period(pp.Period); err != nil {
+		return nil, err
+	}
+	for _, sec := range fullSections {
+		for _, visible := range pp.Sections {
+			if sec == visible {
+				m.Sections = append(m.Sections, sec)
+			}
+		}
+	}
+	if pp.Totals != nil {
+		u, err := s.userSvc.GetByUsername(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		reviews, err := s.repo.Reviews(ctx, u.ID, u.Username, u.Location(), m.from, m.to, false)
+		if err != nil {
+			return nil, err
+		}
+		m.Totals = &models.ComparedTotals{Contributions: pp.Totals.Contributions, Commits: pp.Totals.Commits, PRs: pp.Totals.PRs,
+			Issues: pp.Totals.Issues, Reviews: reviews, ActiveDays: pp.Totals.ActiveDays}
+	}
+	for _, sec := range pp.Sections {
+		switch sec {
+		case profile.SectionContributions:
+			m.days = pp.Contributions
+			if m.days == nil {
+				m.days = []models.ContributionDay{}
+			}
+		case profile.SectionLanguages:
+			m.languages = pp.Languages
+			if m.languages == nil {
+				m.languages = []models.LanguageStats{}
+			}
+		}
+	}
+	if pp.Streaks != nil {
+		m.Streaks = &models.ComparedStreaks{Current: pp.Streaks.Current.Length, Longest: pp.Streaks.Longest.Length}
+	}
+	return m, nil
+}
+
+func (m *member) period(r models.PeriodRange) error {
+	var err error
+	if m.from, err = time.Parse(dateLayout, r.From); err != nil {
+		return err
+	}
+	m.to, err = time.Parse(dateLayout, r.To)
+	return err
+}
+
+// granularity — шаг рядов, чтобы точек было не больше сотни с небольшим: до квартала — дни,
+// до двух лет — недели, дальше — месяцы.
+func granularity(from, to time.Time) string {
+	days := int(to.Sub(from).Hours()/24) + 1
+	switch {
+	case days <= 92:
+		return stats.GranularityDay
+	case days <= 731:
+		return stats.GranularityWeek
+	}
+	return stats.GranularityMonth
+}
+
+// bucketStart — первый день шага, в который попадает d (недели — с понедельника).
+func bucketStart(g string, d time.Time) time.Time {
+	switch g {
+	case stats.GranularityWeek:
+		return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+	case stats.GranularityMonth:
+		return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return d
+}
+
+func buckets(g string, from, to time.Time) []string {
+	out := []string{}
+	for d := bucketStart(g, from); !d.After(to); {
+		out = append(out, d.Format(dateLayout))
+		switch g {
+		case stats.GranularityWeek:
+			d = d.AddDate(0, 0, 7)
+		case stats.GranularityMonth:
+			d = d.AddDate(0, 1, 0)
+		default:
+			d = d.AddDate(0, 0, 1)
+		}
+	}
+	return out
+}
+
+// series раскладывает вклады по шагам; недельные точки (старые данные после свёртки) попадают в шаг своего понедельника.
+func series(g string, keys []string, days []models.ContributionDay) *models.ComparedSeries {
+	index := make(map[string]int, len(keys))
+	for i, k := range keys {
+		index[k] = i
+	}
+	out := &models.ComparedSeries{Values: make([]int, len(keys)), Normalized: make([]float64, len(keys))}
+	for _, d := range days {
+		day, err := time.Parse(dateLayout, d.Date)
+		if err != nil {
+			continue
+		}
+		if i, ok := index[bucketStart(g, day).Format(dateLayout)]; ok {
+			out.Values[i] += d.Count
+		}
+	}
+	peak := 0
+	for _, v := range out.Values {
+		if v > peak {
+			peak = v
+		}
+	}
+	if peak > 0 {
+		for i, v := range out.Values {
+			out.Normalized[i] = round(float64(v) / float64(peak))
+		}
+	}
+	return out
+}
+
+// commonLanguages — языки с наибольшей суммой долей среди тех, чьи языки видны.
+func commonLanguages(members []*member) []string {
+	total := make(map[string]float64)
+	for _, m := range members {
+		for _, l := range m.languages {
+			total[l.Language] += l.Percent
+		}
+	}
+	out := make([]string, 0, len(total))
+	for lang := range total {
+		out = append(out, lang)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if total[out[i]] != total[out[j]] {
+			return total[out[i]] > total[out[j]]
+		}
+		return out[i] < out[j]
+	})
+	if len(out) > maxLanguages {
+		out = out[:maxLanguages]
+	}
+	return out
+}
+
+func shares(languages []string, own []models.LanguageStats) []float64 {
+	byName := make(map[string]float64, len(own))
+	for _, l := range own {
+		byName[l.Language] = l.Percent
+	}
+	out := make([]float64, len(languages))
+	for i, lang := range languages {
+		out[i] = byName[lang]
+	}
+	return out
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,244 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"github.com/google/uuid"
+	githublib "github.com/devsync/server/pkg/github"
+	"github.com/devsync/server/internal/domain/backfill"
+	"github.com/devsync/server/internal/domain/stats"
+)
+
+// Загрузка истории идёт помесячно от начала данных Events API назад. За один запуск задачи —
+// не больше backfillChunksPerRun месяцев, дальше задача ставит продолжение, уступая очередь синхронизациям.
+const (
+	backfillChunksPerRun = 6
+	maxSearchPages       = 10 // поиск отдаёт не больше 1000 результатов
+	maxCommitPages       = 10
+)
+
+const (
+	EventBackfillProgress = "backfill_progress"
+	EventBackfillFinished = "backfill_finished"
+)
+
+type BackfillProgress struct {
+	JobID        uuid.UUID `json:"job_id"`
+	Month        string    `json:"month,omitempty"` // YYYY-MM только что загруженного месяца
+	ChunksDone   int       `json:"chunks_done"`
+	ChunksTotal  int       `json:"chunks_total"`
+	CommitsFound int       `json:"commits_found"`
+}
+
+// BackfillResult — итог одного запуска: Done или когда продолжить.
+type BackfillResult struct {
+	Done     bool
+	ResumeAt time.Time
+}
+
+func (s *syncService) Backfill(ctx context.Context, userID, jobID uuid.UUID) (*BackfillResult, error) {
+	st, err := s.backfillRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || st.Status == backfill.StatusDone {
+		return &BackfillResult{Done: true}, nil
+	}
+	u, err := s.userSvc.GetByIDWithToken(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.AccessToken == "" {
+		return &BackfillResult{Done: true}, nil
+	}
+	if u.ReauthRequired {
+		return nil, ErrReauthRequired
+	}
+	token, err := s.accessToken(ctx, u)
+	if errors.Is(err, ErrReauthRequired) {
+		return nil, s.markReauth(ctx, userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	client := githublib.NewLimitedClient(token, s.limits)
+
+	if st.RangeEnd == nil {
+		// Первый запуск: история до первого события из Events API (оно же — граница учёта, см. stats.Rebuild)
+		end := time.Now().UTC()
+		if t, err := s.backfillRepo.EventsStart(ctx, userID); err != nil {
+			return nil, err
+		} else if t != nil {
+			end = t.UTC()
+		}
+		// Границы месяцев — на полночь UTC: так каждый день графика попадает ровно в один месяц
+		end = end.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		start := end.AddDate(-st.Years, 0, 0)
+		chunks := st.Years * 12
+		if err := s.backfillRepo.Begin(ctx, userID, start, end, chunks); err != nil {
+			return nil, err
+		}
+		st.RangeStart, st.RangeEnd, st.Cursor, st.ChunksTotal = &start, &end, &end, chunks
+	}
+
+	cursor, start := *st.Cursor, *st.RangeStart
+	var rebuildFrom *time.Time
+	defer func() {
+		// Пересчитываем дни один раз за запуск — от самого раннего загруженного месяца
+		if rebuildFrom != nil {
+			if err := s.activityRepo.Rebuild(context.Background(), userID, u.Location(), *rebuildFrom); err != nil {
+				log.Printf("backfill %s: rebuild: %v", userID, err)
+			} else if s.notifier != nil {
+				s.notifier.BroadcastToUser(userID, EventStatsUpdated, nil)
+			}
+		}
+	}()
+
+	for i := 0; i < backfillChunksPerRun && cursor.After(start); i++ {
+		from := cursor.AddDate(0, -1, 0)
+		if from.Before(start) {
+			from = start
+		}
+		rows, commits, err := s.backfillChunk(ctx, client, userID, u.Username, from, cursor)
+		if githublib.IsUnauthorized(err) {
+			return nil, s.markReauth(ctx, userID)
+		}
+		if githublib.IsRateLimited(err) {
+			// Месяц не засчитан — повторим его целиком после сброса лимита
+			resume := githublib.RetryAt(err)
+			if resume.Before(time.Now()) {
+				resume = time.Now().Add(time.Minute)
+			}
+			return &BackfillResult{ResumeAt: resume}, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("backfill %s..%s: %w", from.Format("2006-01-02"), cursor.Format("2006-01-02"), err)
+		}
+		if len(rows) > 0 {
+			if _, err := s.activityRepo.Insert(ctx, userID, rows); err != nil {
+				return nil, err
+			}
+			rebuildFrom = &from
+		}
+		if err := s.backfillRepo.Checkpoint(ctx, userID, from, commits); err != nil {
+			return nil, err
+		}
+		cursor = from
+		st.ChunksDone++
+		st.CommitsFound += commits
+		s.emitBackfill(userID, EventBackfillProgress, BackfillProgress{
+			JobID: jobID, Month: from.Format("2006-01"),
+			ChunksDone: st.ChunksDone, ChunksTotal: st.ChunksTotal, CommitsFound: st.CommitsFound,
+		})
+	}
+	if cursor.After(start) {
+		return &BackfillResult{ResumeAt: time.Now()}, nil
+	}
+	if err := s.backfillRepo.Finish(ctx, userID, nil); err != nil {
+		return nil, err
+	}
+	s.emitBackfill(userID, EventBackfillFinished, BackfillProgress{
+		JobID: jobID, ChunksDone: st.ChunksDone, ChunksTotal: st.ChunksTotal, CommitsFound: st.CommitsFound,
+	})
+	return &BackfillResult{Done: true}, nil
+}
+
+// backfillChunk собирает коммиты автора за [from, to) из поиска (публичные репозитории) и списков
+// коммитов приватных репозиториев, плюс вклады из графика GitHub, которые коммитами не объясняются.
+func (s *syncService) backfillChunk(ctx context.Context, client *githublib.Client, userID uuid.UUID, login string, from, to time.Time) ([]stats.ActivityEventRow, int, error) {
+	seen := make(map[string]bool)
+	var rows []stats.ActivityEventRow
+	perDay := make(map[string]int) // коммитов по дням UTC — для сверки с графиком
+	add := func(sha string, repoID int64, repoName string, public bool, date string) {
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil || seen[sha] || t.Before(from) || !t.Before(to) {
+			return
+		}
+		seen[sha] = true
+		perDay[t.UTC().Format("2006-01-02")]++
+		rows = append(rows, stats.ActivityEventRow{
+			GitHubEventID: "commit:" + sha,
+			Type:          stats.EventBackfillCommit,
+			RepoGitHubID:  repoID,
+			RepoName:      repoName,
+			IsPublic:      public,
+			Commits:       1,
+			OccurredAt:    t,
+		})
+	}
+
+	// Даты в поиске без времени и пояса — берём с запасом в день, точную границу проверяет add
+	query := fmt.Sprintf("author:%s author-date:%s..%s", login, from.AddDate(0, 0, -1).Format("2006-01-02"), to.Format("2006-01-02"))
+	for page := 1; page <= maxSearchPages; page++ {
+		res, err := client.SearchCommits(ctx, query, page)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, it := range res.Items {
+			add(it.SHA, it.Repository.ID, it.Repository.FullName, !it.Repository.Private, it.Commit.Author.Date)
+		}
+		if len(res.Items) < 100 {
+			break
+		}
+	}
+
+	repos, err := s.backfillRepo.PrivateRepos(ctx, userID, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, r := range repos {
+		for page := 1; page <= maxCommitPages; page++ {
+			commits, err := client.ListCommits(ctx, r.FullName, login, from, to, page)
+			if githublib.IsNotFound(err) {
+				break // репозиторий удалён или доступ отозван
+			}
+			if err != nil {
+				return nil, 0, err
+			}
+			for _, c := range commits {
+				add(c.SHA, r.GitHubID, r.FullName, false, c.Commit.Author.Date)
+			}
+			if len(commits) < 100 {
+				break
+			}
+		}
+	}
+	commits := len(rows)
+
+	days, err := client.ContributionCalendar(ctx, login, from, to.Add(-time.Second))
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, d := range days {
+		extra := d.ContributionCount - perDay[d.Date]
+		if extra <= 0 {
+			continue
+		}
+		// У графика нет времени — ставим полдень UTC, чтобы день не съезжал в большинстве поясов
+		t, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			continue
+		}
+		t = t.Add(12 * time.Hour)
+		if t.Before(from) || !t.Before(to) {
+			continue
+		}
+		rows = append(rows, stats.ActivityEventRow{
+			GitHubEventID: "calendar:" + d.Date,
+			Type:          stats.EventCalendarDay,
+			IsPublic:      false, // в графике могут быть приватные вклады, если пользователь их показывает
+			Commits:       extra,
+			OccurredAt:    t,
+		})
+	}
+	return rows, commits, nil
+}
+
+func (s *syncService) emitBackfill(userID uuid.UUID, event string, p BackfillProgress) {
+	if s.notifier != nil {
+		s.notifier.BroadcastToUser(userID, event, p)
+	}
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"log"
+	"strings"
+	"github.com/google/uuid"
+	githublib "github.com/devsync/server/pkg/github"
+	"github.com/devsync/server/internal/domain/collab"
+)
+
+// За одну синхронизацию обходим не больше 10 изменившихся репозиториев и 20 последних PR в каждом:
+// ревью запрашиваются по одному PR, это самая дорогая часть по лимиту GitHub API.
+const (
+	maxCollabReposPerSync = 10
+	maxPullsPerRepo       = 20
+)
+
+func (s *syncService) scanCollaboration(ctx context.Context, client *githublib.Client, userID uuid.UUID) error {
+	repos, err := s.collabRepo.ReposToScan(ctx, userID, maxCollabReposPerSync)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		data, err := fetchCollaboration(ctx, client, repo)
+		if githublib.IsUnauthorized(err) {
+			return err
+		}
+		if err != nil {
+			log.Printf("sync %s: collaborators of %s: %v", userID, repo.FullName, err)
+			continue
+		}
+		if err := s.collabRepo.ReplaceRepo(ctx, userID, repo.ID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchCollaboration(ctx context.Context, client *githublib.Client, repo collab.ScanRepo) (collab.RepoData, error) {
+	var data collab.RepoData
+	contributors, err := client.GetRepoContributors(ctx, repo.FullName)
+	if err != nil && !githublib.IsNotFound(err) {
+		return data, err
+	}
+	for _, c := range contributors {
+		if isBot(c.Login, c.Type) {
+			continue
+		}
+		data.Contributors = append(data.Contributors, collab.ContributorRow{
+			RepoID: repo.ID, Login: c.Login, AvatarURL: c.AvatarURL, Contributions: c.Contributions,
+		})
+	}
+	pulls, err := client.ListPullRequests(ctx, repo.FullName, maxPullsPerRepo)
+	if err != nil && !githublib.IsNotFound(err) {
+		return data, err
+	}
+	for _, pr := range pulls {
+		if isBot(pr.User.Login, pr.User.Type) {
+			continue
+		}
+		data.PullRequests = append(data.PullRequests, collab.PullRequestRow{
+			RepoID: repo.ID, Number: pr.Number, AuthorLogin: pr.User.Login, AuthorAvatar: pr.User.AvatarURL,
+			Merged: pr.MergedAt != nil, CreatedAt: parseTime(pr.CreatedAt),
+		})
+		reviews, err := client.ListReviews(ctx, repo.FullName, pr.Number)
+		if err != nil {
+			return data, err
+		}
+		// несколько ревью одного человека на PR сводим в одну строку
+		byReviewer := make(map[string]*collab.ReviewRow)
+		for _, rv := range reviews {
+			if isBot(rv.User.Login, rv.User.Type) || strings.EqualFold(rv.User.Login, pr.User.Login) {
+				continue
+			}
+			row, ok := byReviewer[rv.User.Login]
+			if !ok {
+				row = &collab.ReviewRow{RepoID: repo.ID, PRNumber: pr.Number, ReviewerLogin: rv.User.Login, ReviewerAvatar: rv.User.AvatarURL}
+				byReviewer[rv.User.Login] = row
+			}
+			row.Reviews++
+			row.LastState = rv.State
+			row.SubmittedAt = parseTime(rv.SubmittedAt)
+		}
+		for _, row := range byReviewer {
+			data.Reviews = append(data.Reviews, *row)
+		}
+	}
+	return data, nil
+}
+
+func isBot(login, typ string) bool {
+	return login == "" || typ == "Bot" || strings.HasSuffix(login, "[bot]")
+}
@@ -0,0 +1,33 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	githublib "github.com/devsync/server/pkg/github"
+)
+
+func (s *syncService) MergedExternalPRs(ctx context.Context, userID uuid.UUID) (int, error) {
+	u, err := s.userSvc.GetByIDWithToken(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if u.AccessToken == "" || u.ReauthRequired {
+		return 0, ErrReauthRequired
+	}
+	token, err := s.accessToken(ctx, u)
+	if errors.Is(err, ErrReauthRequired) {
+		return 0, s.markReauth(ctx, userID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	client := githublib.NewLimitedClient(token, s.limits)
+	// -user: исключает репозитории самого пользователя; репозитории его организаций считаются чужими
+	n, err := client.SearchIssuesCount(ctx, fmt.Sprintf("is:pr is:merged author:%s -user:%s", u.Username, u.Username))
+	if githublib.IsUnauthorized(err) {
+		return 0, s.markReauth(ctx, userID)
+	}
+	return n, err
+}
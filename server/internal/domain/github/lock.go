@@ -0,0 +1,105 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/infrastructure/lease"
+)
+
+// Синхронизация пользователя держит аренду sync:<user_id> (holder — <id задачи>/<id запуска>) в worker_leases:
+// второй SyncUser того же пользователя не стартует, пока идёт первый. Аренда продлевается каждые
+// syncLockTTL/3; если процесс упал, она истекает сама.
+const syncLockTTL = 2 * time.Minute
+
+var (
+	ErrSyncInProgress = errors.New("sync already in progress")
+	errSyncLockLost   = errors.New("sync lock lost")
+)
+
+// SyncInProgressError — пользователя уже синхронизирует задача JobID.
+type SyncInProgressError struct {
+	JobID uuid.UUID
+}
+
+func (e *SyncInProgressError) Error() string {
+	return fmt.Sprintf("sync already in progress (job %s)", e.JobID)
+}
+
+func (e *SyncInProgressError) Is(target error) bool { return target == ErrSyncInProgress }
+
+func SyncLockName(userID uuid.UUID) string {
+	return "sync:" + userID.String()
+}
+
+// RunningSync — id задачи, которая сейчас синхронизирует пользователя; uuid.Nil — никто.
+func RunningSync(ctx context.Context, locks lease.Store, userID uuid.UUID) (uuid.UUID, error) {
+	l, err := locks.Get(ctx, SyncLockName(userID))
+	if err != nil || l == nil || time.Now().After(l.ExpiresAt) {
+		return uuid.Nil, err
+	}
+	jobID, _, _ := strings.Cut(l.Holder, "/")
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return uuid.Nil, nil
+	}
+	return id, nil
+}
+
+// lockUser берёт блокировку синхронизации. Возвращённый контекст отменяется, если блокировку
+// не удалось продлить (её мог забрать другой процесс); unlock освобождает её.
+func (s *syncService) lockUser(ctx context.Context, userID, jobID uuid.UUID) (context.Context, func(), error) {
+	if jobID == uuid.Nil {
+		jobID = uuid.New()
+	}
+	// Свой holder у каждого запуска: аренда продлевается тому же holder, и повторно выданная очередью
+	// задача (истекла видимость, а первый запуск ещё жив) иначе получила бы её тоже
+	name, holder := SyncLockName(userID), jobID.String()+"/"+uuid.NewString()
+	ok, err := s.locks.Acquire(ctx, name, holder, syncLockTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		running, err := RunningSync(ctx, s.locks, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, &SyncInProgressError{JobID: running}
+	}
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(syncLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-lockCtx.Done():
+				return
+			case <-ticker.C:
+				ok, err := s.locks.Acquire(lockCtx, name, holder, syncLockTTL)
+				if err != nil && lockCtx.Err() == nil {
+					log.Printf("sync %s: renew lock: %v", userID, err)
+					continue
+				}
+				if !ok && lockCtx.Err() == nil {
+					cancel(errSyncLockLost)
+					return
+				}
+			}
+		}
+	}()
+	unlock := func() {
+		close(done)
+		cancel(nil)
+		if err := s.locks.Release(context.Background(), name, holder); err != nil {
+			log.Printf("sync %s: release lock: %v", userID, err)
+		}
+	}
+	return lockCtx, unlock, nil
+}
@@ -0,0 +1,89 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/infrastructure/lease"
+)
+
+// memLocks — lease.Store в памяти с той же семантикой, что и worker_leases.
+type memLocks struct {
+	mu     sync.Mutex
+	leases map[string]lease.Lease
+}
+
+func newMemLocks() *memLocks {
+	return &memLocks{leases: make(map[string]lease.Lease)}
+}
+
+func (m *memLocks) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if l, ok := m.leases[name]; ok && l.Holder != holder && now.Before(l.ExpiresAt) {
+		return false, nil
+	}
+	m.leases[name] = lease.Lease{Name: name, Holder: holder, AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (m *memLocks) Release(ctx context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.leases[name]; ok && l.Holder == holder {
+		delete(m.leases, name)
+	}
+	return nil
+}
+
+func (m *memLocks) Get(ctx context.Context, name string) (*lease.Lease, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.leases[name]; ok {
+		return &l, nil
+	}
+	return nil, nil
+}
+
+func TestLockUserRejectsRedeliveredJob(t *testing.T) {
+	locks := newMemLocks()
+	s := &syncService{locks: locks}
+	userID, jobID := uuid.New(), uuid.New()
+
+	_, unlock, err := s.lockUser(context.Background(), userID, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// та же задача, повторно выданная очередью, пока первый запуск ещё держит блокировку
+	_, _, err = s.lockUser(context.Background(), userID, jobID)
+	var inProgress *SyncInProgressError
+	if !errors.As(err, &inProgress) || inProgress.JobID != jobID {
+		t.Fatalf("second lockUser error = %v, want SyncInProgressError for job %s", err, jobID)
+	}
+	if running, err := RunningSync(context.Background(), locks, userID); err != nil || running != jobID {
+		t.Errorf("RunningSync = %s, %v, want %s", running, err, jobID)
+	}
+
+	unlock()
+	if running, _ := RunningSync(context.Background(), locks, userID); running != uuid.Nil {
+		t.Errorf("RunningSync after unlock = %s, want none", running)
+	}
+	_, unlock, err = s.lockUser(context.Background(), userID, jobID)
+	if err != nil {
+		t.Fatalf("lockUser after unlock: %v", err)
+	}
+	unlock()
+}
+
+func TestRunningSyncIgnoresExpiredLease(t *testing.T) {
+	locks := newMemLocks()
+	userID := uuid.New()
+	locks.leases[SyncLockName(userID)] = lease.Lease{Holder: uuid.NewString() + "/" + uuid.NewString(), ExpiresAt: time.Now().Add(-time.Second)}
+	if running, err := RunningSync(context.Background(), locks, userID); err != nil || running != uuid.Nil {
+		t.Errorf("RunningSync = %s, %v, want none", running, err)
+	}
+}
@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"log"
+	"github.com/google/uuid"
+	githublib "github.com/devsync/server/pkg/github"
+	"github.com/devsync/server/pkg/manifest"
+	"github.com/devsync/server/internal/domain/stats"
+)
+
+// maxManifestReposPerSync ограничивает число сканируемых за одну синхронизацию репозиториев
+// (1 запрос на список файлов + по запросу на манифест); остальные доберутся в следующий раз.
+const maxManifestReposPerSync = 20
+
+// scanManifests читает манифесты в корне default-ветки изменившихся репозиториев и сохраняет зависимости.
+func (s *syncService) scanManifests(ctx context.Context, client *githublib.Client, userID uuid.UUID) error {
+	repos, err := s.depRepo.ReposToScan(ctx, userID, maxManifestReposPerSync)
+	if err != nil {
+		return err
+	}
+	for _, repo := range repos {
+		deps, err := fetchDependencies(ctx, client, repo)
+		if githublib.IsUnauthorized(err) {
+			return err
+		}
+		if err != nil {
+			// один сломанный репозиторий не должен валить синхронизацию
+			log.Printf("sync %s: manifests of %s: %v", userID, repo.FullName, err)
+			continue
+		}
+		if err := s.depRepo.ReplaceForRepo(ctx, userID, repo.ID, deps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fetchDependencies(ctx context.Context, client *githublib.Client, repo stats.RepoToScan) ([]stats.DependencyRow, error) {
+	entries, err := client.ListRootContents(ctx, repo.FullName, repo.DefaultBranch)
+	if githublib.IsNotFound(err) {
+		return nil, nil // пустой репозиторий
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []stats.DependencyRow
+	for _, e := range entries {
+		if e.Type != "file" || !manifest.Supported(e.Name) {
+			continue
+		}
+		data, err := client.GetRawFile(ctx, repo.FullName, e.Path, repo.DefaultBranch)
+		if err != nil {
+			return nil, err
+		}
+		deps, err := manifest.Parse(e.Name, data)
+		if err != nil {
+			log.Printf("parse %s/%s: %v", repo.FullName, e.Name, err)
+			continue
+		}
+		for _, d := range deps {
+			out = append(out, stats.DependencyRow{
+				Ecosystem: d.Ecosystem, Name: d.Name, Version: d.Version, Manifest: e.Name, IsDev: d.Dev,
+			})
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,37 @@
+package github
+
+import (
+	"github.com/google/uuid"
+)
+
+// События хода синхронизации (websocket; из worker — через Redis).
+const (
+	EventSyncStarted  = "sync_started"
+	EventSyncProgress = "sync_progress"
+	EventSyncFinished = "sync_finished"
+	EventSyncFailed   = "sync_failed"
+	EventStatsUpdated = "stats_updated"
+)
+
+// Этапы для EventSyncProgress.
+const (
+	StageRepos         = "repos"
+	StageManifests     = "manifests"
+	StageCollaboration = "collaboration"
+	StageEvents        = "events"
+)
+
+type SyncProgress struct {
+	JobID  uuid.UUID `json:"job_id"`
+	Stage  string    `json:"stage,omitempty"`
+	Page   int       `json:"page,omitempty"`
+	Repos  int       `json:"repos,omitempty"`  // сколько репозиториев получено
+	Events int       `json:"events,omitempty"` // сколько событий обработано
+	Error  string    `json:"error,omitempty"`
+}
+
+func (s *syncService) emit(userID uuid.UUID, event string, p SyncProgress) {
+	if s.notifier != nil {
+		s.notifier.BroadcastToUser(userID, event, p)
+	}
+}
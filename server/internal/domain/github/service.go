@@ -2,15 +2,34 @@ package github
 
 import (
 	"context"
+	"errors"
 	"time"
 	"github.com/google/uuid"
+	"golang.org/x/oauth2"
 	githublib "github.com/devsync/server/pkg/github"
+	"github.com/devsync/server/internal/domain/collab"
+	"github.com/devsync/server/internal/domain/backfill"
 	"github.com/devsync/server/internal/domain/stats"
 	"github.com/devsync/server/internal/domain/user"
+	"github.com/devsync/server/internal/infrastructure/lease"
 )
 
+// GitHub отдаёт не больше 300 событий (3 страницы по 100) за последние 90 дней.
+const maxEventPages = 3
+
 type SyncService interface {
-	SyncUser(ctx context.Context, userID uuid.UUID) error
+	// SyncUser синхронизирует пользователя; jobID попадает в события прогресса (uuid.Nil — без задачи).
+	// Если пользователя уже синхронизирует другая задача, сразу возвращает *SyncInProgressError.
+	SyncUser(ctx context.Context, userID, jobID uuid.UUID) error
+	// Backfill загружает очередную порцию истории пользователя (см. backfill.go).
+	Backfill(ctx context.Context, userID, jobID uuid.UUID) (*BackfillResult, error)
+	// MergedExternalPRs — число смёрженных PR пользователя в чужие репозитории (поиск GitHub).
+	MergedExternalPRs(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// Notifier — рассылка событий пользователю (websocket.Hub на сервере, Redis в worker).
+type Notifier interface {
+	BroadcastToUser(userID uuid.UUID, event string, data interface{})
 }
 
 type syncService struct {
@@ -18,6 +37,14 @@ type syncService struct {
 	repoRepo  stats.RepoRepository
 	contribRepo stats.ContributionRepository
 	dailyRepo stats.DailyStatsRepository
+	activityRepo stats.ActivityRepository
+	depRepo   stats.DependencyRepository
+	collabRepo collab.Repository
+	backfillRepo backfill.Repository
+	oauth     *oauth2.Config
+	limits    *githublib.Limits // общие на процесс лимиты GitHub API; nil — без ограничений
+	locks     lease.Store // блокировки синхронизации по пользователям (lock.go)
+	notifier  Notifier // может быть nil
 }
 
 func NewSyncService(
@@ -25,29 +52,82 @@ func NewSyncService(
 	repoRepo stats.RepoRepository,
 	contribRepo stats.ContributionRepository,
 	dailyRepo stats.DailyStatsRepository,
+	activityRepo stats.ActivityRepository,
+	depRepo stats.DependencyRepository,
+	collabRepo collab.Repository,
+	backfillRepo backfill.Repository,
+	oauth *oauth2.Config,
+	limits *githublib.Limits,
+	locks lease.Store,
+	notifier Notifier,
 ) SyncService {
 	return &syncService{
 		userSvc:     userSvc,
 		repoRepo:    repoRepo,
 		contribRepo: contribRepo,
 		dailyRepo:   dailyRepo,
+		activityRepo: activityRepo,
+		depRepo:     depRepo,
+		collabRepo:  collabRepo,
+		backfillRepo: backfillRepo,
+		oauth:       oauth,
+		limits:      limits,
+		locks:       locks,
+		notifier:    notifier,
+	}
+}
+
+func (s *syncService) SyncUser(ctx context.Context, userID, jobID uuid.UUID) error {
+	lockCtx, unlock, err := s.lockUser(ctx, userID, jobID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	s.emit(userID, EventSyncStarted, SyncProgress{JobID: jobID})
+	err = s.sync(lockCtx, userID, jobID)
+	if errors.Is(context.Cause(lockCtx), errSyncLockLost) {
+		err = errSyncLockLost
 	}
+	switch {
+	case err == nil:
+		s.emit(userID, EventSyncFinished, SyncProgress{JobID: jobID})
+		if s.notifier != nil {
+			s.notifier.BroadcastToUser(userID, EventStatsUpdated, nil)
+		}
+	case ctx.Err() == nil:
+		// Прерванная остановкой worker задача вернётся в очередь — это не ошибка
+		s.emit(userID, EventSyncFailed, SyncProgress{JobID: jobID, Error: err.Error()})
+	}
+	return err
 }
 
-func (s *syncService) SyncUser(ctx context.Context, userID uuid.UUID) error {
+func (s *syncService) sync(ctx context.Context, userID, jobID uuid.UUID) error {
 	u, err := s.userSvc.GetByIDWithToken(ctx, userID)
 	if err != nil {
 		return err
 	}
-	client := githublib.NewClient(u.AccessToken)
 	if u.AccessToken == "" {
 		return nil
 	}
+	if u.ReauthRequired {
+		return ErrReauthRequired
+	}
+	token, err := s.accessToken(ctx, u)
+	if errors.Is(err, ErrReauthRequired) {
+		return s.markReauth(ctx, userID)
+	}
+	if err != nil {
+		return err
+	}
+	client := githublib.NewLimitedClient(token, s.limits)
 
 	// Fetch repos
 	var allRepos []stats.RepoRow
 	for page := 1; ; page++ {
 		repos, err := client.GetUserRepos(ctx, page)
+		if githublib.IsUnauthorized(err) {
+			return s.markReauth(ctx, userID)
+		}
 		if err != nil {
 			return err
 		}
@@ -63,7 +143,7 @@ func (s *syncService) SyncUser(ctx context.Context, userID uuid.UUID) error {
 			if r.Language != nil {
 				lang = *r.Language
 			}
-			allRepos = append(allRepos, stats.RepoRow{
+			row := stats.RepoRow{
 				GitHubID:    r.ID,
 				Name:        r.Name,
 				FullName:    r.FullName,
@@ -72,8 +152,31 @@ func (s *syncService) SyncUser(ctx context.Context, userID uuid.UUID) error {
 				Forks:       r.Forks,
 				Language:    lang,
 				IsPrivate:   r.Private,
-			})
+				IsFork:      r.Fork,
+				IsArchived:  r.Archived,
+				IsTemplate:  r.IsTemplate,
+				Topics:      r.Topics,
+				SizeKB:      r.Size,
+				OpenIssues:  r.OpenIssues,
+				DefaultBranch: r.DefaultBranch,
+				CreatedAt:   parseTime(r.CreatedAt),
+				UpdatedAt:   parseTime(r.UpdatedAt),
+			}
+			if r.License != nil {
+				row.License = r.License.SPDXID
+				if row.License == "" || row.License == "NOASSERTION" {
+					row.License = r.License.Name
+				}
+			}
+			if r.Homepage != nil {
+				row.Homepage = *r.Homepage
+			}
+			if r.PushedAt != nil {
+				row.PushedAt = parseTime(*r.PushedAt)
+			}
+			allRepos = append(allRepos, row)
 		}
+		s.emit(userID, EventSyncProgress, SyncProgress{JobID: jobID, Stage: StageRepos, Page: page, Repos: len(allRepos)})
 		if len(repos) < 100 {
 			break
 		}
@@ -81,34 +184,80 @@ func (s *syncService) SyncUser(ctx context.Context, userID uuid.UUID) error {
 	if err := s.repoRepo.Upsert(ctx, userID, allRepos); err != nil {
 		return err
 	}
+	if err := s.dailyRepo.RecordStars(ctx, userID, time.Now().In(u.Location())); err != nil {
+		return err
+	}
+	s.emit(userID, EventSyncProgress, SyncProgress{JobID: jobID, Stage: StageManifests, Repos: len(allRepos)})
+	if err := s.scanManifests(ctx, client, userID); githublib.IsUnauthorized(err) {
+		return s.markReauth(ctx, userID)
+	} else if err != nil {
+		return err
+	}
+	s.emit(userID, EventSyncProgress, SyncProgress{JobID: jobID, Stage: StageCollaboration, Repos: len(allRepos)})
+	if err := s.scanCollaboration(ctx, client, userID); githublib.IsUnauthorized(err) {
+		return s.markReauth(ctx, userID)
+	} else if err != nil {
+		return err
+	}
 
-	// Derive contributions from events (public events for username)
-	events, err := client.GetUserEvents(ctx, u.Username, 1)
-	if err == nil {
-		byDate := make(map[string]int)
+	// Сохраняем события с точным временем; дни считаются в часовом поясе пользователя
+	var activity []stats.ActivityEventRow
+	for page := 1; page <= maxEventPages; page++ {
+		events, err := client.GetUserEvents(ctx, u.Username, page)
+		if githublib.IsUnauthorized(err) {
+			return s.markReauth(ctx, userID)
+		}
+		if err != nil {
+			break
+		}
 		for _, e := range events {
 			t, err := time.Parse(time.RFC3339, e.CreatedAt)
-			if err != nil {
+			if err != nil || e.ID == "" {
 				continue
 			}
-			date := t.Format("2006-01-02")
-			switch e.Type {
-			case "PushEvent":
-				byDate[date] += e.Payload.Size
-				if byDate[date] == 0 {
-					byDate[date] = 1
-				}
-			case "PullRequestEvent", "IssuesEvent":
-				byDate[date]++
-			default:
-				// optional: count other events
+			action := e.Payload.Action
+			if e.Type == "PullRequestEvent" && action == "closed" && e.Payload.PullRequest != nil && e.Payload.PullRequest.Merged {
+				action = stats.ActionMerged
+			}
+			activity = append(activity, stats.ActivityEventRow{
+				GitHubEventID: e.ID,
+				Type:          e.Type,
+				Action:        action,
+				RepoGitHubID:  e.Repo.ID,
+				RepoName:      e.Repo.Name,
+				IsPublic:      e.Public,
+				Commits:       e.Payload.Size,
+				OccurredAt:    t,
+			})
+		}
+		s.emit(userID, EventSyncProgress, SyncProgress{JobID: jobID, Stage: StageEvents, Page: page, Events: len(activity)})
+		if len(events) < 100 {
+			break
+		}
+	}
+	if len(activity) > 0 {
+		if _, err := s.activityRepo.Insert(ctx, userID, activity); err != nil {
+			return err
+		}
+		earliest := activity[0].OccurredAt
+		for _, e := range activity {
+			if e.OccurredAt.Before(earliest) {
+				earliest = e.OccurredAt
 			}
 		}
-		for date, count := range byDate {
-			_ = s.contribRepo.Upsert(ctx, userID, date, count, nil)
+		if err := s.activityRepo.Rebuild(ctx, userID, u.Location(), earliest); err != nil {
+			return err
 		}
 	}
 
 	_ = s.userSvc.UpdateLastSynced(ctx, userID)
 	return nil
 }
+
+func parseTime(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
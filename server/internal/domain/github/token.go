@@ -0,0 +1,83 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+// ErrReauthRequired — токен GitHub отозван или не обновляется, нужен повторный вход.
+var ErrReauthRequired = errors.New("github authorization expired, re-login required")
+
+// EventReauthRequired — событие WebSocket, после которого фронт предлагает войти заново.
+const EventReauthRequired = "reauth_required"
+
+// accessToken возвращает действующий токен, при необходимости обновляя его через refresh token.
+func (s *syncService) accessToken(ctx context.Context, u *user.User) (string, error) {
+	if s.oauth == nil || u.RefreshToken == nil || *u.RefreshToken == "" || u.TokenExpiresAt == nil {
+		return u.AccessToken, nil
+	}
+	current := &oauth2.Token{
+		AccessToken:  u.AccessToken,
+		RefreshToken: *u.RefreshToken,
+		Expiry:       *u.TokenExpiresAt,
+	}
+	tok, err := s.oauth.TokenSource(ctx, current).Token()
+	if err != nil {
+		var rErr *oauth2.RetrieveError
+		if errors.As(err, &rErr) && revoked(rErr) {
+			return "", ErrReauthRequired
+		}
+		// 5xx, 429, сбои прокси — временные: задача повторится
+		return "", err
+	}
+	if tok.AccessToken == "" {
+		// GitHub отвечает 200 с error=bad_refresh_token
+		return "", ErrReauthRequired
+	}
+	if tok.AccessToken != u.AccessToken {
+		refresh := tok.RefreshToken
+		if refresh == "" {
+			refresh = *u.RefreshToken
+		}
+		var expiry *time.Time
+		if !tok.Expiry.IsZero() {
+			expiry = &tok.Expiry
+		}
+		if err := s.userSvc.UpdateTokens(ctx, u.ID, tok.AccessToken, &refresh, expiry); err != nil {
+			return "", err
+		}
+		u.AccessToken = tok.AccessToken
+		u.RefreshToken = &refresh
+		u.TokenExpiresAt = expiry
+	}
+	return tok.AccessToken, nil
+}
+
+// revoked — GitHub отказал в обновлении токена: refresh token отозван или истёк. GitHub отвечает на это
+// 200 с error=bad_refresh_token, другие провайдеры — invalid_grant или 400/401.
+func revoked(err *oauth2.RetrieveError) bool {
+	switch err.ErrorCode {
+	case "bad_refresh_token", "invalid_grant":
+		return true
+	}
+	if err.Response != nil {
+		return err.Response.StatusCode == http.StatusBadRequest || err.Response.StatusCode == http.StatusUnauthorized
+	}
+	return false
+}
+
+// markReauth помечает пользователя, чтобы worker перестал его синхронизировать, и уведомляет фронт.
+func (s *syncService) markReauth(ctx context.Context, userID uuid.UUID) error {
+	if err := s.userSvc.MarkReauthRequired(ctx, userID); err != nil {
+		return err
+	}
+	if s.notifier != nil {
+		s.notifier.BroadcastToUser(userID, EventReauthRequired, nil)
+	}
+	return ErrReauthRequired
+}
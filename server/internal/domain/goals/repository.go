@@ -0,0 +1,162 @@
+package goals
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+var ErrNotFound = errors.New("goal not found")
+
+type Repository interface {
+	List(ctx context.Context, userID uuid.UUID) ([]models.Goal, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*models.Goal, error)
+	Create(ctx context.Context, g *models.Goal) error
+	Update(ctx context.Context, g *models.Goal) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	// Value — значение метрики за дни [from, to] (пояс loc нужен для событий с точным временем).
+	Value(ctx context.Context, userID uuid.UUID, metric string, loc *time.Location, from, to time.Time) (int, error)
+	// SavePeriod записывает итог периода; true — цель в этом периоде только что достигнута.
+	SavePeriod(ctx context.Context, goalID uuid.UUID, from, to time.Time, value, target int) (bool, error)
+	History(ctx context.Context, goalID uuid.UUID, limit int) ([]models.GoalPeriod, error)
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+const goalColumns = `id, user_id, metric, target, period, active, created_at`
+
+func scanGoal(row pgx.Row, g *models.Goal) error {
+	return row.Scan(&g.ID, &g.UserID, &g.Metric, &g.Target, &g.Period, &g.Active, &g.CreatedAt)
+}
+
+func (r *repo) List(ctx context.Context, userID uuid.UUID) ([]models.Goal, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+goalColumns+` FROM goals WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.Goal{}
+	for rows.Next() {
+		var g models.Goal
+		if err := scanGoal(rows, &g); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) Get(ctx context.Context, userID, id uuid.UUID) (*models.Goal, error) {
+	var g models.Goal
+	err := scanGoal(r.pool.QueryRow(ctx, `SELECT `+goalColumns+` FROM goals WHERE user_id = $1 AND id = $2`, userID, id), &g)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *repo) Create(ctx context.Context, g *models.Goal) error {
+	return r.pool.QueryRow(ctx, `INSERT INTO goals (user_id, metric, target, period, active)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		g.UserID, g.Metric, g.Target, g.Period, g.Active).Scan(&g.ID, &g.CreatedAt)
+}
+
+func (r *repo) Update(ctx context.Context, g *models.Goal) error {
+	err := r.pool.QueryRow(ctx, `UPDATE goals SET metric = $3, target = $4, period = $5, active = $6, updated_at = NOW()
+		WHERE user_id = $1 AND id = $2 RETURNING created_at`,
+		g.UserID, g.ID, g.Metric, g.Target, g.Period, g.Active).Scan(&g.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *repo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM goals WHERE user_id = $1 AND id = $2`, userID, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// metricQueries — SQL метрик по дням [$2, $3]; суммы — из тех же таблиц, что и статистика.
+var metricQueries = map[string]string{
+	MetricCommits:       `SELECT COALESCE(SUM(commits), 0) FROM daily_stats WHERE user_id = $1 AND date BETWEEN $2::date AND $3::date`,
+	MetricPRsOpened:     `SELECT COALESCE(SUM(prs), 0) FROM daily_stats WHERE user_id = $1 AND date BETWEEN $2::date AND $3::date`,
+	MetricIssues:        `SELECT COALESCE(SUM(issues), 0) FROM daily_stats WHERE user_id = $1 AND date BETWEEN $2::date AND $3::date`,
+	MetricContributions: `SELECT COALESCE(SUM(count), 0) FROM contributions WHERE user_id = $1 AND date BETWEEN $2::date AND $3::date`,
+	MetricActiveDays: `SELECT COUNT(DISTINCT date) FROM contributions
+		WHERE user_id = $1 AND date BETWEEN $2::date AND $3::date AND count > 0`,
+	MetricPRsMerged: `SELECT COUNT(*) FROM activity_events
+		WHERE user_id = $1 AND type = 'PullRequestEvent' AND action = 'merged'
+			AND (occurred_at AT TIME ZONE $4)::date BETWEEN $2::date AND $3::date`,
+}
+
+func (r *repo) Value(ctx context.Context, userID uuid.UUID, metric string, loc *time.Location, from, to time.Time) (int, error) {
+	query, ok := metricQueries[metric]
+	if !ok {
+		return 0, ErrInvalidGoal
+	}
+	args := []interface{}{userID, from.Format(dateLayout), to.Format(dateLayout)}
+	if metric == MetricPRsMerged {
+		args = append(args, loc.String())
+	}
+	var n int
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&n)
+	return n, err
+}
+
+func (r *repo) SavePeriod(ctx context.Context, goalID uuid.UUID, from, to time.Time, value, target int) (bool, error) {
+	var reached bool
+	err := r.pool.QueryRow(ctx, `WITH prev AS (
+			SELECT met FROM goal_periods WHERE goal_id = $1 AND period_start = $2::date)
+		INSERT INTO goal_periods (goal_id, period_start, period_end, value, target, met, reached_at)
+		VALUES ($1, $2::date, $3::date, $4, $5, $4::int >= $5::int, CASE WHEN $4::int >= $5::int THEN NOW() END)
+		ON CONFLICT (goal_id, period_start) DO UPDATE SET
+			period_end = EXCLUDED.period_end, value = EXCLUDED.value, target = EXCLUDED.target, met = EXCLUDED.met,
+			reached_at = CASE WHEN EXCLUDED.met THEN COALESCE(goal_periods.reached_at, NOW()) END,
+			updated_at = NOW()
+		RETURNING met AND NOT COALESCE((SELECT met FROM prev), false)`,
+		goalID, from.Format(dateLayout), to.Format(dateLayout), value, target).Scan(&reached)
+	return reached, err
+}
+
+func (r *repo) History(ctx context.Context, goalID uuid.UUID, limit int) ([]models.GoalPeriod, error) {
+	rows, err := r.pool.Query(ctx, `SELECT period_start, period_end, value, target, met, reached_at
+		FROM goal_periods WHERE goal_id = $1 ORDER BY period_start DESC LIMIT $2`, goalID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.GoalPeriod{}
+	for rows.Next() {
+		var p models.GoalPeriod
+		var start, end time.Time
+		var met bool
+		if err := rows.Scan(&start, &end, &p.Value, &p.Target, &met, &p.ReachedAt); err != nil {
+			return nil, err
+		}
+		p.PeriodStart, p.PeriodEnd = start.Format(dateLayout), end.Format(dateLayout)
+		if met {
+			p.Status = StatusMet
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,247 @@
+package goals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+// Метрики целей.
+const (
+	MetricCommits       = "commits"
+	MetricContributions = "contributions"
+	MetricPRsOpened     = "prs_opened"
+	MetricPRsMerged     = "prs_merged"
+	MetricIssues        = "issues"
+	MetricActiveDays    = "active_days"
+)
+
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Итоги периодов в истории.
+const (
+	StatusMet        = "met"
+	StatusMissed     = "missed"
+	StatusInProgress = "in_progress"
+)
+
+// EventGoalProgress — websocket-событие: цель в периоде достигнута (из worker — через Redis).
+const EventGoalProgress = "goal_progress"
+
+const (
+	MaxGoals  = 20
+	MaxTarget = 100000
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	ErrInvalidGoal  = errors.New("invalid goal")
+	ErrTooManyGoals = fmt.Errorf("at most %d goals per user", MaxGoals)
+)
+
+type Notifier interface {
+	BroadcastToUser(userID uuid.UUID, event string, data interface{})
+}
+
+type Service interface {
+	List(ctx context.Context, userID uuid.UUID) ([]models.Goal, error)
+	Create(ctx context.Context, userID uuid.UUID, g *models.Goal) error
+	Update(ctx context.Context, userID uuid.UUID, g *models.Goal) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	History(ctx context.Context, userID, id uuid.UUID, limit int) ([]models.GoalPeriod, error)
+	// Current — прогресс активных целей в текущем периоде (для дашборда и отчётов).
+	Current(ctx context.Context, userID uuid.UUID) ([]models.GoalProgress, error)
+	// Track пересчитывает текущий и предыдущий периоды после синхронизации и сообщает о достигнутых целях.
+	Track(ctx context.Context, userID uuid.UUID) error
+}
+
+type service struct {
+	repo     Repository
+	userSvc  user.Service
+	notifier Notifier
+}
+
+// NewService: notifier может быть nil — тогда события не рассылаются.
+func NewService(repo Repository, userSvc user.Service, notifier Notifier) Service {
+	return &service{repo: repo, userSvc: userSvc, notifier: notifier}
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]models.Goal, error) {
+	return s.repo.List(ctx, userID)
+}
+
+func validate(g *models.Goal) error {
+	if _, ok := metricQueries[g.Metric]; !ok {
+		return fmt.Errorf("%w: metric must be commits, contributions, prs_opened, prs_merged, issues or active_days", ErrInvalidGoal)
+	}
+	if g.Period != PeriodWeek && g.Period != PeriodMonth {
+		return fmt.Errorf("%w: period must be week or month", ErrInvalidGoal)
+	}
+	if g.Target < 1 || g.Target > MaxTarget {
+		return fmt.Errorf("%w: target must be between 1 and %d", ErrInvalidGoal, MaxTarget)
+	}
+	if g.Metric == MetricActiveDays && g.Target > periodDays(g.Period) {
+		return fmt.Errorf("%w: a %s has at most %d days", ErrInvalidGoal, g.Period, periodDays(g.Period))
+	}
+	return nil
+}
+
+func periodDays(period string) int {
+	if period == PeriodWeek {
+		return 7
+	}
+	return 31
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, g *models.Goal) error {
+	if err := validate(g); err != nil {
+		return err
+	}
+	existing, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(existing) >= MaxGoals {
+		return ErrTooManyGoals
+	}
+	g.UserID = userID
+	return s.repo.Create(ctx, g)
+}
+
+func (s *service) Update(ctx context.Context, userID uuid.UUID, g *models.Goal) error {
+	if err := validate(g); err != nil {
+		return err
+	}
+	g.UserID = userID
+	return s.repo.Update(ctx, g)
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+func (s *service) History(ctx context.Context, userID, id uuid.UUID, limit int) ([]models.GoalPeriod, error) {
+	if _, err := s.repo.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	today, _, err := s.today(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	periods, err := s.repo.History(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range periods {
+		p := &periods[i]
+		switch {
+		case p.Status == StatusMet:
+		case p.PeriodEnd >= today.Format(dateLayout):
+			p.Status = StatusInProgress
+		default:
+			p.Status = StatusMissed
+		}
+	}
+	return periods, nil
+}
+
+func (s *service) Current(ctx context.Context, userID uuid.UUID) ([]models.GoalProgress, error) {
+	today, loc, err := s.today(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := []models.GoalProgress{}
+	for _, g := range list {
+		if !g.Active {
+			continue
+		}
+		from, to := bounds(g.Period, today)
+		value, err := s.repo.Value(ctx, userID, g.Metric, loc, from, to)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, progress(g, from, to, value))
+	}
+	return out, nil
+}
+
+func (s *service) Track(ctx context.Context, userID uuid.UUID) error {
+	today, loc, err := s.today(ctx, userID)
+	if err != nil {
+		return err
+	}
+	list, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, g := range list {
+		if !g.Active {
+			continue
+		}
+		from, to := bounds(g.Period, today)
+		// Предыдущий период — ещё раз: события за его последние часы могли прийти только сейчас
+		prevFrom, prevTo := bounds(g.Period, from.AddDate(0, 0, -1))
+		created := g.CreatedAt.In(loc)
+		createdDay := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)
+		periods := [][2]time.Time{{from, to}}
+		if !prevTo.Before(createdDay) {
+			periods = append(periods, [2]time.Time{prevFrom, prevTo})
+		}
+		for _, p := range periods {
+			value, err := s.repo.Value(ctx, userID, g.Metric, loc, p[0], p[1])
+			if err != nil {
+				return err
+			}
+			reached, err := s.repo.SavePeriod(ctx, g.ID, p[0], p[1], value, g.Target)
+			if err != nil {
+				return err
+			}
+			if reached && s.notifier != nil {
+				s.notifier.BroadcastToUser(userID, EventGoalProgress, progress(g, p[0], p[1], value))
+			}
+		}
+	}
+	return nil
+}
+
+// today — сегодняшняя дата пользователя (полночь UTC, как даты в contributions) и его пояс.
+func (s *service) today(ctx context.Context, userID uuid.UUID) (time.Time, *time.Location, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+	loc := u.Location()
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC), loc, nil
+}
+
+// bounds — календарная неделя (с понедельника) или месяц, в которые попадает day.
+func bounds(period string, day time.Time) (time.Time, time.Time) {
+	if period == PeriodWeek {
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		return start, start.AddDate(0, 0, 6)
+	}
+	start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, -1)
+}
+
+func progress(g models.Goal, from, to time.Time, value int) models.GoalProgress {
+	return models.GoalProgress{
+		GoalID: g.ID, Metric: g.Metric, Target: g.Target, Period: g.Period,
+		PeriodStart: from.Format(dateLayout), PeriodEnd: to.Format(dateLayout),
+		Value: value, Percent: math.Round(float64(value)/float64(g.Target)*1000) / 10, Met: value >= g.Target,
+	}
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Achievement — достижение и прогресс пользователя по нему.
+type Achievement struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Badge       string     `json:"badge"`
+	Metric      string     `json:"metric"`
+	Threshold   int        `json:"threshold"`
+	Progress    int        `json:"progress"` // значение метрики при последней проверке
+	Percent     float64    `json:"percent"`  // 0–100; у полученных всегда 100
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// Achievements — полученные (новые первыми) и ещё закрытые (ближние к цели первыми).
+type Achievements struct {
+	Earned []Achievement `json:"earned"`
+	Locked []Achievement `json:"locked"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// Collaborator — человек, с которым пользователь работает в общих репозиториях.
+type Collaborator struct {
+	Login           string `json:"login"`
+	AvatarURL       string `json:"avatar_url,omitempty"`
+	SharedRepos     int    `json:"shared_repos"`
+	SharedCommits   int    `json:"shared_commits"`
+	ReviewsGiven    int    `json:"reviews_given"`    // пользователь ревьюил PR коллеги
+	ReviewsReceived int    `json:"reviews_received"` // коллега ревьюил PR пользователя
+	PullRequests    int    `json:"pull_requests"`    // PR, где они были автором и ревьюером
+	Weight          int    `json:"weight"`
+}
+
+// CollaborationGraph — экспорт для отрисовки на дашборде (nodes/edges).
+type CollaborationGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	ID        string `json:"id"`
+	Login     string `json:"login"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+	IsSelf    bool   `json:"is_self"`
+	Weight    int    `json:"weight"`
+}
+
+type GraphEdge struct {
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Repos   int    `json:"repos"`
+	Commits int    `json:"commits"`
+	Reviews int    `json:"reviews"`
+	PRs     int    `json:"prs"`
+	Weight  int    `json:"weight"`
+}
@@ -0,0 +1,43 @@
+package models
+
+// Comparison — ответ GET /api/compare: пользователи в порядке запроса, ряды и доли языков выровнены
+// по общим Buckets и Languages.
+type Comparison struct {
+	Period      PeriodRange    `json:"period"`
+	Granularity string         `json:"granularity"` // day, week, month — шаг рядов
+	Buckets     []string       `json:"buckets"`     // первый день каждого шага
+	Languages   []string       `json:"languages"`   // самые заметные языки всех участников сравнения
+	Users       []ComparedUser `json:"users"`
+}
+
+// ComparedUser — данные одного пользователя; раздела нет, если он скрыт в публичном профиле.
+type ComparedUser struct {
+	Username  string           `json:"username"`
+	AvatarURL *string          `json:"avatar_url,omitempty"`
+	Access    string           `json:"access"`   // self, team — вся статистика; public — только публичный профиль
+	Sections  []string         `json:"sections"` // totals, contributions, languages, streaks
+	Totals    *ComparedTotals  `json:"totals,omitempty"`
+	Series    *ComparedSeries  `json:"series,omitempty"`
+	Languages []float64        `json:"languages,omitempty"` // доли (%) в порядке Comparison.Languages
+	Streaks   *ComparedStreaks `json:"streaks,omitempty"`
+}
+
+type ComparedTotals struct {
+	Contributions int `json:"contributions"`
+	Commits       int `json:"commits"`
+	PRs           int `json:"prs"`
+	Issues        int `json:"issues"`
+	Reviews       int `json:"reviews"` // ревью PR в репозиториях, синхронизированных в DevSync
+	ActiveDays    int `json:"active_days"`
+}
+
+// ComparedSeries — вклады по шагам Comparison.Buckets.
+type ComparedSeries struct {
+	Values     []int     `json:"values"`
+	Normalized []float64 `json:"normalized"` // Values, делённые на максимум пользователя: форма активности без масштаба, 0–1
+}
+
+type ComparedStreaks struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
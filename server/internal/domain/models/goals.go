@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// Goal — цель пользователя: Target единиц метрики Metric за каждый календарный период Period.
+type Goal struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"-"`
+	Metric    string    `json:"metric"` // commits, contributions, prs_opened, prs_merged, issues, active_days
+	Target    int       `json:"target"`
+	Period    string    `json:"period"` // week (с понедельника), month
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GoalProgress — состояние цели в текущем периоде.
+type GoalProgress struct {
+	GoalID      uuid.UUID `json:"goal_id"`
+	Metric      string    `json:"metric"`
+	Target      int       `json:"target"`
+	Period      string    `json:"period"`
+	PeriodStart string    `json:"period_start"`
+	PeriodEnd   string    `json:"period_end"`
+	Value       int       `json:"value"`
+	Percent     float64   `json:"percent"` // может быть больше 100
+	Met         bool      `json:"met"`
+}
+
+// GoalPeriod — итог цели за один период.
+type GoalPeriod struct {
+	PeriodStart string     `json:"period_start"`
+	PeriodEnd   string     `json:"period_end"`
+	Value       int        `json:"value"`
+	Target      int        `json:"target"`
+	Status      string     `json:"status"` // met, missed, in_progress
+	ReachedAt   *time.Time `json:"reached_at,omitempty"`
+}
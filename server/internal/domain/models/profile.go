@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ProfileVisibility — публичный ли профиль и какие разделы в нём видны.
+type ProfileVisibility struct {
+	Public    bool       `json:"public"`
+	Sections  []string   `json:"sections"` // totals, contributions, repos, languages, streaks, achievements
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// PublicProfile — статистика для GET /api/public/:username/stats. Считается только по публичным
+// репозиториям; скрытые пользователем разделы отсутствуют.
+type PublicProfile struct {
+	Username      string            `json:"username"`
+	AvatarURL     *string           `json:"avatar_url,omitempty"`
+	Period        PeriodRange       `json:"period"`
+	Sections      []string          `json:"sections"`
+	Totals        *PublicTotals     `json:"totals,omitempty"`
+	Contributions []ContributionDay `json:"contributions,omitempty"`
+	TopRepos      []Repo            `json:"top_repos,omitempty"`
+	Languages     []LanguageStats   `json:"languages,omitempty"`
+	Streaks       *Streaks          `json:"streaks,omitempty"`
+	Achievements  []Achievement     `json:"achievements,omitempty"` // только полученные
+}
+
+type PublicTotals struct {
+	Contributions int `json:"contributions"`
+	Commits       int `json:"commits"`
+	PRs           int `json:"prs"`
+	Issues        int `json:"issues"`
+	ActiveDays    int `json:"active_days"`
+	Repos         int `json:"repos"` // публичные репозитории пользователя
+	Stars         int `json:"stars"`
+}
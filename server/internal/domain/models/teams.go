@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+	"github.com/google/uuid"
+)
+
+// Team — команда; Role — роль того, кто запрашивает.
+type Team struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   uuid.UUID `json:"owner_id"`
+	Role      string    `json:"role"` // owner, admin, member
+	Members   int       `json:"members"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TeamMember struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	AvatarURL *string   `json:"avatar_url,omitempty"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// TeamInvitation — приглашение по логину GitHub; пользователь может зарегистрироваться уже после него.
+type TeamInvitation struct {
+	ID          uuid.UUID  `json:"id"`
+	TeamID      uuid.UUID  `json:"team_id"`
+	TeamName    string     `json:"team_name"`
+	Username    string     `json:"username"`
+	Role        string     `json:"role"`
+	InvitedBy   string     `json:"invited_by"`
+	Status      string     `json:"status"` // pending, accepted, declined
+	CreatedAt   time.Time  `json:"created_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+}
+
+// TeamStats — статистика команды в форме UserStats и разбивка по участникам.
+// Репозитории, общие для нескольких участников, считаются один раз; серий у команды нет — они у участников.
+type TeamStats struct {
+	Team Team `json:"team"`
+	UserStats
+	Members []TeamMemberStats `json:"members"`
+}
+
+type TeamMemberStats struct {
+	UserID        uuid.UUID    `json:"user_id"`
+	Username      string       `json:"username"`
+	Role          string       `json:"role"`
+	Totals        PeriodTotals `json:"totals"`
+	TotalRepos    int          `json:"total_repos"`
+	TotalStars    int          `json:"total_stars"`
+	CurrentStreak int          `json:"current_streak"`
+	LongestStreak int          `json:"longest_streak"`
+	WorkPattern   WorkPattern  `json:"work_pattern"`
+	Share         float64      `json:"share"` // доля вкладов команды за период, %
+}
@@ -1,8 +1,12 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+	"github.com/google/uuid"
+)
 
 type UserStats struct {
+	Period           PeriodRange        `json:"period"`
 	TotalRepos       int                `json:"total_repos"`
 	TotalStars       int                `json:"total_stars"`
 	TotalForks       int                `json:"total_forks"`
@@ -11,6 +15,127 @@ type UserStats struct {
 	TopRepos         []Repo             `json:"top_repos"`
 	DailyStats       []DailyStats       `json:"daily_stats"`
 	ContributionSum  int                `json:"contribution_sum"`
+	Totals           PeriodTotals       `json:"totals"`
+	Streaks          Streaks            `json:"streaks"`
+	WorkPattern      WorkPattern        `json:"work_pattern"`
+	Goals            []GoalProgress     `json:"goals"` // активные цели в текущем периоде
+	Comparison       *PeriodComparison  `json:"comparison,omitempty"` // только с ?compare=
+	Badges           []Achievement      `json:"badges,omitempty"`     // полученные достижения; только в отчётах
+	TechStack        TechStack          `json:"tech_stack"`
+}
+
+// Streaks — серии дней с вкладами за всю историю, в часовом поясе пользователя.
+// Дни отдыха (выходные, если включено, и объявленные) серию не прерывают, но и не удлиняют.
+type Streaks struct {
+	Current      Streak   `json:"current"` // Length 0 — серии сейчас нет
+	Longest      Streak   `json:"longest"`
+	History      []Streak `json:"history"` // серии не короче MinLength, новые первыми
+	MinLength    int      `json:"min_length"`
+	RestWeekends bool     `json:"rest_weekends"`
+}
+
+type Streak struct {
+	Start  string `json:"start,omitempty"`
+	End    string `json:"end,omitempty"`
+	Length int    `json:"length"` // дней с вкладами
+	Days   int    `json:"days"`   // календарных дней от Start до End
+}
+
+// PeriodTotals — суммы за период; считаются по самым крупным готовым агрегатам (год, месяц, неделя).
+type PeriodTotals struct {
+	Contributions int `json:"contributions"`
+	Commits       int `json:"commits"`
+	PRs           int `json:"prs"`
+	Issues        int `json:"issues"`
+	StarsReceived int `json:"stars_received"`
+	ActiveDays    int `json:"active_days"`
+}
+
+// WorkPattern — доли вкладов в выходные и вне рабочих часов (будни до 9:00 и с 18:00) в поясе пользователя.
+// Считаются только вклады с известным временем (timed_contributions).
+type WorkPattern struct {
+	WeekendRatio       float64 `json:"weekend_ratio"`
+	AfterHoursRatio    float64 `json:"after_hours_ratio"`
+	TimedContributions int     `json:"timed_contributions"`
+}
+
+// Punchcard — вклады по дню недели и часу: Matrix[0] — понедельник, Matrix[d][h] — час h.
+type Punchcard struct {
+	Period PeriodRange `json:"period"`
+	Matrix [][]int     `json:"matrix"`
+	WorkPattern
+}
+
+// PeriodRange — разрешённый период запроса: дни [From, To] в поясе пользователя.
+type PeriodRange struct {
+	Name string `json:"name"` // year, last_month, 2026-Q1, all, custom, ...
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Label — подпись периода в отчётах.
+func (p PeriodRange) Label() string {
+	if p.Name == "custom" {
+		return p.From + " — " + p.To
+	}
+	return p.Name
+}
+
+// PeriodComparison — суммы и доли языков за период рядом с периодом сравнения.
+type PeriodComparison struct {
+	Compare  string         `json:"compare"` // previous, same_period_last_year
+	Current  PeriodSnapshot `json:"current"`
+	Previous PeriodSnapshot `json:"previous"`
+	Deltas   PeriodDeltas   `json:"deltas"`
+}
+
+type PeriodSnapshot struct {
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Totals    PeriodTotals    `json:"totals"`
+	Languages []LanguageShare `json:"languages"`
+}
+
+// LanguageShare — доля языка во вкладах за период (по языку репозитория).
+type LanguageShare struct {
+	Language      string  `json:"language"`
+	Contributions int     `json:"contributions"`
+	Percent       float64 `json:"percent"`
+}
+
+type PeriodDeltas struct {
+	Contributions Delta           `json:"contributions"`
+	Commits       Delta           `json:"commits"`
+	PRs           Delta           `json:"prs"`
+	Issues        Delta           `json:"issues"`
+	StarsReceived Delta           `json:"stars_received"`
+	ActiveDays    Delta           `json:"active_days"`
+	Languages     []LanguageDelta `json:"languages"`
+}
+
+type Delta struct {
+	Abs     int      `json:"abs"`
+	Percent *float64 `json:"percent"` // null — в периоде сравнения был 0
+}
+
+// LanguageDelta — изменение доли языка в процентных пунктах.
+type LanguageDelta struct {
+	Language string  `json:"language"`
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	Delta    float64 `json:"delta"`
+}
+
+// TechStack — фреймворки и библиотеки из манифестов репозиториев (go.mod, package.json, ...).
+type TechStack struct {
+	Frameworks []TechItem `json:"frameworks"`
+	Libraries  []TechItem `json:"libraries"`
+}
+
+type TechItem struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+	Repos     int    `json:"repos"`
 }
 
 type Repo struct {
@@ -24,11 +149,25 @@ type Repo struct {
 	Forks       int       `json:"forks"`
 	Language    string    `json:"language"`
 	IsPrivate   bool      `json:"is_private"`
+	IsFork      bool      `json:"is_fork"`
+	IsArchived  bool      `json:"is_archived"`
+	IsTemplate  bool      `json:"is_template"`
+	Topics      []string  `json:"topics"`
+	License     string    `json:"license,omitempty"`
+	SizeKB      int       `json:"size_kb"`
+	OpenIssues  int       `json:"open_issues"`
+	Homepage    string    `json:"homepage,omitempty"`
+	DefaultBranch string  `json:"default_branch,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	PushedAt    *time.Time `json:"pushed_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
 }
 
 type ContributionDay struct {
 	Date  string `json:"date"`
 	Count int    `json:"count"`
+	// Granularity — week, если сырые дни уже удалены и точка — неделя с понедельника Date; для дней пусто
+	Granularity string `json:"granularity,omitempty"`
 }
 
 type LanguageStats struct {
@@ -44,4 +183,5 @@ type DailyStats struct {
 	PRs            int       `json:"prs"`
 	Issues         int       `json:"issues"`
 	StarsReceived  int       `json:"stars_received"`
+	Granularity    string    `json:"granularity,omitempty"` // как у ContributionDay
 }
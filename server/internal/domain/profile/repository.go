@@ -0,0 +1,123 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+// Day — публичные вклады за день в поясе пользователя.
+type Day struct {
+	Date          string
+	Contributions int
+	Commits       int
+	PRs           int
+	Issues        int
+}
+
+type Repository interface {
+	// Visibility — сохранённые настройки; nil, если пользователь их не менял.
+	Visibility(ctx context.Context, userID uuid.UUID) (*models.ProfileVisibility, error)
+	SaveVisibility(ctx context.Context, userID uuid.UUID, v *models.ProfileVisibility) error
+	// Days — публичные вклады по дням [from, to]; дни без вкладов пропускаются.
+	Days(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) ([]Day, error)
+	// ActiveDays — все даты с публичными вкладами (для серий).
+	ActiveDays(ctx context.Context, userID uuid.UUID, loc *time.Location) ([]string, error)
+	FirstDay(ctx context.Context, userID uuid.UUID, loc *time.Location) (*time.Time, error)
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+func (r *repo) Visibility(ctx context.Context, userID uuid.UUID) (*models.ProfileVisibility, error) {
+	var v models.ProfileVisibility
+	err := r.pool.QueryRow(ctx, `SELECT public, sections, updated_at FROM profile_visibility WHERE user_id = $1`, userID).
+		Scan(&v.Public, &v.Sections, &v.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *repo) SaveVisibility(ctx context.Context, userID uuid.UUID, v *models.ProfileVisibility) error {
+	return r.pool.QueryRow(ctx, `INSERT INTO profile_visibility (user_id, public, sections) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET public = EXCLUDED.public, sections = EXCLUDED.sections, updated_at = NOW()
+		RETURNING updated_at`, userID, v.Public, v.Sections).Scan(&v.UpdatedAt)
+}
+
+// publicEvents — события, которые можно показывать: публичные, с известным репозиторием, который
+// пользователь не синхронизировал как приватный. Дни из графика GitHub (CalendarDay) не входят —
+// в них могут быть приватные вклады. Восстановленные коммиты — только до начала данных Events API.
+const publicEvents = `WITH events_start AS (
+		SELECT COALESCE(MIN(occurred_at), 'infinity'::timestamptz) AS t FROM activity_events
+		WHERE user_id = $1 AND type NOT IN ('BackfillCommit', 'CalendarDay')),
+	events AS (
+		SELECT e.* FROM activity_events e
+		LEFT JOIN repositories r ON r.user_id = e.user_id AND r.github_id = e.repo_github_id
+		WHERE e.user_id = $1 AND e.is_public AND e.repo_github_id IS NOT NULL AND NOT COALESCE(r.is_private, false)
+			AND e.type IN ('PushEvent', 'PullRequestEvent', 'IssuesEvent', 'BackfillCommit')
+			AND (e.type <> 'BackfillCommit' OR e.occurred_at < (SELECT t FROM events_start)))`
+
+func (r *repo) Days(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) ([]Day, error) {
+	rows, err := r.pool.Query(ctx, publicEvents+`
+		SELECT (occurred_at AT TIME ZONE $2)::date AS day,
+			SUM(CASE WHEN type = 'PushEvent' THEN GREATEST(commits, 1) WHEN type = 'BackfillCommit' THEN commits ELSE 1 END),
+			COALESCE(SUM(commits) FILTER (WHERE type IN ('PushEvent', 'BackfillCommit')), 0),
+			COUNT(*) FILTER (WHERE type = 'PullRequestEvent' AND action = 'opened'),
+			COUNT(*) FILTER (WHERE type = 'IssuesEvent' AND action = 'opened')
+		FROM events
+		WHERE (occurred_at AT TIME ZONE $2)::date BETWEEN $3::date AND $4::date
+		GROUP BY day ORDER BY day`, userID, loc.String(), from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Day
+	for rows.Next() {
+		var d Day
+		var day time.Time
+		if err := rows.Scan(&day, &d.Contributions, &d.Commits, &d.PRs, &d.Issues); err != nil {
+			return nil, err
+		}
+		d.Date = day.Format(dateLayout)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) ActiveDays(ctx context.Context, userID uuid.UUID, loc *time.Location) ([]string, error) {
+	rows, err := r.pool.Query(ctx, publicEvents+`
+		SELECT DISTINCT (occurred_at AT TIME ZONE $2)::date AS day FROM events ORDER BY day`, userID, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			return nil, err
+		}
+		out = append(out, d.Format(dateLayout))
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) FirstDay(ctx context.Context, userID uuid.UUID, loc *time.Location) (*time.Time, error) {
+	var day *time.Time
+	err := r.pool.QueryRow(ctx, publicEvents+`
+		SELECT MIN((occurred_at AT TIME ZONE $2)::date) FROM events`, userID, loc.String()).Scan(&day)
+	return day, err
+}
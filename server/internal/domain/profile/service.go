@@ -0,0 +1,363 @@
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/stats"
+	"github.com/devsync/server/internal/domain/user"
+	"github.com/devsync/server/internal/infrastructure/cache"
+)
+
+// Разделы публичного профиля.
+const (
+	SectionTotals        = "totals"
+	SectionContributions = "contributions"
+	SectionRepos         = "repos"
+	SectionLanguages     = "languages"
+	SectionStreaks       = "streaks"
+	SectionAchievements  = "achievements"
+)
+
+// Sections — все разделы в порядке показа.
+var Sections = []string{SectionTotals, SectionContributions, SectionRepos, SectionLanguages, SectionStreaks, SectionAchievements}
+
+// DefaultSections — что видно, если пользователь открыл профиль, не выбирая разделы.
+var DefaultSections = []string{SectionTotals, SectionContributions, SectionRepos, SectionLanguages}
+
+// CacheTTL — сколько живут ответы публичных эндпоинтов; смена настроек видна сразу (они входят в ключ).
+const CacheTTL = 5 * time.Minute
+
+const (
+	dateLayout      = "2006-01-02"
+	publicRepoLimit = 1000
+	topRepos        = 10
+)
+
+var (
+	// ErrNotFound — нет такого пользователя, профиль закрыт или раздел скрыт: причину не раскрываем.
+	ErrNotFound          = errors.New("profile not found")
+	ErrInvalidVisibility = errors.New("invalid visibility")
+)
+
+// StatsProvider — репозитории и настройки серий пользователя.
+type StatsProvider interface {
+	GetRepos(ctx context.Context, userID uuid.UUID, f stats.RepoFilter) ([]models.Repo, error)
+	StreakSettings(ctx context.Context, userID uuid.UUID) (*stats.StreakSettings, error)
+	RestDays(ctx context.Context, userID uuid.UUID) ([]stats.RestDay, error)
+}
+
+// AchievementsProvider — полученные достижения для раздела achievements.
+type AchievementsProvider interface {
+	List(ctx context.Context, userID uuid.UUID) (*models.Achievements, error)
+}
+
+type Service interface {
+	Visibility(ctx context.Context, userID uuid.UUID) (*models.ProfileVisibility, error)
+	UpdateVisibility(ctx context.Context, userID uuid.UUID, v *models.ProfileVisibility) error // ErrInvalidVisibility
+
+	// Stats — публичная статистика за период (stats.ErrInvalidPeriod — неверный период).
+	Stats(ctx context.Context, username string, q stats.PeriodQuery) (*models.PublicProfile, error)
+	Contributions(ctx context.Context, username string, q stats.PeriodQuery) ([]models.ContributionDay, error)
+	// Repos — только публичные репозитории; фильтр private игнорируется.
+	Repos(ctx context.Context, username string, f stats.RepoFilter) ([]models.Repo, error)
+}
+
+type service struct {
+	repo           Repository
+	userSvc        user.Service
+	statsSvc       StatsProvider
+	achievementSvc AchievementsProvider
+	cache          cache.Cache
+}
+
+// NewService: cache может быть nil — тогда ответы не кэшируются.
+func NewService(repo Repository, userSvc user.Service, statsSvc StatsProvider, achievementSvc AchievementsProvider, c cache.Cache) Service {
+	return &service{repo: repo, userSvc: userSvc, statsSvc: statsSvc, achievementSvc: achievementSvc, cache: c}
+}
+
+func (s *service) Visibility(ctx context.Context, userID uuid.UUID) (*models.ProfileVisibility, error) {
+	v, err := s.repo.Visibility(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		v = &models.ProfileVisibility{Sections: append([]string(nil), DefaultSections...)}
+	}
+	return v, nil
+}
+
+func (s *service) UpdateVisibility(ctx context.Context, userID uuid.UUID, v *models.ProfileVisibility) error {
+	if v.Sections == nil {
+		v.Sections = append([]string(nil), DefaultSections...)
+	}
+	wanted := make(map[string]bool, len(v.Sections))
+	for _, sec := range v.Sections {
+		sec = strings.ToLower(strings.TrimSpace(sec))
+		if !contains(Sections, sec) {
+			return fmt.Errorf("%w: unknown section %q", ErrInvalidVisibility, sec)
+		}
+		wanted[sec] = true
+	}
+	// Порядок — как в Sections, без повторов
+	v.Sections = []string{}
+	for _, sec := range Sections {
+		if wanted[sec] {
+			v.Sections = append(v.Sections, sec)
+		}
+	}
+	return s.repo.SaveVisibility(ctx, userID, v)
+}
+
+func (s *service) Stats(ctx context.Context, username string, q stats.PeriodQuery) (*models.PublicProfile, error) {
+	u, vis, err := s.open(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	var out models.PublicProfile
+	key := cacheKey(u.ID, vis, "stats", q.Period, q.From, q.To)
+	if s.cached(ctx, key, &out) {
+		return &out, nil
+	}
+	loc := u.Location()
+	p, err := s.period(ctx, u.ID, loc, q)
+	if err != nil {
+		return nil, err
+	}
+	out = models.PublicProfile{Username: u.Username, AvatarURL: u.AvatarURL, Period: p.Range(), Sections: vis.Sections}
+	show := func(sec string) bool { return contains(vis.Sections, sec) }
+
+	if show(SectionTotals) || show(SectionContributions) {
+		days, err := s.repo.Days(ctx, u.ID, loc, p.From, p.To)
+		if err != nil {
+			return nil, err
+		}
+		if show(SectionTotals) {
+			out.Totals = &models.PublicTotals{}
+			for _, d := range days {
+				out.Totals.Contributions += d.Contributions
+				out.Totals.Commits += d.Commits
+				out.Totals.PRs += d.PRs
+				out.Totals.Issues += d.Issues
+				out.Totals.ActiveDays++
+			}
+		}
+		if show(SectionContributions) {
+			out.Contributions = contributionDays(days)
+		}
+	}
+	if show(SectionTotals) || show(SectionRepos) || show(SectionLanguages) {
+		repos, err := s.publicRepos(ctx, u.ID, stats.RepoFilter{Limit: publicRepoLimit})
+		if err != nil {
+			return nil, err
+		}
+		if show(SectionTotals) {
+			out.Totals.Repos = len(repos)
+			for _, r := range repos {
+				out.Totals.Stars += r.Stars
+			}
+		}
+		if show(SectionRepos) {
+			out.TopRepos = repos
+			if len(repos) > topRepos {
+				out.TopRepos = repos[:topRepos]
+			}
+		}
+		if show(SectionLanguages) {
+			out.Languages = stats.CalculateLanguageStats(repos)
+		}
+	}
+	if show(SectionStreaks) {
+		if out.Streaks, err = s.streaks(ctx, u.ID, loc); err != nil {
+			return nil, err
+		}
+	}
+	if show(SectionAchievements) {
+		list, err := s.achievementSvc.List(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		out.Achievements = list.Earned
+	}
+	s.store(ctx, key, &out)
+	return &out, nil
+}
+
+func (s *service) Contributions(ctx context.Context, username string, q stats.PeriodQuery) ([]models.ContributionDay, error) {
+	u, vis, err := s.open(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(vis.Sections, SectionContributions) {
+		return nil, ErrNotFound
+	}
+	out := []models.ContributionDay{}
+	key := cacheKey(u.ID, vis, "contributions", q.Period, q.From, q.To)
+	if s.cached(ctx, key, &out) {
+		return out, nil
+	}
+	loc := u.Location()
+	p, err := s.period(ctx, u.ID, loc, q)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.repo.Days(ctx, u.ID, loc, p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+	out = contributionDays(days)
+	s.store(ctx, key, out)
+	return out, nil
+}
+
+func (s *service) Repos(ctx context.Context, username string, f stats.RepoFilter) ([]models.Repo, error) {
+	u, vis, err := s.open(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(vis.Sections, SectionRepos) {
+		return nil, ErrNotFound
+	}
+	out := []models.Repo{}
+	key := cacheKey(u.ID, vis, "repos", fmt.Sprintf("%+v", repoFilterKey(f)))
+	if s.cached(ctx, key, &out) {
+		return out, nil
+	}
+	out, err = s.publicRepos(ctx, u.ID, f)
+	if err != nil {
+		return nil, err
+	}
+	s.store(ctx, key, out)
+	return out, nil
+}
+
+// open — пользователь с открытым профилем и его настройки.
+func (s *service) open(ctx context.Context, username string) (*user.User, *models.ProfileVisibility, error) {
+	u, err := s.userSvc.GetByUsername(ctx, username)
+	if errors.Is(err, user.ErrNotFound) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	vis, err := s.repo.Visibility(ctx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if vis == nil || !vis.Public {
+		return nil, nil, ErrNotFound
+	}
+	return u, vis, nil
+}
+
+func (s *service) period(ctx context.Context, userID uuid.UUID, loc *time.Location, q stats.PeriodQuery) (stats.Period, error) {
+	p, err := stats.ResolvePeriod(q, time.Now().In(loc))
+	if err != nil || p.Name != stats.PeriodAll {
+		return p, err
+	}
+	first, err := s.repo.FirstDay(ctx, userID, loc)
+	if err != nil {
+		return p, err
+	}
+	p.From = p.To
+	if first != nil {
+		p.From = *first
+	}
+	return p, nil
+}
+
+// publicRepos — репозитории пользователя без приватных, что бы ни было в фильтре.
+func (s *service) publicRepos(ctx context.Context, userID uuid.UUID, f stats.RepoFilter) ([]models.Repo, error) {
+	private := false
+	f.Private = &private
+	return s.statsSvc.GetRepos(ctx, userID, f)
+}
+
+// streaks — серии только по публичным вкладам, с правилами отдыха пользователя.
+func (s *service) streaks(ctx context.Context, userID uuid.UUID, loc *time.Location) (*models.Streaks, error) {
+	set, err := s.statsSvc.StreakSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rest, err := s.statsSvc.RestDays(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.repo.ActiveDays(ctx, userID, loc)
+	if err != nil {
+		return nil, err
+	}
+	rules := stats.StreakRules{RestWeekends: set.RestWeekends, MinLength: set.MinLength, RestDays: make(map[string]bool, len(rest))}
+	for _, d := range rest {
+		rules.RestDays[d.Date] = true
+	}
+	st := stats.ComputeStreaks(days, time.Now().In(loc), rules)
+	return &st, nil
+}
+
+func (s *service) cached(ctx context.Context, key string, v interface{}) bool {
+	if s.cache == nil {
+		return false
+	}
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal([]byte(data), v) == nil
+}
+
+// store кэширует ответ; ошибка кэша запрос не проваливает.
+func (s *service) store(ctx context.Context, key string, v interface{}) {
+	if s.cache == nil {
+		return
+	}
+	if data, err := json.Marshal(v); err == nil {
+		s.cache.Set(ctx, key, data, CacheTTL)
+	}
+}
+
+// cacheKey включает время изменения настроек: закрытый или урезанный профиль не отдаётся из кэша.
+func cacheKey(userID uuid.UUID, vis *models.ProfileVisibility, parts ...string) string {
+	var version int64
+	if vis.UpdatedAt != nil {
+		version = vis.UpdatedAt.UnixNano()
+	}
+	return fmt.Sprintf("public:%s:%d:%s", userID, version, strings.Join(parts, ":"))
+}
+
+// repoFilterKey — фильтр без указателей, чтобы ключ кэша не зависел от адресов.
+func repoFilterKey(f stats.RepoFilter) interface{} {
+	deref := func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		return fmt.Sprint(*b)
+	}
+	return struct {
+		Fork, Archived, Template              string
+		Topic, Language, License, Sort, Order string
+		Limit, Offset                         int
+	}{deref(f.Fork), deref(f.Archived), deref(f.Template), f.Topic, f.Language, f.License, f.Sort, f.Order, f.Limit, f.Offset}
+}
+
+func contributionDays(days []Day) []models.ContributionDay {
+	out := make([]models.ContributionDay, 0, len(days))
+	for _, d := range days {
+		out = append(out, models.ContributionDay{Date: d.Date, Count: d.Contributions})
+	}
+	return out
+}
+
+func contains(list []string, v string) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
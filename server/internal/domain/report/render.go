@@ -0,0 +1,238 @@
+package report
+
+import (
+	"fmt"
+	"github.com/devsync/server/internal/domain/goals"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/pkg/pdf"
+)
+
+const (
+	FormatPDF      = "pdf"
+	FormatMarkdown = "markdown"
+)
+
+// Document — готовый отчёт для скачивания или вложения в письмо.
+type Document struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+func ToReportData(username, period string, s *models.UserStats) *pdf.ReportData {
+	rd := &pdf.ReportData{
+		Username:        username,
+		Period:          period,
+		TotalRepos:      s.TotalRepos,
+		TotalStars:      s.TotalStars,
+		TotalForks:      s.TotalForks,
+		ContributionSum: s.ContributionSum,
+		CurrentStreak:   pdf.StreakSummary{Length: s.Streaks.Current.Length, Start: s.Streaks.Current.Start, End: s.Streaks.Current.End},
+		LongestStreak:   pdf.StreakSummary{Length: s.Streaks.Longest.Length, Start: s.Streaks.Longest.Start, End: s.Streaks.Longest.End},
+	}
+	for _, t := range trends(s.Comparison) {
+		rd.Trends = append(rd.Trends, pdf.TrendSummary{Label: t.label, Current: t.current, Previous: t.previous, Percent: t.delta.Percent})
+	}
+	for _, g := range s.Goals {
+		rd.Goals = append(rd.Goals, pdf.GoalSummary{Label: goalLabel(g), Value: g.Value, Target: g.Target, Met: g.Met})
+	}
+	for _, r := range s.TopRepos {
+		rd.TopRepos = append(rd.TopRepos, pdf.RepoSummary{Name: r.Name, Stars: r.Stars, Forks: r.Forks, Language: r.Language})
+	}
+	for _, l := range s.Languages {
+		rd.Languages = append(rd.Languages, pdf.LangSummary{Language: l.Language, Percent: l.Percent})
+	}
+	for _, t := range s.TechStack.Frameworks {
+		rd.Frameworks = append(rd.Frameworks, pdf.TechSummary{Name: t.Name, Ecosystem: t.Ecosystem, Repos: t.Repos})
+	}
+	for _, t := range s.TechStack.Libraries {
+		rd.Libraries = append(rd.Libraries, pdf.TechSummary{Name: t.Name, Ecosystem: t.Ecosystem, Repos: t.Repos})
+	}
+	return rd
+}
+
+func Markdown(username, period string, s *models.UserStats) string {
+	md := fmt.Sprintf("# GitHub Stats — %s\n\n", username)
+	md += fmt.Sprintf("- **Repositories:** %d\n", s.TotalRepos)
+	md += fmt.Sprintf("- **Stars:** %d\n", s.TotalStars)
+	md += fmt.Sprintf("- **Forks:** %d\n", s.TotalForks)
+	md += fmt.Sprintf("- **Contributions (%s):** %d\n\n", period, s.ContributionSum)
+	md += "## Top Repositories\n\n"
+	for _, r := range s.TopRepos {
+		md += fmt.Sprintf("- [%s](https://github.com/%s) — ⭐ %d | 🍴 %d | %s\n", r.Name, r.FullName, r.Stars, r.Forks, r.Language)
+	}
+	md += "\n## Languages\n\n"
+	for _, l := range s.Languages {
+		md += fmt.Sprintf("- %s: %.1f%%\n", l.Language, l.Percent)
+	}
+	if len(s.TechStack.Frameworks) > 0 || len(s.TechStack.Libraries) > 0 {
+		md += "\n## Tech Stack\n\n"
+		for _, t := range s.TechStack.Frameworks {
+			md += fmt.Sprintf("- **%s** (%s) — %d repos\n", t.Name, t.Ecosystem, t.Repos)
+		}
+		if len(s.TechStack.Libraries) > 0 {
+			md += "\n**Libraries:** "
+			for i, t := range s.TechStack.Libraries {
+				if i > 0 {
+					md += ", "
+				}
+				md += fmt.Sprintf("`%s` (%d)", t.Name, t.Repos)
+			}
+			md += "\n"
+		}
+	}
+	if s.Comparison != nil {
+		md += fmt.Sprintf("\n## Trend vs previous %s\n\n", period)
+		for _, t := range trends(s.Comparison) {
+			md += fmt.Sprintf("- **%s:** %d %s\n", t.label, t.current, arrow(t.delta))
+		}
+		if len(s.Comparison.Deltas.Languages) > 0 {
+			md += "\n**Languages:** "
+			for i, l := range s.Comparison.Deltas.Languages {
+				if i == trendLanguages {
+					break
+				}
+				if i > 0 {
+					md += ", "
+				}
+				md += fmt.Sprintf("%s %.1f%% (%+.1f pp)", l.Language, l.Current, l.Delta)
+			}
+			md += "\n"
+		}
+	}
+	if len(s.Goals) > 0 {
+		md += "\n## Goals\n\n"
+		for _, g := range s.Goals {
+			mark := "⏳"
+			if g.Met {
+				mark = "✅"
+			}
+			md += fmt.Sprintf("- %s **%s:** %d/%d (%.0f%%, %s — %s)\n", mark, goalLabel(g), g.Value, g.Target, g.Percent, g.PeriodStart, g.PeriodEnd)
+		}
+	}
+	if len(s.Badges) > 0 {
+		md += "\n## Badges\n\n"
+		for _, b := range s.Badges {
+			md += fmt.Sprintf("- %s **%s** — %s (%s)\n", b.Badge, b.Title, b.Description, b.UnlockedAt.Format("2006-01-02"))
+		}
+	}
+	if s.Streaks.Longest.Length > 0 {
+		md += "\n## Streaks\n\n"
+		md += fmt.Sprintf("- **Current:** %d days\n", s.Streaks.Current.Length)
+		md += fmt.Sprintf("- **Longest:** %d days (%s — %s)\n", s.Streaks.Longest.Length, s.Streaks.Longest.Start, s.Streaks.Longest.End)
+		if len(s.Streaks.History) > 1 {
+			md += "\n**Recent streaks:**\n\n"
+			for i, st := range s.Streaks.History {
+				if i == recentStreaks {
+					break
+				}
+				md += fmt.Sprintf("- %s — %s: %d days\n", st.Start, st.End, st.Length)
+			}
+		}
+	}
+	return md
+}
+
+// trendLanguages — сколько языков показывать в тренде Markdown-отчёта.
+const trendLanguages = 5
+
+type trend struct {
+	label             string
+	current, previous int
+	delta             models.Delta
+}
+
+// trends — показатели сравнения в порядке вывода в отчётах.
+func trends(c *models.PeriodComparison) []trend {
+	if c == nil {
+		return nil
+	}
+	cur, prev, d := c.Current.Totals, c.Previous.Totals, c.Deltas
+	return []trend{
+		{"Contributions", cur.Contributions, prev.Contributions, d.Contributions},
+		{"Commits", cur.Commits, prev.Commits, d.Commits},
+		{"Pull requests", cur.PRs, prev.PRs, d.PRs},
+		{"Issues", cur.Issues, prev.Issues, d.Issues},
+		{"Stars gained", cur.StarsReceived, prev.StarsReceived, d.StarsReceived},
+		{"Active days", cur.ActiveDays, prev.ActiveDays, d.ActiveDays},
+	}
+}
+
+// arrow — «▲ +20 (+25.0%)»; без процента, если в прошлом периоде был 0.
+func arrow(d models.Delta) string {
+	sign := "→"
+	switch {
+	case d.Abs > 0:
+		sign = "▲"
+	case d.Abs < 0:
+		sign = "▼"
+	}
+	if d.Percent == nil {
+		return fmt.Sprintf("%s %+d", sign, d.Abs)
+	}
+	return fmt.Sprintf("%s %+d (%+.1f%%)", sign, d.Abs, *d.Percent)
+}
+
+var goalMetricLabels = map[string]string{
+	goals.MetricCommits:       "commits",
+	goals.MetricContributions: "contributions",
+	goals.MetricPRsOpened:     "PRs opened",
+	goals.MetricPRsMerged:     "PRs merged",
+	goals.MetricIssues:        "issues opened",
+	goals.MetricActiveDays:    "active days",
+}
+
+// goalLabel — «20 commits per week».
+func goalLabel(g models.GoalProgress) string {
+	metric, ok := goalMetricLabels[g.Metric]
+	if !ok {
+		metric = g.Metric
+	}
+	return fmt.Sprintf("%d %s per %s", g.Target, metric, g.Period)
+}
+
+// recentStreaks — сколько последних серий попадает в Markdown-отчёт.
+const recentStreaks = 5
+
+// ToTeamReportData — отчёт команды: разделы как у пользователя плюс вклад участников.
+func ToTeamReportData(period string, ts *models.TeamStats) *pdf.ReportData {
+	rd := ToReportData(ts.Team.Name, period, &ts.UserStats)
+	for _, m := range ts.Members {
+		rd.Members = append(rd.Members, pdf.MemberSummary{Username: m.Username, Role: m.Role,
+			Contributions: m.Totals.Contributions, Commits: m.Totals.Commits, PRs: m.Totals.PRs, Share: m.Share})
+	}
+	return rd
+}
+
+func TeamMarkdown(period string, ts *models.TeamStats) string {
+	md := Markdown(ts.Team.Name, period, &ts.UserStats)
+	if len(ts.Members) > 0 {
+		md += "\n## Members\n\n"
+		md += "| Member | Role | Contributions | Commits | PRs | Share |\n"
+		md += "|---|---|---:|---:|---:|---:|\n"
+		for _, m := range ts.Members {
+			md += fmt.Sprintf("| [%s](https://github.com/%s) | %s | %d | %d | %d | %.1f%% |\n",
+				m.Username, m.Username, m.Role, m.Totals.Contributions, m.Totals.Commits, m.Totals.PRs, m.Share)
+		}
+	}
+	return md
+}
+
+func ToComparisonData(cmp *models.Comparison) *pdf.ComparisonData {
+	cd := &pdf.ComparisonData{Period: cmp.Period.Label(), Languages: cmp.Languages, Buckets: cmp.Buckets}
+	for _, u := range cmp.Users {
+		us := pdf.ComparedSummary{Username: u.Username, Access: u.Access, Languages: u.Languages}
+		if t := u.Totals; t != nil {
+			us.Contributions, us.Commits, us.PRs = &t.Contributions, &t.Commits, &t.PRs
+			us.Issues, us.Reviews, us.ActiveDays = &t.Issues, &t.Reviews, &t.ActiveDays
+		}
+		if st := u.Streaks; st != nil {
+			us.CurrentStreak, us.LongestStreak = &st.Current, &st.Longest
+		}
+		if u.Series != nil {
+			us.Series = u.Series.Normalized
+		}
+		cd.Users = append(cd.Users, us)
+	}
+	return cd
+}
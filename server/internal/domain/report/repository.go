@@ -0,0 +1,241 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	DeliveryPending = "pending"
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+)
+
+type Subscription struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"-"`
+	Format     string     `json:"format"`  // pdf, markdown
+	Period     string     `json:"period"`  // week, month, year — за какой срок статистика
+	Cadence    string     `json:"cadence"` // daily, weekly, monthly — как часто отправлять
+	Recipients []string   `json:"recipients"`
+	Active     bool       `json:"active"`
+	NextRunAt  *time.Time `json:"next_run_at,omitempty"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type Delivery struct {
+	ID             uuid.UUID  `json:"id"`
+	SubscriptionID uuid.UUID  `json:"subscription_id"`
+	UserID         uuid.UUID  `json:"-"`
+	Status         string     `json:"status"`
+	Recipients     []string   `json:"recipients"`
+	Attempts       int        `json:"attempts"`
+	Error          *string    `json:"error,omitempty"`
+	JobID          *uuid.UUID `json:"job_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+}
+
+// DueSubscription — подписка, которую пора отправить, с часовым поясом владельца.
+type DueSubscription struct {
+	Subscription
+	Timezone string
+}
+
+var ErrNotFound = errors.New("subscription not found")
+
+type Repository interface {
+	List(ctx context.Context, userID uuid.UUID) ([]Subscription, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*Subscription, error)
+	Create(ctx context.Context, s *Subscription) error
+	Update(ctx context.Context, s *Subscription) error
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	Due(ctx context.Context, limit int) ([]DueSubscription, error)
+	SetNextRun(ctx context.Context, id uuid.UUID, next time.Time) error
+
+	CreateDelivery(ctx context.Context, d *Delivery) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (*Delivery, error)
+	SetDeliveryJob(ctx context.Context, id, jobID uuid.UUID) error
+	// FinishDelivery записывает попытку: sent, failed (окончательно) или pending с ошибкой (будет повтор).
+	FinishDelivery(ctx context.Context, id uuid.UUID, status string, cause error) error
+	ListDeliveries(ctx context.Context, userID, subscriptionID uuid.UUID, limit int) ([]Delivery, error)
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+const subscriptionColumns = `id, user_id, format, period, cadence, recipients, active, next_run_at, last_sent_at, created_at`
+
+func scanSubscription(row pgx.Row, s *Subscription, extra ...interface{}) error {
+	return row.Scan(append([]interface{}{&s.ID, &s.UserID, &s.Format, &s.Period, &s.Cadence, &s.Recipients,
+		&s.Active, &s.NextRunAt, &s.LastSentAt, &s.CreatedAt}, extra...)...)
+}
+
+func (r *repo) List(ctx context.Context, userID uuid.UUID) ([]Subscription, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+subscriptionColumns+` FROM report_subscriptions
+		WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Subscription{}
+	for rows.Next() {
+		var s Subscription
+		if err := scanSubscription(rows, &s); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) Get(ctx context.Context, userID, id uuid.UUID) (*Subscription, error) {
+	var s Subscription
+	err := scanSubscription(r.pool.QueryRow(ctx, `SELECT `+subscriptionColumns+` FROM report_subscriptions
+		WHERE id = $1 AND user_id = $2`, id, userID), &s)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *repo) Create(ctx context.Context, s *Subscription) error {
+	return r.pool.QueryRow(ctx, `INSERT INTO report_subscriptions (user_id, format, period, cadence, recipients, active, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		s.UserID, s.Format, s.Period, s.Cadence, s.Recipients, s.Active, s.NextRunAt,
+	).Scan(&s.ID, &s.CreatedAt)
+}
+
+func (r *repo) Update(ctx context.Context, s *Subscription) error {
+	tag, err := r.pool.Exec(ctx, `UPDATE report_subscriptions SET format = $3, period = $4, cadence = $5,
+			recipients = $6, active = $7, next_run_at = $8, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2`,
+		s.ID, s.UserID, s.Format, s.Period, s.Cadence, s.Recipients, s.Active, s.NextRunAt)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *repo) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM report_subscriptions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err == nil && tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (r *repo) Due(ctx context.Context, limit int) ([]DueSubscription, error) {
+	rows, err := r.pool.Query(ctx, `SELECT s.id, s.user_id, s.format, s.period, s.cadence, s.recipients, s.active,
+			s.next_run_at, s.last_sent_at, s.created_at, u.timezone
+		FROM report_subscriptions s JOIN users u ON u.id = s.user_id
+		WHERE s.active AND s.next_run_at <= NOW()
+		ORDER BY s.next_run_at LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []DueSubscription
+	for rows.Next() {
+		var d DueSubscription
+		if err := scanSubscription(rows, &d.Subscription, &d.Timezone); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) SetNextRun(ctx context.Context, id uuid.UUID, next time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE report_subscriptions SET next_run_at = $2, updated_at = NOW() WHERE id = $1`, id, next)
+	return err
+}
+
+func (r *repo) CreateDelivery(ctx context.Context, d *Delivery) error {
+	d.Status = DeliveryPending
+	return r.pool.QueryRow(ctx, `INSERT INTO report_deliveries (subscription_id, user_id, status, recipients)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		d.SubscriptionID, d.UserID, d.Status, d.Recipients,
+	).Scan(&d.ID, &d.CreatedAt)
+}
+
+const deliveryColumns = `id, subscription_id, user_id, status, recipients, attempts, error, job_id, created_at, sent_at`
+
+func scanDelivery(row pgx.Row, d *Delivery) error {
+	return row.Scan(&d.ID, &d.SubscriptionID, &d.UserID, &d.Status, &d.Recipients, &d.Attempts, &d.Error,
+		&d.JobID, &d.CreatedAt, &d.SentAt)
+}
+
+func (r *repo) GetDelivery(ctx context.Context, id uuid.UUID) (*Delivery, error) {
+	var d Delivery
+	err := scanDelivery(r.pool.QueryRow(ctx, `SELECT `+deliveryColumns+` FROM report_deliveries WHERE id = $1`, id), &d)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *repo) SetDeliveryJob(ctx context.Context, id, jobID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `UPDATE report_deliveries SET job_id = $2 WHERE id = $1`, id, jobID)
+	return err
+}
+
+func (r *repo) FinishDelivery(ctx context.Context, id uuid.UUID, status string, cause error) error {
+	var msg *string
+	if cause != nil {
+		m := cause.Error()
+		msg = &m
+	}
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	var subID uuid.UUID
+	err = tx.QueryRow(ctx, `UPDATE report_deliveries SET status = $2, error = $3, attempts = attempts + 1,
+			sent_at = CASE WHEN $2 = 'sent' THEN NOW() END
+		WHERE id = $1 RETURNING subscription_id`, id, status, msg).Scan(&subID)
+	if err != nil {
+		return err
+	}
+	if status == DeliverySent {
+		if _, err := tx.Exec(ctx, `UPDATE report_subscriptions SET last_sent_at = NOW() WHERE id = $1`, subID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *repo) ListDeliveries(ctx context.Context, userID, subscriptionID uuid.UUID, limit int) ([]Delivery, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+deliveryColumns+` FROM report_deliveries
+		WHERE user_id = $1 AND subscription_id = $2 ORDER BY created_at DESC LIMIT $3`, userID, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Delivery{}
+	for rows.Next() {
+		var d Delivery
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
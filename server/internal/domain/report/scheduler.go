@@ -0,0 +1,62 @@
+package report
+
+import (
+	"context"
+	"log"
+	"time"
+	"github.com/devsync/server/internal/infrastructure/queue"
+)
+
+const schedulerBatch = 200
+
+// Scheduler раз в tick ставит в очередь подписки, которым пора уйти на почту.
+type Scheduler struct {
+	repo Repository
+	svc  Service
+}
+
+func NewScheduler(repo Repository, svc Service) *Scheduler {
+	return &Scheduler{repo: repo, svc: svc}
+}
+
+func (s *Scheduler) Run(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		if n, err := s.EnqueueDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("report scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("report scheduler: queued %d reports", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) EnqueueDue(ctx context.Context) (int, error) {
+	due, err := s.repo.Due(ctx, schedulerBatch)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	queued := 0
+	for i := range due {
+		d := &due[i]
+		loc := time.UTC
+		if l, err := time.LoadLocation(d.Timezone); err == nil {
+			loc = l
+		}
+		// Сначала сдвигаем время: при сбое лучше пропустить отчёт, чем отправить его дважды
+		if err := s.repo.SetNextRun(ctx, d.ID, nextRun(d.Cadence, now, loc)); err != nil {
+			return queued, err
+		}
+		if _, err := s.svc.Enqueue(ctx, &d.Subscription, queue.PriorityLow); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
@@ -0,0 +1,388 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/schedule"
+	"github.com/devsync/server/internal/domain/stats"
+	"github.com/devsync/server/internal/domain/user"
+	"github.com/devsync/server/internal/infrastructure/queue"
+	mailer "github.com/devsync/server/internal/infrastructure/mail"
+	"github.com/devsync/server/pkg/pdf"
+)
+
+const (
+	CadenceDaily   = "daily"
+	CadenceWeekly  = "weekly"
+	CadenceMonthly = "monthly"
+)
+
+// Отчёты уходят в 9:00 по часовому поясу пользователя: ежедневно, по понедельникам, первого числа.
+var cadenceCron = map[string]string{
+	CadenceDaily:   "0 9 * * *",
+	CadenceWeekly:  "0 9 * * 1",
+	CadenceMonthly: "0 9 1 * *",
+}
+
+const (
+	MaxRecipients     = 10
+	MaxSubscriptions  = 20
+	deliveriesHistory = 50
+)
+
+var (
+	ErrInvalidSubscription  = errors.New("invalid subscription")
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
+)
+
+type StatsProvider interface {
+	GetUserStatsWithPeriod(ctx context.Context, userID uuid.UUID, q stats.PeriodQuery) (*models.UserStats, error)
+	ComparePeriods(ctx context.Context, userID uuid.UUID, q stats.PeriodQuery, compare string) (*models.PeriodComparison, error)
+}
+
+// GoalsProvider — текущий прогресс целей для раздела Goals.
+type GoalsProvider interface {
+	Current(ctx context.Context, userID uuid.UUID) ([]models.GoalProgress, error)
+}
+
+// AchievementsProvider — достижения для раздела Badges.
+type AchievementsProvider interface {
+	List(ctx context.Context, userID uuid.UUID) (*models.Achievements, error)
+}
+
+// TeamStatsProvider — статистика команды для её отчётов (team.Service).
+type TeamStatsProvider interface {
+	Stats(ctx context.Context, userID, teamID uuid.UUID, q stats.PeriodQuery) (*models.TeamStats, error)
+}
+
+// ComparisonProvider — сравнение пользователей для PDF сравнения (compare.Service).
+type ComparisonProvider interface {
+	Compare(ctx context.Context, viewerID uuid.UUID, usernames []string, q stats.PeriodQuery) (*models.Comparison, error)
+}
+
+// GeneratePayload — полезная нагрузка задачи generate_report.
+type GeneratePayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+type Service interface {
+	// Build собирает отчёт в нужном формате за период week, month или year.
+	Build(ctx context.Context, userID uuid.UUID, format string, q stats.PeriodQuery) (*Document, error) // stats.ErrInvalidPeriod — неверный период
+	// BuildTeam — отчёт команды для её участника userID (ошибки team.Service).
+	BuildTeam(ctx context.Context, userID, teamID uuid.UUID, format string, q stats.PeriodQuery) (*Document, error)
+	// BuildComparison — PDF сравнения пользователей глазами userID (ошибки compare.Service).
+	BuildComparison(ctx context.Context, userID uuid.UUID, usernames []string, q stats.PeriodQuery) (*Document, error)
+
+	List(ctx context.Context, userID uuid.UUID) ([]Subscription, error)
+	Create(ctx context.Context, userID uuid.UUID, s *Subscription) (*Subscription, error)
+	Update(ctx context.Context, userID uuid.UUID, s *Subscription) (*Subscription, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	Deliveries(ctx context.Context, userID, id uuid.UUID) ([]Delivery, error)
+	// SendNow ставит внеочередную отправку подписки.
+	SendNow(ctx context.Context, userID, id uuid.UUID) (*Delivery, error)
+
+	// Deliver формирует и отправляет письмо по задаче worker; final — последняя попытка.
+	Deliver(ctx context.Context, deliveryID uuid.UUID, final bool) error
+	// Enqueue создаёт запись о доставке и задачу для worker.
+	Enqueue(ctx context.Context, s *Subscription, priority int) (*Delivery, error)
+}
+
+type service struct {
+	repo     Repository
+	userSvc  user.Service
+	statsSvc StatsProvider
+	goalSvc  GoalsProvider
+	achievementSvc AchievementsProvider
+	teamSvc  TeamStatsProvider
+	compareSvc ComparisonProvider
+	pdfGen   *pdf.Generator
+	jobs     queue.Queue
+	mailer   mailer.Mailer
+}
+
+// NewService — jobs и mailer могут быть nil там, где подписки не отправляются (например, в API только скачивание);
+// teamSvc и compareSvc — там, где не строятся отчёты команд и сравнения (worker).
+func NewService(repo Repository, userSvc user.Service, statsSvc StatsProvider, goalSvc GoalsProvider, achievementSvc AchievementsProvider, teamSvc TeamStatsProvider, compareSvc ComparisonProvider, pdfGen *pdf.Generator, jobs queue.Queue, m mailer.Mailer) Service {
+	return &service{repo: repo, userSvc: userSvc, statsSvc: statsSvc, goalSvc: goalSvc, achievementSvc: achievementSvc, teamSvc: teamSvc, compareSvc: compareSvc, pdfGen: pdfGen, jobs: jobs, mailer: m}
+}
+
+func (s *service) Build(ctx context.Context, userID uuid.UUID, format string, q stats.PeriodQuery) (*Document, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.statsSvc.GetUserStatsWithPeriod(ctx, userID, q)
+	if err != nil {
+		return nil, err
+	}
+	// Тренд в отчёте — относительно предыдущего периода; у all его нет
+	if st.Period.Name != stats.PeriodAll {
+		if st.Comparison, err = s.statsSvc.ComparePeriods(ctx, userID, q, stats.ComparePrevious); err != nil {
+			return nil, err
+		}
+	}
+	if st.Goals, err = s.goalSvc.Current(ctx, userID); err != nil {
+		return nil, err
+	}
+	earned, err := s.achievementSvc.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	st.Badges = earned.Earned
+	period := st.Period.Label()
+	switch format {
+	case FormatPDF:
+		data, err := s.pdfGen.Generate(u.Username, ToReportData(u.Username, period, st))
+		if err != nil {
+			return nil, err
+		}
+		return &Document{Filename: "devsync-report.pdf", ContentType: "application/pdf", Data: data}, nil
+	case FormatMarkdown:
+		return &Document{Filename: "README-stats.md", ContentType: "text/markdown; charset=utf-8", Data: []byte(Markdown(u.Username, period, st))}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidSubscription, format)
+}
+
+func (s *service) BuildTeam(ctx context.Context, userID, teamID uuid.UUID, format string, q stats.PeriodQuery) (*Document, error) {
+	ts, err := s.teamSvc.Stats(ctx, userID, teamID, q)
+	if err != nil {
+		return nil, err
+	}
+	period := ts.Period.Label()
+	switch format {
+	case FormatPDF:
+		data, err := s.pdfGen.Generate(ts.Team.Name, ToTeamReportData(period, ts))
+		if err != nil {
+			return nil, err
+		}
+		return &Document{Filename: "devsync-team-report.pdf", ContentType: "application/pdf", Data: data}, nil
+	case FormatMarkdown:
+		return &Document{Filename: "TEAM-stats.md", ContentType: "text/markdown; charset=utf-8", Data: []byte(TeamMarkdown(period, ts))}, nil
+	}
+	return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidSubscription, format)
+}
+
+func (s *service) BuildComparison(ctx context.Context, userID uuid.UUID, usernames []string, q stats.PeriodQuery) (*Document, error) {
+	cmp, err := s.compareSvc.Compare(ctx, userID, usernames, q)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.pdfGen.Generate("", ToComparisonData(cmp))
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Filename: "devsync-comparison.pdf", ContentType: "application/pdf", Data: data}, nil
+}
+
+// validate нормализует подписку и проверяет поля.
+func validate(sub *Subscription) error {
+	if sub.Format != FormatPDF && sub.Format != FormatMarkdown {
+		return fmt.Errorf("%w: format must be pdf or markdown", ErrInvalidSubscription)
+	}
+	// Подписке нужен период, который сдвигается со временем: фиксированный (2026-Q1, from/to) каждый раз дал бы тот же отчёт
+	if !stats.IsRelativePeriod(sub.Period) {
+		return fmt.Errorf("%w: period must be week, month, quarter, year or this_/last_ week, month, quarter, year", ErrInvalidSubscription)
+	}
+	if _, ok := cadenceCron[sub.Cadence]; !ok {
+		return fmt.Errorf("%w: cadence must be daily, weekly or monthly", ErrInvalidSubscription)
+	}
+	if len(sub.Recipients) == 0 || len(sub.Recipients) > MaxRecipients {
+		return fmt.Errorf("%w: between 1 and %d recipients required", ErrInvalidSubscription, MaxRecipients)
+	}
+	seen := make(map[string]bool, len(sub.Recipients))
+	recipients := make([]string, 0, len(sub.Recipients))
+	for _, r := range sub.Recipients {
+		addr, err := mail.ParseAddress(strings.TrimSpace(r))
+		if err != nil {
+			return fmt.Errorf("%w: invalid recipient %q", ErrInvalidSubscription, r)
+		}
+		key := strings.ToLower(addr.Address)
+		if !seen[key] {
+			seen[key] = true
+			recipients = append(recipients, addr.Address)
+		}
+	}
+	sub.Recipients = recipients
+	return nil
+}
+
+// nextRun — следующее время отправки после after в часовом поясе loc.
+func nextRun(cadence string, after time.Time, loc *time.Location) time.Time {
+	c, err := schedule.ParseCron(cadenceCron[cadence])
+	if err != nil {
+		// Выражения фиксированные, сюда не попадаем
+		panic(err)
+	}
+	return c.Next(after.In(loc))
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]Subscription, error) {
+	return s.repo.List(ctx, userID)
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, sub *Subscription) (*Subscription, error) {
+	if err := validate(sub); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxSubscriptions {
+		return nil, ErrTooManySubscriptions
+	}
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sub.UserID = userID
+	sub.NextRunAt = nil
+	if sub.Active {
+		next := nextRun(sub.Cadence, time.Now(), u.Location())
+		sub.NextRunAt = &next
+	}
+	if err := s.repo.Create(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *service) Update(ctx context.Context, userID uuid.UUID, sub *Subscription) (*Subscription, error) {
+	if err := validate(sub); err != nil {
+		return nil, err
+	}
+	cur, err := s.repo.Get(ctx, userID, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sub.UserID = userID
+	sub.NextRunAt = cur.NextRunAt
+	if !sub.Active {
+		sub.NextRunAt = nil
+	} else if !cur.Active || cur.Cadence != sub.Cadence || sub.NextRunAt == nil {
+		next := nextRun(sub.Cadence, time.Now(), u.Location())
+		sub.NextRunAt = &next
+	}
+	if err := s.repo.Update(ctx, sub); err != nil {
+		return nil, err
+	}
+	sub.LastSentAt = cur.LastSentAt
+	sub.CreatedAt = cur.CreatedAt
+	return sub, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+func (s *service) Deliveries(ctx context.Context, userID, id uuid.UUID) ([]Delivery, error) {
+	if _, err := s.repo.Get(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, userID, id, deliveriesHistory)
+}
+
+func (s *service) SendNow(ctx context.Context, userID, id uuid.UUID) (*Delivery, error) {
+	sub, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Enqueue(ctx, sub, queue.PriorityHigh)
+}
+
+func (s *service) Enqueue(ctx context.Context, sub *Subscription, priority int) (*Delivery, error) {
+	d := &Delivery{SubscriptionID: sub.ID, UserID: sub.UserID, Recipients: sub.Recipients}
+	if err := s.repo.CreateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	userID := sub.UserID
+	jobID, err := s.jobs.Enqueue(ctx, queue.EnqueueParams{
+		Type:     queue.TypeGenerateReport,
+		UserID:   &userID,
+		Payload:  GeneratePayload{DeliveryID: d.ID},
+		Priority: priority,
+	})
+	if err != nil {
+		s.repo.FinishDelivery(ctx, d.ID, DeliveryFailed, err)
+		return nil, err
+	}
+	if err := s.repo.SetDeliveryJob(ctx, d.ID, jobID); err != nil {
+		return nil, err
+	}
+	d.JobID = &jobID
+	return d, nil
+}
+
+func (s *service) Deliver(ctx context.Context, deliveryID uuid.UUID, final bool) error {
+	d, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+	if d.Status != DeliveryPending {
+		return nil
+	}
+	sub, err := s.repo.Get(ctx, d.UserID, d.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	err = s.send(ctx, sub, d)
+	if ctx.Err() != nil {
+		// Worker останавливается — задача вернётся в очередь, попытку не считаем
+		return err
+	}
+	status := DeliverySent
+	if err != nil {
+		status = DeliveryPending
+		if final || errors.Is(err, mailer.ErrNotConfigured) {
+			status = DeliveryFailed
+		}
+	}
+	if ferr := s.repo.FinishDelivery(context.Background(), d.ID, status, err); ferr != nil && err == nil {
+		return ferr
+	}
+	if errors.Is(err, mailer.ErrNotConfigured) {
+		return queue.Permanent(err)
+	}
+	return err
+}
+
+func (s *service) send(ctx context.Context, sub *Subscription, d *Delivery) error {
+	u, err := s.userSvc.GetByID(ctx, sub.UserID)
+	if err != nil {
+		return err
+	}
+	doc, err := s.Build(ctx, sub.UserID, sub.Format, stats.PeriodQuery{Period: sub.Period})
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      d.Recipients,
+		Subject: fmt.Sprintf("DevSync %s report — %s", sub.Period, u.Username),
+		Text: fmt.Sprintf("Hi!\n\nAttached is the %s GitHub activity report for %s (%s).\n\n"+
+			"You receive it because of a %s subscription in DevSync.\n", sub.Period, u.Username, time.Now().In(u.Location()).Format("2006-01-02"), sub.Cadence),
+		Attachments: []mailer.Attachment{{Filename: doc.Filename, ContentType: doc.ContentType, Data: doc.Data}},
+	})
+}
+
+// PayloadDelivery достаёт id доставки из задачи generate_report.
+func PayloadDelivery(job *queue.Job) (uuid.UUID, error) {
+	var p GeneratePayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return uuid.Nil, err
+	}
+	if p.DeliveryID == uuid.Nil {
+		return uuid.Nil, errors.New("generate_report job without delivery_id")
+	}
+	return p.DeliveryID, nil
+}
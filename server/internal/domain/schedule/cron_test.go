@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseCron(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/15 * * * *",
+		"0 9 * * 1-5",
+		"0,30 8-18/2 1,15 * *",
+		"5/20 * * * 7",
+		"0 0 29 2 *",
+	}
+	for _, expr := range valid {
+		if _, err := ParseCron(expr); err != nil {
+			t.Errorf("ParseCron(%q): %v", expr, err)
+		}
+	}
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+	}
+	for _, expr := range invalid {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// среда, 14 октября 2026
+	at := func(loc *time.Location, month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, loc)
+	}
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"*/15 * * * *", at(time.UTC, 10, 14, 10, 7), at(time.UTC, 10, 14, 10, 15)},
+		// строго после after
+		{"*/15 * * * *", at(time.UTC, 10, 14, 10, 15), at(time.UTC, 10, 14, 10, 30)},
+		{"0 9 * * 1-5", at(time.UTC, 10, 16, 10, 0), at(time.UTC, 10, 19, 9, 0)},
+		// 7 — тоже воскресенье
+		{"0 12 * * 7", at(time.UTC, 10, 14, 0, 0), at(time.UTC, 10, 18, 12, 0)},
+		// день месяца и день недели заданы оба — достаточно любого
+		{"0 0 20 * 1", at(time.UTC, 10, 14, 0, 0), at(time.UTC, 10, 19, 0, 0)},
+		{"0 0 1 * *", at(time.UTC, 10, 14, 0, 0), at(time.UTC, 11, 1, 0, 0)},
+		// в поясе after: 9:00 по Москве уже прошло
+		{"0 9 * * *", at(moscow, 10, 14, 10, 0), at(moscow, 10, 15, 9, 0)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := c.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("Next(%q, %s) = %s, want %s", tt.expr, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextNever(t *testing.T) {
+	c, err := ParseCron("0 0 31 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next for 31 February = %s, want zero time", got)
+	}
+}
+
+func TestCronNextAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ParseCron("30 2 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 8 марта 2026 в 2:00 часы переводят на 3:00 — 2:30 в этот день нет, следующий запуск 9 марта
+	got := c.Next(time.Date(2026, 3, 8, 0, 0, 0, 0, ny))
+	if want := time.Date(2026, 3, 9, 2, 30, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("Next across spring forward = %s, want %s", got, want)
+	}
+}
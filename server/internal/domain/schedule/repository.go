@@ -0,0 +1,101 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	// Get — расписание пользователя; adaptive по умолчанию, если не настроено.
+	Get(ctx context.Context, userID uuid.UUID) (*Schedule, error)
+	Save(ctx context.Context, s *Schedule) error
+	Activity(ctx context.Context, userID uuid.UUID) (Activity, error)
+	// Due — пользователи, которым пора в очередь или чьё adaptive-расписание устарело после входа.
+	Due(ctx context.Context, limit int) ([]DueUser, error)
+	SetNextRun(ctx context.Context, userID uuid.UUID, next time.Time, enqueued bool) error
+}
+
+type DueUser struct {
+	Schedule
+	Activity
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+func (r *repo) Get(ctx context.Context, userID uuid.UUID) (*Schedule, error) {
+	s := &Schedule{UserID: userID, Mode: ModeAdaptive}
+	err := r.pool.QueryRow(ctx, `SELECT mode, interval_minutes, cron_expr, next_run_at, last_enqueued_at
+		FROM sync_schedules WHERE user_id = $1`, userID,
+	).Scan(&s.Mode, &s.IntervalMinutes, &s.Cron, &s.NextRunAt, &s.LastEnqueuedAt)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save сбрасывает next_run_at — планировщик пересчитает его по новым правилам.
+func (r *repo) Save(ctx context.Context, s *Schedule) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO sync_schedules (user_id, mode, interval_minutes, cron_expr, next_run_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULL, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET mode = EXCLUDED.mode, interval_minutes = EXCLUDED.interval_minutes,
+			cron_expr = EXCLUDED.cron_expr, next_run_at = NULL, updated_at = NOW()`,
+		s.UserID, s.Mode, s.IntervalMinutes, s.Cron)
+	s.NextRunAt = nil
+	return err
+}
+
+func (r *repo) Activity(ctx context.Context, userID uuid.UUID) (Activity, error) {
+	var a Activity
+	err := r.pool.QueryRow(ctx, `SELECT u.last_login_at, u.last_synced_at::timestamptz,
+			(SELECT MAX(occurred_at) FROM activity_events e WHERE e.user_id = u.id)
+		FROM users u WHERE u.id = $1`, userID,
+	).Scan(&a.LastLoginAt, &a.LastSyncedAt, &a.LastEventAt)
+	return a, err
+}
+
+func (r *repo) Due(ctx context.Context, limit int) ([]DueUser, error) {
+	rows, err := r.pool.Query(ctx, `SELECT u.id, COALESCE(s.mode, 'adaptive'), s.interval_minutes, s.cron_expr,
+			u.timezone, s.next_run_at, s.last_enqueued_at,
+			u.last_login_at, u.last_synced_at::timestamptz,
+			(SELECT MAX(occurred_at) FROM activity_events e WHERE e.user_id = u.id)
+		FROM users u
+		LEFT JOIN sync_schedules s ON s.user_id = u.id
+		WHERE u.access_token IS NOT NULL AND u.access_token <> '' AND NOT u.reauth_required
+			AND (s.next_run_at IS NULL OR s.next_run_at <= NOW()
+				OR (s.mode = 'adaptive' AND u.last_login_at > s.updated_at))
+		ORDER BY s.next_run_at NULLS FIRST
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []DueUser
+	for rows.Next() {
+		var d DueUser
+		if err := rows.Scan(&d.UserID, &d.Mode, &d.IntervalMinutes, &d.Cron, &d.Timezone, &d.NextRunAt, &d.LastEnqueuedAt,
+			&d.LastLoginAt, &d.LastSyncedAt, &d.LastEventAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) SetNextRun(ctx context.Context, userID uuid.UUID, next time.Time, enqueued bool) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO sync_schedules (user_id, next_run_at, last_enqueued_at, updated_at)
+		VALUES ($1, $2, CASE WHEN $3::boolean THEN NOW() END, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET next_run_at = EXCLUDED.next_run_at,
+			last_enqueued_at = COALESCE(EXCLUDED.last_enqueued_at, sync_schedules.last_enqueued_at), updated_at = NOW()`,
+		userID, next, enqueued)
+	return err
+}
@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"github.com/google/uuid"
+)
+
+const (
+	ModeAdaptive = "adaptive" // по умолчанию: частота зависит от активности пользователя
+	ModeInterval = "interval"
+	ModeCron     = "cron"
+)
+
+// Не чаще раза в час: синхронизация тратит сотни запросов из лимита токена.
+const (
+	MinInterval = time.Hour
+	MaxInterval = 30 * 24 * time.Hour
+)
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+type Schedule struct {
+	UserID          uuid.UUID  `json:"-"`
+	Mode            string     `json:"mode"`
+	IntervalMinutes *int       `json:"interval_minutes,omitempty"`
+	Cron            *string    `json:"cron,omitempty"`
+	Timezone        string     `json:"timezone"` // пояс пользователя; в нём считается cron
+	NextRunAt       *time.Time `json:"next_run_at,omitempty"`
+	LastEnqueuedAt  *time.Time `json:"last_enqueued_at,omitempty"`
+	// EffectiveIntervalMinutes — текущий интервал adaptive-режима.
+	EffectiveIntervalMinutes int `json:"effective_interval_minutes,omitempty"`
+}
+
+// Activity — признаки активности для adaptive-режима.
+type Activity struct {
+	LastLoginAt  *time.Time
+	LastEventAt  *time.Time // последнее событие GitHub
+	LastSyncedAt *time.Time
+}
+
+func (a Activity) lastActive() *time.Time {
+	var last *time.Time
+	for _, t := range []*time.Time{a.LastLoginAt, a.LastEventAt} {
+		if t != nil && (last == nil || t.After(*last)) {
+			last = t
+		}
+	}
+	return last
+}
+
+// AdaptiveInterval: активные недавно — каждые 2 часа, затихшие — раз в неделю.
+func AdaptiveInterval(a Activity, now time.Time) time.Duration {
+	last := a.lastActive()
+	if last == nil {
+		return 24 * time.Hour
+	}
+	switch idle := now.Sub(*last); {
+	case idle < 2*24*time.Hour:
+		return 2 * time.Hour
+	case idle < 14*24*time.Hour:
+		return 6 * time.Hour
+	case idle < 60*24*time.Hour:
+		return 24 * time.Hour
+	default:
+		return 7 * 24 * time.Hour
+	}
+}
+
+// Validate проверяет режим и его параметры; cron не должен срабатывать чаще MinInterval
+// в поясе loc, где он и будет срабатывать (переходы на летнее время сокращают промежутки).
+func (s *Schedule) Validate(loc *time.Location) error {
+	switch s.Mode {
+	case ModeAdaptive:
+		s.IntervalMinutes, s.Cron = nil, nil
+	case ModeInterval:
+		if s.IntervalMinutes == nil {
+			return fmt.Errorf("%w: interval_minutes is required", ErrInvalidSchedule)
+		}
+		d := time.Duration(*s.IntervalMinutes) * time.Minute
+		if d < MinInterval || d > MaxInterval {
+			return fmt.Errorf("%w: interval_minutes must be between %d and %d", ErrInvalidSchedule,
+				int(MinInterval.Minutes()), int(MaxInterval.Minutes()))
+		}
+		s.Cron = nil
+	case ModeCron:
+		if s.Cron == nil {
+			return fmt.Errorf("%w: cron is required", ErrInvalidSchedule)
+		}
+		c, err := ParseCron(*s.Cron)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+		}
+		// Проверяем промежутки между срабатываниями на год вперёд
+		t := c.Next(time.Now().In(loc))
+		if t.IsZero() {
+			return fmt.Errorf("%w: cron never fires", ErrInvalidSchedule)
+		}
+		for start, i := t, 0; i < 500 && t.Sub(start) < 366*24*time.Hour; i++ {
+			n := c.Next(t)
+			if n.IsZero() {
+				break
+			}
+			if n.Sub(t) < MinInterval {
+				return fmt.Errorf("%w: cron must not fire more often than every %d minutes", ErrInvalidSchedule, int(MinInterval.Minutes()))
+			}
+			t = n
+		}
+		s.IntervalMinutes = nil
+	default:
+		return fmt.Errorf("%w: mode must be adaptive, interval or cron", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// Next — следующий запуск после after. Для interval и adaptive отсчёт идёт от последней синхронизации.
+// Режим без своего параметра (строка, записанная в обход Validate) работает как adaptive.
+func (s *Schedule) Next(after time.Time, loc *time.Location, a Activity) time.Time {
+	switch {
+	case s.Mode == ModeCron && s.Cron != nil:
+		if c, err := ParseCron(*s.Cron); err == nil {
+			if t := c.Next(after.In(loc)); !t.IsZero() {
+				return t
+			}
+		}
+		return after.Add(24 * time.Hour)
+	case s.Mode == ModeInterval && s.IntervalMinutes != nil:
+		return fromLastSync(after, a, time.Duration(*s.IntervalMinutes)*time.Minute)
+	default:
+		return fromLastSync(after, a, AdaptiveInterval(a, after))
+	}
+}
+
+func fromLastSync(after time.Time, a Activity, every time.Duration) time.Time {
+	if a.LastSyncedAt == nil {
+		return after
+	}
+	next := a.LastSyncedAt.Add(every)
+	if next.Before(after) {
+		return after
+	}
+	return next
+}
@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	minutes := func(n int) *int { return &n }
+	cron := func(s string) *string { return &s }
+	tests := []struct {
+		name  string
+		sched Schedule
+		ok    bool
+	}{
+		{"adaptive", Schedule{Mode: ModeAdaptive}, true},
+		{"interval", Schedule{Mode: ModeInterval, IntervalMinutes: minutes(90)}, true},
+		{"interval without minutes", Schedule{Mode: ModeInterval}, false},
+		{"interval too short", Schedule{Mode: ModeInterval, IntervalMinutes: minutes(30)}, false},
+		{"interval too long", Schedule{Mode: ModeInterval, IntervalMinutes: minutes(31 * 24 * 60)}, false},
+		{"cron", Schedule{Mode: ModeCron, Cron: cron("0 */2 * * *")}, true},
+		{"cron without expression", Schedule{Mode: ModeCron}, false},
+		{"cron too frequent", Schedule{Mode: ModeCron, Cron: cron("*/30 * * * *")}, false},
+		{"cron never fires", Schedule{Mode: ModeCron, Cron: cron("0 0 31 2 *")}, false},
+		{"cron malformed", Schedule{Mode: ModeCron, Cron: cron("0 25 * * *")}, false},
+		{"unknown mode", Schedule{Mode: "hourly"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sched.Validate(time.UTC)
+			if tt.ok && err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidSchedule) {
+				t.Errorf("Validate error = %v, want ErrInvalidSchedule", err)
+			}
+		})
+	}
+}
+
+func TestValidateClearsOtherModeFields(t *testing.T) {
+	n, expr := 120, "0 9 * * *"
+	s := Schedule{Mode: ModeAdaptive, IntervalMinutes: &n, Cron: &expr}
+	if err := s.Validate(time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if s.IntervalMinutes != nil || s.Cron != nil {
+		t.Errorf("adaptive schedule kept interval %v and cron %v", s.IntervalMinutes, s.Cron)
+	}
+}
+
+func TestValidateCronInUserTimezone(t *testing.T) {
+	// На острове Лорд-Хау летнее время сдвигает часы на 30 минут: при переходе между 1:30 и 2:30
+	// проходит полчаса (весной 2:00 → 2:30, осенью 1:30 повторяется). В UTC то же выражение — раз в час.
+	lordHowe, err := time.LoadLocation("Australia/Lord_Howe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expr := "30 1,2 * * *"
+	s := Schedule{Mode: ModeCron, Cron: &expr}
+	if err := s.Validate(time.UTC); err != nil {
+		t.Fatalf("Validate in UTC: %v", err)
+	}
+	if err := s.Validate(lordHowe); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("Validate in Australia/Lord_Howe error = %v, want ErrInvalidSchedule", err)
+	}
+}
+
+func TestNextFallsBackToAdaptive(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	synced := now.Add(-time.Hour)
+	login := now.Add(-time.Hour)
+	a := Activity{LastLoginAt: &login, LastSyncedAt: &synced}
+	// активный пользователь: адаптивный режим — раз в 2 часа от последней синхронизации
+	want := synced.Add(2 * time.Hour)
+	// строка в БД без параметров режима не должна ронять планировщик
+	for _, s := range []Schedule{{Mode: ModeCron}, {Mode: ModeInterval}, {Mode: ModeAdaptive}} {
+		if got := s.Next(now, time.UTC, a); !got.Equal(want) {
+			t.Errorf("Next for %s without parameters = %s, want %s", s.Mode, got, want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	synced := now.Add(-time.Hour)
+	a := Activity{LastSyncedAt: &synced}
+	minutes, expr := 180, "0 9 * * *"
+	interval := Schedule{Mode: ModeInterval, IntervalMinutes: &minutes}
+	if got, want := interval.Next(now, time.UTC, a), synced.Add(3*time.Hour); !got.Equal(want) {
+		t.Errorf("interval Next = %s, want %s", got, want)
+	}
+	cron := Schedule{Mode: ModeCron, Cron: &expr}
+	if got, want := cron.Next(now, time.UTC, a), time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("cron Next = %s, want %s", got, want)
+	}
+	// давно не синхронизировался — запускать сразу
+	old := now.Add(-10 * time.Hour)
+	if got := interval.Next(now, time.UTC, Activity{LastSyncedAt: &old}); !got.Equal(now) {
+		t.Errorf("overdue interval Next = %s, want %s", got, now)
+	}
+}
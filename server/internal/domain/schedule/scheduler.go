@@ -0,0 +1,82 @@
+package schedule
+
+import (
+	"context"
+	"log"
+	"time"
+	"github.com/devsync/server/internal/infrastructure/queue"
+)
+
+// Сколько пользователей ставить в очередь за один проход.
+const schedulerBatch = 500
+
+// Scheduler раз в tick ставит в очередь синхронизации, у которых подошло время.
+type Scheduler struct {
+	repo Repository
+	jobs queue.Queue
+}
+
+func NewScheduler(repo Repository, jobs queue.Queue) *Scheduler {
+	return &Scheduler{repo: repo, jobs: jobs}
+}
+
+func (s *Scheduler) Run(ctx context.Context, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		if n, err := s.EnqueueDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("scheduler: queued sync for %d users", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// EnqueueDue ставит в очередь всех, кому пора, и пересчитывает им следующий запуск.
+func (s *Scheduler) EnqueueDue(ctx context.Context) (int, error) {
+	due, err := s.repo.Due(ctx, schedulerBatch)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	queued := 0
+	for i := range due {
+		d := &due[i]
+		loc := time.UTC
+		if l, err := time.LoadLocation(d.Timezone); err == nil {
+			loc = l
+		}
+		wasDue := d.NextRunAt != nil && !d.NextRunAt.After(now)
+		next := d.Schedule.Next(now, loc, d.Activity)
+		if !wasDue && next.After(now) {
+			// Расписание только пересчитано (новый пользователь, смена режима, вход после затишья)
+			if err := s.repo.SetNextRun(ctx, d.UserID, next, false); err != nil {
+				return queued, err
+			}
+			continue
+		}
+		userID := d.UserID
+		_, err := s.jobs.Enqueue(ctx, queue.EnqueueParams{
+			Type:     queue.TypeSyncUser,
+			UserID:   &userID,
+			Priority: queue.PriorityLow,
+			DedupKey: queue.UserKey(queue.TypeSyncUser, userID),
+		})
+		if err != nil {
+			return queued, err
+		}
+		// Следующий запуск считаем так, будто синхронизация прошла сейчас
+		a := d.Activity
+		a.LastSyncedAt = &now
+		if err := s.repo.SetNextRun(ctx, userID, d.Schedule.Next(now, loc, a), true); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
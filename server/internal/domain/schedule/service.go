@@ -0,0 +1,63 @@
+package schedule
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+type Service interface {
+	Get(ctx context.Context, userID uuid.UUID) (*Schedule, error)
+	Update(ctx context.Context, userID uuid.UUID, s *Schedule) (*Schedule, error)
+}
+
+type service struct {
+	repo    Repository
+	userSvc user.Service
+}
+
+func NewService(repo Repository, userSvc user.Service) Service {
+	return &service{repo: repo, userSvc: userSvc}
+}
+
+func (s *service) Get(ctx context.Context, userID uuid.UUID) (*Schedule, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sched, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	a, err := s.repo.Activity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	sched.Timezone = u.Location().String()
+	if sched.NextRunAt == nil {
+		// Ещё не пересчитано планировщиком — показываем расчётное время
+		next := sched.Next(now, u.Location(), a)
+		sched.NextRunAt = &next
+	}
+	if sched.Mode == ModeAdaptive {
+		sched.EffectiveIntervalMinutes = int(AdaptiveInterval(a, now).Minutes())
+	}
+	return sched, nil
+}
+
+func (s *service) Update(ctx context.Context, userID uuid.UUID, sched *Schedule) (*Schedule, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := sched.Validate(u.Location()); err != nil {
+		return nil, err
+	}
+	sched.UserID = userID
+	if err := s.repo.Save(ctx, sched); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID)
+}
@@ -0,0 +1,147 @@
+package signals
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Окна детектора: последняя неделя (7 дней до вчера включительно) сравнивается с предыдущими
+// baselineWeeks неделями. Всплески поздней работы и работы в выходные должны держаться две недели
+// подряд, поэтому для них база сдвинута ещё на неделю назад.
+const (
+	baselineWeeks   = 8
+	historyWeeks    = baselineWeeks + 2
+	minActiveWeeks  = 4   // активных недель в базе, без них сигналы не считаются
+	spikeZ          = 2.0 // порог z для всплеска поздней работы и выходных
+	prVolumeZ       = 2.5 // порог |z| для числа PR
+	highZ           = 3.0 // с этого |z| сигнал — high
+	minSpike        = 3   // всплеск меньше 3 вкладов в неделю не считается
+	minPRChange     = 3   // и изменение числа PR меньше 3
+	minDropBaseline = 5.0 // спад до нуля — только если обычно вкладов хотя бы 5 в неделю
+)
+
+// week — суммы за 7 дней; weeks[0] — последняя неделя.
+type week struct {
+	start, end    time.Time
+	contributions int
+	prs           int
+	lateNight     int
+	weekend       int
+}
+
+// buildWeeks раскладывает дни по неделям от последнего дня назад; days — подряд, по возрастанию.
+func buildWeeks(days []Day) []week {
+	n := len(days) / 7
+	weeks := make([]week, n)
+	for i := 0; i < n; i++ {
+		w := &weeks[i]
+		for j := 0; j < 7; j++ {
+			d := days[len(days)-1-i*7-j]
+			if j == 0 {
+				w.end = d.Date
+			}
+			w.start = d.Date
+			w.contributions += d.Contributions
+			w.prs += d.PRs
+			w.lateNight += d.LateNight
+			if wd := d.Date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+				w.weekend += d.Contributions
+			}
+		}
+	}
+	return weeks
+}
+
+// meanStd — среднее и стандартное отклонение; отклонение не меньше 1, чтобы ровная база не давала бесконечный z.
+func meanStd(values []float64) (float64, float64) {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Max(math.Sqrt(sq/float64(len(values))), 1)
+}
+
+func metric(weeks []week, f func(week) int) []float64 {
+	out := make([]float64, len(weeks))
+	for i, w := range weeks {
+		out[i] = float64(f(w))
+	}
+	return out
+}
+
+// Detect возвращает сигналы по последней неделе. Нужны historyWeeks недель истории.
+func Detect(days []Day) []Signal {
+	weeks := buildWeeks(days)
+	if len(weeks) < historyWeeks {
+		return nil
+	}
+	active := 0
+	for _, w := range weeks[1 : baselineWeeks+1] {
+		if w.contributions > 0 {
+			active++
+		}
+	}
+	if active < minActiveWeeks {
+		return nil
+	}
+	cur := weeks[0]
+	var out []Signal
+	add := func(kind string, value, mean, std float64, explanation string) {
+		z := (value - mean) / std
+		sev := SeverityMedium
+		if math.Abs(z) >= highZ {
+			sev = SeverityHigh
+		}
+		out = append(out, Signal{
+			Kind: kind, Severity: sev,
+			WindowStart: cur.start.Format("2006-01-02"), WindowEnd: cur.end.Format("2006-01-02"),
+			Value: value, Baseline: round2(mean), StdDev: round2(std), ZScore: round2(z),
+			Explanation: explanation,
+		})
+	}
+
+	// Устойчивые всплески: обе последние недели выше базы
+	spikes := []struct {
+		kind, what string
+		f          func(week) int
+	}{
+		{KindLateNight, fmt.Sprintf("Late-night contributions (%02d:00–%02d:00)", LateNightStart, LateNightEnd), func(w week) int { return w.lateNight }},
+		{KindWeekend, "Weekend contributions", func(w week) int { return w.weekend }},
+	}
+	for _, sp := range spikes {
+		vals := metric(weeks, sp.f)
+		mean, std := meanStd(vals[2:historyWeeks])
+		z0, z1 := (vals[0]-mean)/std, (vals[1]-mean)/std
+		if z0 >= spikeZ && z1 >= spikeZ && vals[0] >= minSpike && vals[1] >= minSpike {
+			add(sp.kind, vals[0], mean, std, fmt.Sprintf("%s were %.0f and %.0f in the last two weeks, typically %.1f ± %.1f per week",
+				sp.what, vals[0], vals[1], mean, std))
+		}
+	}
+
+	contribs := metric(weeks, func(w week) int { return w.contributions })
+	if mean, std := meanStd(contribs[1 : baselineWeeks+1]); contribs[0] == 0 && mean >= minDropBaseline {
+		add(KindActivityDrop, 0, mean, std, fmt.Sprintf("No contributions in the last 7 days, typically %.1f ± %.1f per week", mean, std))
+	}
+
+	prs := metric(weeks, func(w week) int { return w.prs })
+	mean, std := meanStd(prs[1 : baselineWeeks+1])
+	if z := (prs[0] - mean) / std; math.Abs(z) >= prVolumeZ && math.Abs(prs[0]-mean) >= minPRChange {
+		direction := "more"
+		if z < 0 {
+			direction = "fewer"
+		}
+		add(KindPRVolume, prs[0], mean, std, fmt.Sprintf("Opened %.0f pull requests in the last 7 days, %s than the typical %.1f ± %.1f per week",
+			prs[0], direction, mean, std))
+	}
+	return out
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,121 @@
+package signals
+
+import (
+	"testing"
+	"time"
+)
+
+// history — n дней подряд, последний — воскресенье 11 октября 2026; fill задаёт день по числу дней до конца (0 — последний).
+func history(n int, fill func(ago int, d *Day)) []Day {
+	last := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+	days := make([]Day, n)
+	for i := range days {
+		ago := n - 1 - i
+		days[i].Date = last.AddDate(0, 0, -ago)
+		if fill != nil {
+			fill(ago, &days[i])
+		}
+	}
+	return days
+}
+
+func weekday(d *Day) bool {
+	wd := d.Date.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// steady — по 2 вклада в будни, всего 10 в неделю.
+func steady(ago int, d *Day) {
+	if weekday(d) {
+		d.Contributions = 2
+	}
+}
+
+func kinds(signals []Signal) []string {
+	out := make([]string, 0, len(signals))
+	for _, s := range signals {
+		out = append(out, s.Kind)
+	}
+	return out
+}
+
+func TestDetect(t *testing.T) {
+	full := historyWeeks * 7
+	tests := []struct {
+		name string
+		days []Day
+		want []string
+	}{
+		{"not enough history", history(full-1, steady), nil},
+		{"too few active weeks", history(full, func(ago int, d *Day) {
+			if ago < 21 {
+				steady(ago, d)
+			}
+		}), nil},
+		{"steady rhythm", history(full, steady), nil},
+		{"activity drop", history(full, func(ago int, d *Day) {
+			if ago >= 7 {
+				steady(ago, d)
+			}
+		}), []string{KindActivityDrop}},
+		{"weekend spike for two weeks", history(full, func(ago int, d *Day) {
+			steady(ago, d)
+			if ago < 14 && !weekday(d) {
+				d.Contributions = 5
+			}
+		}), []string{KindWeekend}},
+		{"late-night spike for two weeks", history(full, func(ago int, d *Day) {
+			steady(ago, d)
+			if ago < 14 && weekday(d) {
+				d.LateNight = 2
+			}
+		}), []string{KindLateNight}},
+		{"late-night spike for one week only", history(full, func(ago int, d *Day) {
+			steady(ago, d)
+			if ago < 7 && weekday(d) {
+				d.LateNight = 2
+			}
+		}), nil},
+		{"pull request surge", history(full, func(ago int, d *Day) {
+			steady(ago, d)
+			if d.Date.Weekday() == time.Monday {
+				d.PRs = 1
+			}
+			if ago < 7 && weekday(d) {
+				d.PRs = 1
+			}
+		}), []string{KindPRVolume}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kinds(Detect(tt.days))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Detect = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Detect = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectSignalDetails(t *testing.T) {
+	signals := Detect(history(historyWeeks*7, func(ago int, d *Day) {
+		if ago >= 7 {
+			steady(ago, d)
+		}
+	}))
+	if len(signals) != 1 {
+		t.Fatalf("Detect = %+v, want one activity drop", signals)
+	}
+	s := signals[0]
+	if s.WindowStart != "2026-10-05" || s.WindowEnd != "2026-10-11" {
+		t.Errorf("window = %s..%s, want 2026-10-05..2026-10-11", s.WindowStart, s.WindowEnd)
+	}
+	// база ровно 10 в неделю: отклонение ограничено снизу единицей, z = -10
+	if s.Baseline != 10 || s.StdDev != 1 || s.ZScore != -10 || s.Severity != SeverityHigh {
+		t.Errorf("signal = %+v, want baseline 10, std 1, z -10, high severity", s)
+	}
+}
@@ -0,0 +1,146 @@
+package signals
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Виды сигналов.
+const (
+	KindLateNight    = "late_night_spike"
+	KindWeekend      = "weekend_spike"
+	KindActivityDrop = "activity_drop"
+	KindPRVolume     = "pr_volume"
+)
+
+const (
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Signal — отклонение показателя за окно [WindowStart, WindowEnd] от обычного уровня.
+type Signal struct {
+	ID          *uuid.UUID `json:"id,omitempty"` // nil — не сохранён (текущая проверка)
+	UserID      uuid.UUID  `json:"-"`
+	Kind        string     `json:"kind"`
+	Severity    string     `json:"severity"`
+	WindowStart string     `json:"window_start"`
+	WindowEnd   string     `json:"window_end"`
+	Value       float64    `json:"value"`    // показатель за последнюю неделю
+	Baseline    float64    `json:"baseline"` // среднее за предыдущие недели
+	StdDev      float64    `json:"stddev"`
+	ZScore      float64    `json:"z_score"`
+	Explanation string     `json:"explanation"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// Day — активность за день в поясе пользователя.
+type Day struct {
+	Date          time.Time
+	Contributions int
+	PRs           int
+	LateNight     int // вклады с LateNightStart до LateNightEnd
+}
+
+// Поздние часы: с 22:00 до 05:00.
+const (
+	LateNightStart = 22
+	LateNightEnd   = 5
+)
+
+type Repository interface {
+	// Days — дни [from, to] подряд, включая дни без активности.
+	Days(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) ([]Day, error)
+	// Save сохраняет сигнал, если такого же вида нет за пересекающееся окно; false — уже был.
+	Save(ctx context.Context, s *Signal) (bool, error)
+	List(ctx context.Context, userID uuid.UUID, limit int) ([]Signal, error)
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+// Days: суммы — из contributions и daily_stats, поздние вклады — по времени из activity_events
+// (загруженные коммиты — только до первого события Events API, дни графика GitHub времени не имеют).
+func (r *repo) Days(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) ([]Day, error) {
+	rows, err := r.pool.Query(ctx, `WITH events_start AS (
+			SELECT COALESCE(MIN(occurred_at), 'infinity'::timestamptz) AS t FROM activity_events
+			WHERE user_id = $1 AND type NOT IN ('BackfillCommit', 'CalendarDay')),
+		late AS (
+			SELECT (occurred_at AT TIME ZONE $4)::date AS day,
+				SUM(CASE WHEN type = 'PushEvent' THEN GREATEST(commits, 1) WHEN type = 'BackfillCommit' THEN commits ELSE 1 END) AS n
+			FROM activity_events
+			WHERE user_id = $1 AND type IN ('PushEvent', 'PullRequestEvent', 'IssuesEvent', 'BackfillCommit')
+				AND (type <> 'BackfillCommit' OR occurred_at < (SELECT t FROM events_start))
+				AND (occurred_at AT TIME ZONE $4)::date BETWEEN $2::date AND $3::date
+				AND (EXTRACT(HOUR FROM occurred_at AT TIME ZONE $4) >= $5 OR EXTRACT(HOUR FROM occurred_at AT TIME ZONE $4) < $6)
+			GROUP BY day),
+		contribs AS (
+			SELECT date, SUM(count) AS n FROM contributions
+			WHERE user_id = $1 AND date BETWEEN $2::date AND $3::date GROUP BY date)
+		SELECT d::date, COALESCE(c.n, 0), COALESCE(ds.prs, 0), COALESCE(l.n, 0)
+		FROM generate_series($2::date, $3::date, interval '1 day') AS d
+		LEFT JOIN contribs c ON c.date = d::date
+		LEFT JOIN daily_stats ds ON ds.user_id = $1 AND ds.date = d::date
+		LEFT JOIN late l ON l.day = d::date
+		ORDER BY 1`, userID, from.Format("2006-01-02"), to.Format("2006-01-02"), loc.String(), LateNightStart, LateNightEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Day
+	for rows.Next() {
+		var d Day
+		if err := rows.Scan(&d.Date, &d.Contributions, &d.PRs, &d.LateNight); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) Save(ctx context.Context, s *Signal) (bool, error) {
+	err := r.pool.QueryRow(ctx, `INSERT INTO activity_signals
+			(user_id, kind, severity, window_start, window_end, value, baseline, stddev, z_score, explanation)
+		SELECT $1, $2, $3, $4::date, $5::date, $6, $7, $8, $9, $10
+		WHERE NOT EXISTS (SELECT 1 FROM activity_signals
+			WHERE user_id = $1 AND kind = $2 AND window_start > $4::date - 7)
+		ON CONFLICT (user_id, kind, window_start) DO NOTHING
+		RETURNING id, created_at`,
+		s.UserID, s.Kind, s.Severity, s.WindowStart, s.WindowEnd, s.Value, s.Baseline, s.StdDev, s.ZScore, s.Explanation,
+	).Scan(&s.ID, &s.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *repo) List(ctx context.Context, userID uuid.UUID, limit int) ([]Signal, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id, user_id, kind, severity, window_start, window_end,
+			value, baseline, stddev, z_score, explanation, created_at
+		FROM activity_signals WHERE user_id = $1 ORDER BY window_start DESC, created_at DESC LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Signal{}
+	for rows.Next() {
+		var s Signal
+		var start, end time.Time
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Kind, &s.Severity, &start, &end,
+			&s.Value, &s.Baseline, &s.StdDev, &s.ZScore, &s.Explanation, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		s.WindowStart, s.WindowEnd = start.Format("2006-01-02"), end.Format("2006-01-02")
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,94 @@
+package signals
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+// EventSignal — websocket-событие о новом сигнале (из worker — через Redis).
+const EventSignal = "activity_signal"
+
+type Notifier interface {
+	BroadcastToUser(userID uuid.UUID, event string, data interface{})
+}
+
+// Report — текущие сигналы (по последней неделе) и история сохранённых.
+type Report struct {
+	Active  []Signal `json:"active"`
+	History []Signal `json:"history"`
+}
+
+type Service interface {
+	Get(ctx context.Context, userID uuid.UUID, limit int) (*Report, error)
+	// Check проверяет последнюю неделю после синхронизации, сохраняет новые сигналы и рассылает событие.
+	Check(ctx context.Context, userID uuid.UUID) ([]Signal, error)
+}
+
+type service struct {
+	repo     Repository
+	userSvc  user.Service
+	notifier Notifier
+}
+
+// NewService: notifier может быть nil — тогда сигналы только сохраняются.
+func NewService(repo Repository, userSvc user.Service, notifier Notifier) Service {
+	return &service{repo: repo, userSvc: userSvc, notifier: notifier}
+}
+
+func (s *service) Get(ctx context.Context, userID uuid.UUID, limit int) (*Report, error) {
+	active, err := s.detect(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.repo.List(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		active = []Signal{}
+	}
+	return &Report{Active: active, History: history}, nil
+}
+
+func (s *service) Check(ctx context.Context, userID uuid.UUID) ([]Signal, error) {
+	found, err := s.detect(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var fresh []Signal
+	for i := range found {
+		sig := &found[i]
+		sig.UserID = userID
+		ok, err := s.repo.Save(ctx, sig)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		fresh = append(fresh, *sig)
+		if s.notifier != nil {
+			s.notifier.BroadcastToUser(userID, EventSignal, sig)
+		}
+	}
+	return fresh, nil
+}
+
+// detect — сигналы по дням до вчера включительно в поясе пользователя: сегодняшний день ещё не закончился.
+func (s *service) detect(ctx context.Context, userID uuid.UUID) ([]Signal, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	loc := u.Location()
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	from := to.AddDate(0, 0, -7*historyWeeks+1)
+	days, err := s.repo.Days(ctx, userID, loc, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return Detect(days), nil
+}
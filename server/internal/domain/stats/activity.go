@@ -0,0 +1,233 @@
+package stats
+
+import (
+	"context"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ActivityRepository хранит сырые события GitHub с точным временем; contributions и daily_stats
+// пересчитываются из них с разбивкой по дням в часовом поясе пользователя.
+type ActivityRepository interface {
+	Insert(ctx context.Context, userID uuid.UUID, events []ActivityEventRow) (int, error)
+	Rebuild(ctx context.Context, userID uuid.UUID, loc *time.Location, from time.Time) error
+	// FirstEvent — время самого раннего сохранённого события; nil — событий нет.
+	FirstEvent(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	// ActiveDays — даты (YYYY-MM-DD в поясе loc), в которые были вклады.
+	ActiveDays(ctx context.Context, userID uuid.UUID, loc *time.Location) ([]string, error)
+	// FirstDay — первый день с вкладами (в поясе loc); nil — вкладов нет.
+	FirstDay(ctx context.Context, userID uuid.UUID, loc *time.Location) (*time.Time, error)
+	// Punchcard — вклады за дни [from, to] по дню недели (0 — понедельник) и часу в поясе loc.
+	Punchcard(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) (*[7][24]int, error)
+	// LanguageContributions — вклады за дни [from, to] по языку репозитория пользователя.
+	LanguageContributions(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) (map[string]int, error)
+}
+
+// Типы событий, восстановленных загрузкой истории (в Events API их нет).
+// Учитываются только раньше первого события из Events API, чтобы не считать коммиты дважды.
+const (
+	EventBackfillCommit = "BackfillCommit" // коммит из поиска или списка коммитов репозитория
+	EventCalendarDay    = "CalendarDay"    // вклады дня из графика GitHub сверх найденных коммитов
+)
+
+// ActionMerged — action закрытого с мержем PullRequestEvent (в Events API это closed + merged).
+const ActionMerged = "merged"
+
+type ActivityEventRow struct {
+	GitHubEventID string
+	Type          string
+	Action        string
+	RepoGitHubID  int64
+	RepoName      string
+	IsPublic      bool
+	Commits       int
+	OccurredAt    time.Time
+}
+
+type activityRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewActivityRepository(pool *pgxpool.Pool) ActivityRepository {
+	return &activityRepo{pool: pool}
+}
+
+// Insert пропускает уже сохранённые события; исключение — смёрженные PR, записанные раньше как closed.
+func (r *activityRepo) Insert(ctx context.Context, userID uuid.UUID, events []ActivityEventRow) (int, error) {
+	inserted := 0
+	for _, e := range events {
+		query := `INSERT INTO activity_events (user_id, github_event_id, type, action, repo_github_id, repo_name, is_public, commits, occurred_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9)
+			ON CONFLICT (user_id, github_event_id) DO UPDATE SET action = EXCLUDED.action
+			WHERE EXCLUDED.action = '` + ActionMerged + `' AND activity_events.action = 'closed'`
+		tag, err := r.pool.Exec(ctx, query,
+			userID, e.GitHubEventID, e.Type, e.Action, e.RepoGitHubID, e.RepoName, e.IsPublic, e.Commits, e.OccurredAt,
+		)
+		if err != nil {
+			return inserted, err
+		}
+		inserted += int(tag.RowsAffected())
+	}
+	return inserted, nil
+}
+
+// eventsStartCTE — момент, с которого есть данные Events API; восстановленные события учитываются только до него.
+const eventsStartCTE = `events_start AS (
+		SELECT COALESCE(MIN(occurred_at), 'infinity'::timestamptz) AS t FROM activity_events
+		WHERE user_id = $1 AND type NOT IN ('BackfillCommit', 'CalendarDay'))`
+
+// Rebuild пересчитывает contributions, daily_stats и их агрегаты начиная с дня from (в поясе loc).
+func (r *activityRepo) Rebuild(ctx context.Context, userID uuid.UUID, loc *time.Location, from time.Time) error {
+	tz := loc.String()
+	fromDate := from.In(loc).Format("2006-01-02")
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	day, err := time.Parse(dateLayout, fromDate)
+	if err != nil {
+		return err
+	}
+	if day, err = widenRebuild(ctx, tx, userID, day); err != nil {
+		return err
+	}
+	fromDate = day.Format(dateLayout)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM contributions WHERE user_id = $1 AND date >= $2::date`, userID, fromDate); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `WITH `+eventsStartCTE+`
+		INSERT INTO contributions (user_id, date, count, repo_id)
+		SELECT e.user_id, (e.occurred_at AT TIME ZONE $3)::date AS day,
+			SUM(CASE WHEN e.type = 'PushEvent' THEN GREATEST(e.commits, 1)
+				WHEN e.type IN ('BackfillCommit', 'CalendarDay') THEN e.commits ELSE 1 END), r.id
+		FROM activity_events e
+		LEFT JOIN repositories r ON r.user_id = e.user_id AND r.github_id = e.repo_github_id
+		WHERE e.user_id = $1 AND e.type IN ('PushEvent', 'PullRequestEvent', 'IssuesEvent', 'BackfillCommit', 'CalendarDay')
+			AND (e.type NOT IN ('BackfillCommit', 'CalendarDay') OR e.occurred_at < (SELECT t FROM events_start))
+			AND (e.occurred_at AT TIME ZONE $3)::date >= $2::date
+		GROUP BY e.user_id, day, r.id`, userID, fromDate, tz)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE daily_stats SET commits = 0, prs = 0, issues = 0
+		WHERE user_id = $1 AND date >= $2::date`, userID, fromDate); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `WITH `+eventsStartCTE+`
+		INSERT INTO daily_stats (user_id, date, commits, prs, issues)
+		SELECT user_id, (occurred_at AT TIME ZONE $3)::date AS day,
+			COALESCE(SUM(commits) FILTER (WHERE type IN ('PushEvent', 'BackfillCommit')), 0),
+			COUNT(*) FILTER (WHERE type = 'PullRequestEvent' AND action = 'opened'),
+			COUNT(*) FILTER (WHERE type = 'IssuesEvent' AND action = 'opened')
+		FROM activity_events
+		WHERE user_id = $1 AND (occurred_at AT TIME ZONE $3)::date >= $2::date
+			AND (type NOT IN ('BackfillCommit', 'CalendarDay') OR occurred_at < (SELECT t FROM events_start))
+		GROUP BY user_id, day
+		ON CONFLICT (user_id, date) DO UPDATE SET
+			commits = EXCLUDED.commits, prs = EXCLUDED.prs, issues = EXCLUDED.issues`, userID, fromDate, tz)
+	if err != nil {
+		return err
+	}
+	if err := refreshRollups(ctx, tx, userID, day); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ActiveDays — даты с вкладами за всю историю. Считаются по activity_events: они, в отличие от
+// сырых дней contributions, не удаляются по сроку хранения.
+func (r *activityRepo) ActiveDays(ctx context.Context, userID uuid.UUID, loc *time.Location) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `WITH `+eventsStartCTE+`
+		SELECT DISTINCT (occurred_at AT TIME ZONE $2)::date AS day FROM activity_events
+		WHERE user_id = $1 AND type IN ('PushEvent', 'PullRequestEvent', 'IssuesEvent', 'BackfillCommit', 'CalendarDay')
+			AND (type NOT IN ('BackfillCommit', 'CalendarDay') OR occurred_at < (SELECT t FROM events_start))
+		ORDER BY day`, userID, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			return nil, err
+		}
+		out = append(out, d.Format(dateLayout))
+	}
+	return out, rows.Err()
+}
+
+func (r *activityRepo) FirstEvent(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	var t *time.Time
+	err := r.pool.QueryRow(ctx, `SELECT MIN(occurred_at) FROM activity_events WHERE user_id = $1`, userID).Scan(&t)
+	return t, err
+}
+
+func (r *activityRepo) FirstDay(ctx context.Context, userID uuid.UUID, loc *time.Location) (*time.Time, error) {
+	var day *time.Time
+	err := r.pool.QueryRow(ctx, `WITH `+eventsStartCTE+`
+		SELECT MIN((occurred_at AT TIME ZONE $2)::date) FROM activity_events
+		WHERE user_id = $1 AND type IN ('PushEvent', 'PullRequestEvent', 'IssuesEvent', 'BackfillCommit', 'CalendarDay')
+			AND (type NOT IN ('BackfillCommit', 'CalendarDay') OR occurred_at < (SELECT t FROM events_start))`,
+		userID, loc.String()).Scan(&day)
+	return day, err
+}
+
+// LanguageContributions считает вклады так же, как Rebuild для contributions; события в чужих
+// репозиториях и репозиториях без языка не учитываются.
+func (r *activityRepo) LanguageContributions(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) (map[string]int, error) {
+	rows, err := r.pool.Query(ctx, `WITH `+eventsStartCTE+`
+		SELECT r.language, SUM(CASE WHEN e.type = 'PushEvent' THEN GREATEST(e.commits, 1)
+			WHEN e.type IN ('BackfillCommit', 'CalendarDay') THEN e.commits ELSE 1 END)
+		FROM activity_events e
+		JOIN repositories r ON r.user_id = e.user_id AND r.github_id = e.repo_github_id
+		WHERE e.user_id = $1 AND e.type IN ('PushEvent', 'PullRequestEvent', 'IssuesEvent', 'BackfillCommit', 'CalendarDay')
+			AND (e.type NOT IN ('BackfillCommit', 'CalendarDay') OR e.occurred_at < (SELECT t FROM events_start))
+			AND (e.occurred_at AT TIME ZONE $2)::date BETWEEN $3::date AND $4::date
+			AND COALESCE(r.language, '') <> ''
+		GROUP BY r.language`, userID, loc.String(), from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]int)
+	for rows.Next() {
+		var lang string
+		var n int
+		if err := rows.Scan(&lang, &n); err != nil {
+			return nil, err
+		}
+		out[lang] = n
+	}
+	return out, rows.Err()
+}
+
+// Punchcard берёт время события: для пушей — время пуша, для загруженных коммитов — дату автора.
+// Дни из графика GitHub (CalendarDay) времени не имеют и не учитываются.
+func (r *activityRepo) Punchcard(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) (*[7][24]int, error) {
+	rows, err := r.pool.Query(ctx, `WITH `+eventsStartCTE+`
+		SELECT EXTRACT(ISODOW FROM occurred_at AT TIME ZONE $2)::int, EXTRACT(HOUR FROM occurred_at AT TIME ZONE $2)::int,
+			SUM(CASE WHEN type = 'PushEvent' THEN GREATEST(commits, 1) WHEN type = 'BackfillCommit' THEN commits ELSE 1 END)
+		FROM activity_events
+		WHERE user_id = $1 AND type IN ('PushEvent', 'PullRequestEvent', 'IssuesEvent', 'BackfillCommit')
+			AND (type <> 'BackfillCommit' OR occurred_at < (SELECT t FROM events_start))
+			AND (occurred_at AT TIME ZONE $2)::date BETWEEN $3::date AND $4::date
+		GROUP BY 1, 2`, userID, loc.String(), from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var m [7][24]int
+	for rows.Next() {
+		var dow, hour, n int
+		if err := rows.Scan(&dow, &hour, &n); err != nil {
+			return nil, err
+		}
+		m[dow-1][hour] = n
+	}
+	return &m, rows.Err()
+}
@@ -40,11 +40,7 @@ func BuildUserStats(
 		if i >= 10 {
 			break
 		}
-		topRepos = append(topRepos, models.Repo{
-			UserID: userID, GitHubID: r.GitHubID, Name: r.Name, FullName: r.FullName,
-			Description: r.Description, Stars: r.Stars, Forks: r.Forks,
-			Language: r.Language, IsPrivate: r.IsPrivate,
-		})
+		topRepos = append(topRepos, r.toModel(userID))
 	}
 	for _, r := range repos {
 		totalStars += r.Stars
@@ -54,7 +50,7 @@ func BuildUserStats(
 	var contribDays []models.ContributionDay
 	sum := 0
 	for _, c := range contribs {
-		contribDays = append(contribDays, models.ContributionDay{Date: c.Date, Count: c.Count})
+		contribDays = append(contribDays, models.ContributionDay{Date: c.Date, Count: c.Count, Granularity: c.Granularity})
 		sum += c.Count
 	}
 
@@ -62,17 +58,13 @@ func BuildUserStats(
 	for _, d := range daily {
 		dailyStats = append(dailyStats, models.DailyStats{
 			UserID: d.UserID, Date: d.Date, Commits: d.Commits,
-			PRs: d.PRs, Issues: d.Issues, StarsReceived: d.StarsReceived,
+			PRs: d.PRs, Issues: d.Issues, StarsReceived: d.StarsReceived, Granularity: d.Granularity,
 		})
 	}
 
 	langRepos := make([]models.Repo, 0, len(repos))
 	for _, r := range repos {
-		langRepos = append(langRepos, models.Repo{
-			UserID: userID, GitHubID: r.GitHubID, Name: r.Name, FullName: r.FullName,
-			Description: r.Description, Stars: r.Stars, Forks: r.Forks,
-			Language: r.Language, IsPrivate: r.IsPrivate,
-		})
+		langRepos = append(langRepos, r.toModel(userID))
 	}
 	languages := CalculateLanguageStats(langRepos)
 
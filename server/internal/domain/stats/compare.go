@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+// Режимы сравнения периодов (?compare=).
+const (
+	ComparePrevious = "previous"
+	CompareLastYear = "same_period_last_year"
+)
+
+var ErrInvalidCompare = errors.New("invalid compare")
+
+// compareWindow — период сравнения: предыдущий (у календарных — предыдущий календарный период,
+// у остальных — той же длины сразу перед p) или те же даты годом раньше.
+func compareWindow(p Period, compare string) (time.Time, time.Time, error) {
+	if compare != ComparePrevious && compare != CompareLastYear {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: must be previous or same_period_last_year", ErrInvalidCompare)
+	}
+	if p.Name == PeriodAll {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: not available for period all", ErrInvalidCompare)
+	}
+	days := int(p.To.Sub(p.From).Hours() / 24)
+	if compare == ComparePrevious {
+		if p.unit != "" {
+			from, last := shiftPeriod(p.From, p.unit, -1), p.From.AddDate(0, 0, -1)
+			// Завершённый период сравнивается с предыдущим целиком, текущий — с тем же числом дней
+			if p.To.Equal(shiftPeriod(p.From, p.unit, 1).AddDate(0, 0, -1)) {
+				return from, last, nil
+			}
+			to := from.AddDate(0, 0, days)
+			if to.After(last) {
+				to = last
+			}
+			return from, to, nil
+		}
+		to := p.From.AddDate(0, 0, -1)
+		return to.AddDate(0, 0, -days), to, nil
+	}
+	return p.From.AddDate(-1, 0, 0), p.To.AddDate(-1, 0, 0), nil
+}
+
+func (s *service) ComparePeriods(ctx context.Context, userID uuid.UUID, q PeriodQuery, compare string) (*models.PeriodComparison, error) {
+	loc, err := s.location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.period(ctx, userID, loc, q)
+	if err != nil {
+		return nil, err
+	}
+	prevFrom, prevTo, err := compareWindow(p, compare)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := s.snapshot(ctx, userID, loc, p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+	prev, err := s.snapshot(ctx, userID, loc, prevFrom, prevTo)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PeriodComparison{
+		Compare:  compare,
+		Current:  *cur,
+		Previous: *prev,
+		Deltas: models.PeriodDeltas{
+			Contributions: delta(cur.Totals.Contributions, prev.Totals.Contributions),
+			Commits:       delta(cur.Totals.Commits, prev.Totals.Commits),
+			PRs:           delta(cur.Totals.PRs, prev.Totals.PRs),
+			Issues:        delta(cur.Totals.Issues, prev.Totals.Issues),
+			StarsReceived: delta(cur.Totals.StarsReceived, prev.Totals.StarsReceived),
+			ActiveDays:    delta(cur.Totals.ActiveDays, prev.Totals.ActiveDays),
+			Languages:     languageDeltas(cur.Languages, prev.Languages),
+		},
+	}, nil
+}
+
+func (s *service) snapshot(ctx context.Context, userID uuid.UUID, loc *time.Location, from, to time.Time) (*models.PeriodSnapshot, error) {
+	_, _, totals, err := s.loadRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	langs, err := s.activityRepo.LanguageContributions(ctx, userID, loc, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PeriodSnapshot{
+		From:      from.Format(dateLayout),
+		To:        to.Format(dateLayout),
+		Totals:    totals,
+		Languages: languageShares(langs),
+	}, nil
+}
+
+// languageShares — доли языков по убыванию вкладов.
+func languageShares(m map[string]int) []models.LanguageShare {
+	total := 0
+	for _, n := range m {
+		total += n
+	}
+	out := make([]models.LanguageShare, 0, len(m))
+	for lang, n := range m {
+		pct := 0.0
+		if total > 0 {
+			pct = round1(float64(n) / float64(total) * 100)
+		}
+		out = append(out, models.LanguageShare{Language: lang, Contributions: n, Percent: pct})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Contributions != out[j].Contributions {
+			return out[i].Contributions > out[j].Contributions
+		}
+		return out[i].Language < out[j].Language
+	})
+	return out
+}
+
+// languageDeltas — языки из обоих периодов; порядок — как у текущего, затем исчезнувшие.
+func languageDeltas(cur, prev []models.LanguageShare) []models.LanguageDelta {
+	prevPct := make(map[string]float64, len(prev))
+	for _, l := range prev {
+		prevPct[l.Language] = l.Percent
+	}
+	out := make([]models.LanguageDelta, 0, len(cur)+len(prev))
+	seen := make(map[string]bool, len(cur))
+	for _, l := range cur {
+		seen[l.Language] = true
+		out = append(out, models.LanguageDelta{Language: l.Language, Current: l.Percent, Previous: prevPct[l.Language],
+			Delta: round1(l.Percent - prevPct[l.Language])})
+	}
+	for _, l := range prev {
+		if !seen[l.Language] {
+			out = append(out, models.LanguageDelta{Language: l.Language, Previous: l.Percent, Delta: -l.Percent})
+		}
+	}
+	return out
+}
+
+func delta(cur, prev int) models.Delta {
+	d := models.Delta{Abs: cur - prev}
+	if prev != 0 {
+		pct := round1(float64(cur-prev) / float64(prev) * 100)
+		d.Percent = &pct
+	}
+	return d
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
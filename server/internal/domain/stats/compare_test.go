@@ -0,0 +1,97 @@
+package stats
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+func TestDelta(t *testing.T) {
+	tests := []struct {
+		cur, prev int
+		abs       int
+		percent   *float64
+	}{
+		{cur: 15, prev: 10, abs: 5, percent: ptr(50)},
+		{cur: 10, prev: 15, abs: -5, percent: ptr(-33.3)},
+		{cur: 7, prev: 7, abs: 0, percent: ptr(0)},
+		{cur: 1, prev: 3, abs: -2, percent: ptr(-66.7)},
+		// с нуля рост в процентах не определён
+		{cur: 4, prev: 0, abs: 4},
+		{cur: 0, prev: 0, abs: 0},
+	}
+	for _, tt := range tests {
+		got := delta(tt.cur, tt.prev)
+		if got.Abs != tt.abs || !reflect.DeepEqual(got.Percent, tt.percent) {
+			t.Errorf("delta(%d, %d) = %d %v, want %d %v", tt.cur, tt.prev, got.Abs, fmtPtr(got.Percent), tt.abs, fmtPtr(tt.percent))
+		}
+	}
+}
+
+func TestCompareWindow(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		period, compare string
+		from, to        string
+	}{
+		// текущий календарный период — с тем же числом дней предыдущего
+		{"this_month", ComparePrevious, "2026-09-01", "2026-09-14"},
+		// завершённый — с предыдущим целиком
+		{"last_month", ComparePrevious, "2026-08-01", "2026-08-31"},
+		{"this_quarter", ComparePrevious, "2026-07-01", "2026-07-14"},
+		// скользящий — та же длина сразу перед периодом
+		{"week", ComparePrevious, "2026-09-29", "2026-10-06"},
+		{"this_month", CompareLastYear, "2025-10-01", "2025-10-14"},
+		{"2026-02", CompareLastYear, "2025-02-01", "2025-02-28"},
+	}
+	for _, tt := range tests {
+		p, err := ResolvePeriod(PeriodQuery{Period: tt.period}, now)
+		if err != nil {
+			t.Fatalf("ResolvePeriod(%s): %v", tt.period, err)
+		}
+		from, to, err := compareWindow(p, tt.compare)
+		if err != nil {
+			t.Fatalf("compareWindow(%s, %s): %v", tt.period, tt.compare, err)
+		}
+		if from.Format(dateLayout) != tt.from || to.Format(dateLayout) != tt.to {
+			t.Errorf("compareWindow(%s, %s) = %s..%s, want %s..%s", tt.period, tt.compare,
+				from.Format(dateLayout), to.Format(dateLayout), tt.from, tt.to)
+		}
+	}
+}
+
+func TestCompareWindowInvalid(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	all, _ := ResolvePeriod(PeriodQuery{Period: PeriodAll}, now)
+	if _, _, err := compareWindow(all, ComparePrevious); !errors.Is(err, ErrInvalidCompare) {
+		t.Errorf("compare for all: error = %v, want ErrInvalidCompare", err)
+	}
+	year, _ := ResolvePeriod(PeriodQuery{}, now)
+	if _, _, err := compareWindow(year, "next"); !errors.Is(err, ErrInvalidCompare) {
+		t.Errorf("unknown compare: error = %v, want ErrInvalidCompare", err)
+	}
+}
+
+func TestLanguageDeltas(t *testing.T) {
+	cur := languageShares(map[string]int{"Go": 3, "TypeScript": 1})
+	prev := languageShares(map[string]int{"Go": 1, "Rust": 1})
+	want := []models.LanguageDelta{
+		{Language: "Go", Current: 75, Previous: 50, Delta: 25},
+		{Language: "TypeScript", Current: 25, Delta: 25},
+		{Language: "Rust", Previous: 50, Delta: -50},
+	}
+	if got := languageDeltas(cur, prev); !reflect.DeepEqual(got, want) {
+		t.Errorf("languageDeltas = %+v, want %+v", got, want)
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func fmtPtr(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
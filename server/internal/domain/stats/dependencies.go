@@ -0,0 +1,110 @@
+package stats
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DependencyRepository — зависимости из манифестов (go.mod, package.json, ...) по репозиториям.
+type DependencyRepository interface {
+	ReposToScan(ctx context.Context, userID uuid.UUID, limit int) ([]RepoToScan, error)
+	ReplaceForRepo(ctx context.Context, userID, repoID uuid.UUID, deps []DependencyRow) error
+	TopByUser(ctx context.Context, userID uuid.UUID, limit int) ([]DependencyUsage, error)
+}
+
+type RepoToScan struct {
+	ID            uuid.UUID
+	FullName      string
+	DefaultBranch string
+}
+
+type DependencyRow struct {
+	Ecosystem string
+	Name      string
+	Version   string
+	Manifest  string
+	IsDev     bool
+}
+
+// DependencyUsage — в скольких репозиториях пользователя встречается зависимость.
+type DependencyUsage struct {
+	Ecosystem string
+	Name      string
+	Repos     int
+	DevOnly   bool
+}
+
+type dependencyRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewDependencyRepository(pool *pgxpool.Pool) DependencyRepository {
+	return &dependencyRepo{pool: pool}
+}
+
+// ReposToScan — собственные (не форки) репозитории, в которые пушили после последнего сканирования.
+func (r *dependencyRepo) ReposToScan(ctx context.Context, userID uuid.UUID, limit int) ([]RepoToScan, error) {
+	query := `SELECT id, full_name, COALESCE(default_branch, '') FROM repositories
+		WHERE user_id = $1 AND NOT is_fork AND COALESCE(size_kb, 0) > 0
+			AND (deps_scanned_at IS NULL OR (pushed_at IS NOT NULL AND pushed_at > deps_scanned_at))
+		ORDER BY pushed_at DESC NULLS LAST LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []RepoToScan
+	for rows.Next() {
+		var row RepoToScan
+		if err := rows.Scan(&row.ID, &row.FullName, &row.DefaultBranch); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func (r *dependencyRepo) ReplaceForRepo(ctx context.Context, userID, repoID uuid.UUID, deps []DependencyRow) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `DELETE FROM repo_dependencies WHERE repo_id = $1`, repoID); err != nil {
+		return err
+	}
+	for _, d := range deps {
+		_, err := tx.Exec(ctx, `INSERT INTO repo_dependencies (user_id, repo_id, ecosystem, name, version, manifest, is_dev)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+			ON CONFLICT (repo_id, ecosystem, name) DO UPDATE SET is_dev = repo_dependencies.is_dev AND EXCLUDED.is_dev`,
+			userID, repoID, d.Ecosystem, d.Name, d.Version, d.Manifest, d.IsDev)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE repositories SET deps_scanned_at = NOW() WHERE id = $1`, repoID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *dependencyRepo) TopByUser(ctx context.Context, userID uuid.UUID, limit int) ([]DependencyUsage, error) {
+	query := `SELECT ecosystem, name, COUNT(DISTINCT repo_id), BOOL_AND(is_dev)
+		FROM repo_dependencies WHERE user_id = $1
+		GROUP BY ecosystem, name ORDER BY COUNT(DISTINCT repo_id) DESC, name LIMIT $2`
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []DependencyUsage
+	for rows.Next() {
+		var row DependencyUsage
+		if err := rows.Scan(&row.Ecosystem, &row.Name, &row.Repos, &row.DevOnly); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,162 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+// Особые периоды (?period=); остальные — см. ResolvePeriod.
+const (
+	PeriodAll    = "all"
+	PeriodCustom = "custom" // явные from/to
+)
+
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// PeriodQuery — период из запроса: имя или явные from/to (YYYY-MM-DD, включительно).
+type PeriodQuery struct {
+	Period string
+	From   string
+	To     string
+}
+
+// Period — разрешённый период: дни [From, To] в поясе пользователя, To не позже сегодня.
+type Period struct {
+	Name string
+	From time.Time
+	To   time.Time
+	unit string // week, month, quarter, year — у календарных периодов
+}
+
+// Скользящие периоды: последние N дней до сегодня.
+var rollingDays = map[string]int{"week": 7, "month": 30, "quarter": 90, "year": 365}
+
+var calendarUnits = map[string]bool{GranularityWeek: true, GranularityMonth: true, granularityQuarter: true, GranularityYear: true}
+
+const granularityQuarter = "quarter"
+
+// 2026-Q1, 2026Q1, Q1-2026, «Q1 2026»
+var quarterRe = regexp.MustCompile(`^(?:(\d{4})[- ]?q([1-4])|q([1-4])[- ]?(\d{4}))$`)
+
+// IsRelativePeriod — период, который сдвигается вместе с сегодняшним днём (подходит для подписок на отчёты).
+func IsRelativePeriod(name string) bool {
+	if _, ok := rollingDays[name]; ok {
+		return true
+	}
+	rel, unit, ok := strings.Cut(name, "_")
+	return ok && (rel == "this" || rel == "last") && calendarUnits[unit]
+}
+
+// ResolvePeriod разбирает период относительно now (время в поясе пользователя):
+//   - week, month, quarter, year — последние 7, 30, 90, 365 дней; пусто — year;
+//   - this_week, last_week, this_month, last_month, this_quarter, last_quarter, this_year, last_year;
+//   - 2026-Q1 (или Q1 2026), 2026-03, 2026;
+//   - all — вся история: From остаётся нулевым, его подставляет сервис;
+//   - явные from/to (без period или с period=custom); пустой to — сегодня.
+func ResolvePeriod(q PeriodQuery, now time.Time) (Period, error) {
+	today := truncateTo(GranularityDay, now)
+	name := strings.ToLower(strings.TrimSpace(q.Period))
+	if q.From != "" || q.To != "" {
+		if name != "" && name != PeriodCustom {
+			return Period{}, fmt.Errorf("%w: use either period or from/to", ErrInvalidPeriod)
+		}
+		if q.From == "" {
+			return Period{}, fmt.Errorf("%w: from is required", ErrInvalidPeriod)
+		}
+		from, err := time.Parse(dateLayout, q.From)
+		if err != nil {
+			return Period{}, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidPeriod)
+		}
+		to := today
+		if q.To != "" {
+			if to, err = time.Parse(dateLayout, q.To); err != nil {
+				return Period{}, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidPeriod)
+			}
+		}
+		if to.Before(from) {
+			return Period{}, fmt.Errorf("%w: to is before from", ErrInvalidPeriod)
+		}
+		if from.After(today) {
+			return Period{}, fmt.Errorf("%w: from is in the future", ErrInvalidPeriod)
+		}
+		if to.After(today) {
+			to = today
+		}
+		return Period{Name: PeriodCustom, From: from, To: to}, nil
+	}
+
+	if name == "" {
+		name = "year"
+	}
+	if days, ok := rollingDays[name]; ok {
+		return Period{Name: name, From: today.AddDate(0, 0, -days), To: today}, nil
+	}
+	switch name {
+	case PeriodAll:
+		return Period{Name: name, To: today}, nil
+	case PeriodCustom:
+		return Period{}, fmt.Errorf("%w: custom period requires from", ErrInvalidPeriod)
+	}
+	if rel, unit, ok := strings.Cut(name, "_"); ok && (rel == "this" || rel == "last") && calendarUnits[unit] {
+		start := startOf(unit, today)
+		if rel == "last" {
+			start = shiftPeriod(start, unit, -1)
+		}
+		return calendarPeriod(name, start, unit, today)
+	}
+	if m := quarterRe.FindStringSubmatch(name); m != nil {
+		year, q := m[1]+m[4], m[2]+m[3]
+		y, _ := strconv.Atoi(year)
+		n, _ := strconv.Atoi(q)
+		start := time.Date(y, time.Month((n-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+		return calendarPeriod(fmt.Sprintf("%d-Q%d", y, n), start, granularityQuarter, today)
+	}
+	if start, err := time.Parse("2006-01", name); err == nil && len(name) == 7 {
+		return calendarPeriod(name, start, GranularityMonth, today)
+	}
+	if start, err := time.Parse("2006", name); err == nil && len(name) == 4 {
+		return calendarPeriod(name, start, GranularityYear, today)
+	}
+	return Period{}, fmt.Errorf("%w: unknown period %q", ErrInvalidPeriod, q.Period)
+}
+
+func calendarPeriod(name string, start time.Time, unit string, today time.Time) (Period, error) {
+	if start.After(today) {
+		return Period{}, fmt.Errorf("%w: %s is in the future", ErrInvalidPeriod, name)
+	}
+	end := shiftPeriod(start, unit, 1).AddDate(0, 0, -1)
+	if end.After(today) {
+		end = today
+	}
+	return Period{Name: name, From: start, To: end, unit: unit}, nil
+}
+
+// startOf — первый день календарной недели (понедельник), месяца, квартала или года.
+func startOf(unit string, d time.Time) time.Time {
+	if unit == granularityQuarter {
+		return time.Date(d.Year(), (d.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return truncateTo(unit, d)
+}
+
+// shiftPeriod сдвигает начало календарного периода на n периодов.
+func shiftPeriod(start time.Time, unit string, n int) time.Time {
+	switch unit {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7*n)
+	case GranularityMonth:
+		return start.AddDate(0, n, 0)
+	case granularityQuarter:
+		return start.AddDate(0, 3*n, 0)
+	}
+	return start.AddDate(n, 0, 0)
+}
+
+func (p Period) Range() models.PeriodRange {
+	return models.PeriodRange{Name: p.Name, From: p.From.Format(dateLayout), To: p.To.Format(dateLayout)}
+}
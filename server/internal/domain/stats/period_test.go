@@ -0,0 +1,88 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResolvePeriod(t *testing.T) {
+	// среда, 14 октября 2026
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		q        PeriodQuery
+		name     string
+		from, to string
+	}{
+		{PeriodQuery{}, "year", "2025-10-14", "2026-10-14"},
+		{PeriodQuery{Period: "week"}, "week", "2026-10-07", "2026-10-14"},
+		{PeriodQuery{Period: "Quarter"}, "quarter", "2026-07-16", "2026-10-14"},
+		{PeriodQuery{Period: "this_week"}, "this_week", "2026-10-12", "2026-10-14"},
+		{PeriodQuery{Period: "last_week"}, "last_week", "2026-10-05", "2026-10-11"},
+		{PeriodQuery{Period: "this_month"}, "this_month", "2026-10-01", "2026-10-14"},
+		{PeriodQuery{Period: "last_month"}, "last_month", "2026-09-01", "2026-09-30"},
+		{PeriodQuery{Period: "last_quarter"}, "last_quarter", "2026-07-01", "2026-09-30"},
+		{PeriodQuery{Period: "last_year"}, "last_year", "2025-01-01", "2025-12-31"},
+		{PeriodQuery{Period: "2026-Q1"}, "2026-Q1", "2026-01-01", "2026-03-31"},
+		{PeriodQuery{Period: "Q4 2026"}, "2026-Q4", "2026-10-01", "2026-10-14"},
+		{PeriodQuery{Period: "2026-02"}, "2026-02", "2026-02-01", "2026-02-28"},
+		{PeriodQuery{Period: "2025"}, "2025", "2025-01-01", "2025-12-31"},
+		{PeriodQuery{From: "2026-09-01", To: "2026-09-10"}, PeriodCustom, "2026-09-01", "2026-09-10"},
+		{PeriodQuery{Period: "custom", From: "2026-09-01"}, PeriodCustom, "2026-09-01", "2026-10-14"},
+		{PeriodQuery{From: "2026-10-01", To: "2026-12-31"}, PeriodCustom, "2026-10-01", "2026-10-14"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.q.Period+tt.q.From+tt.q.To, func(t *testing.T) {
+			p, err := ResolvePeriod(tt.q, now)
+			if err != nil {
+				t.Fatalf("ResolvePeriod(%+v): %v", tt.q, err)
+			}
+			r := p.Range()
+			if r.Name != tt.name || r.From != tt.from || r.To != tt.to {
+				t.Errorf("ResolvePeriod(%+v) = %s %s..%s, want %s %s..%s", tt.q, r.Name, r.From, r.To, tt.name, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestResolvePeriodAll(t *testing.T) {
+	p, err := ResolvePeriod(PeriodQuery{Period: PeriodAll}, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.From.IsZero() || p.To.Format(dateLayout) != "2026-10-14" {
+		t.Errorf("all = %v..%v, want zero From and To today", p.From, p.To)
+	}
+}
+
+func TestResolvePeriodUsesUserDate(t *testing.T) {
+	// 23:30 14 октября в UTC-5 — в UTC уже 15-е, но сегодня у пользователя 14-е
+	now := time.Date(2026, 10, 14, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*3600))
+	p, err := ResolvePeriod(PeriodQuery{Period: "this_month"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.To.Format(dateLayout); got != "2026-10-14" {
+		t.Errorf("To = %s, want 2026-10-14", got)
+	}
+}
+
+func TestResolvePeriodInvalid(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	for _, q := range []PeriodQuery{
+		{Period: "fortnight"},
+		{Period: "2027"},
+		{Period: "2026-Q5"},
+		{Period: "custom"},
+		{Period: "week", From: "2026-09-01"},
+		{To: "2026-09-10"},
+		{From: "01.09.2026"},
+		{From: "2026-09-01", To: "10.09.2026"},
+		{From: "2026-09-10", To: "2026-09-01"},
+		{From: "2026-11-01"},
+	} {
+		if _, err := ResolvePeriod(q, now); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("ResolvePeriod(%+v) error = %v, want ErrInvalidPeriod", q, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package stats
+
+import (
+	"context"
+	"math"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+// Рабочие часы для after_hours_ratio: будни с WorkdayStart до WorkdayEnd.
+const (
+	WorkdayStart = 9
+	WorkdayEnd   = 18
+)
+
+func (s *service) GetPunchcard(ctx context.Context, userID uuid.UUID, q PeriodQuery) (*models.Punchcard, error) {
+	loc, err := s.location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.period(ctx, userID, loc, q)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.activityRepo.Punchcard(ctx, userID, loc, p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+	pc := &models.Punchcard{Period: p.Range(), Matrix: make([][]int, 7), WorkPattern: workPattern(m)}
+	for d := range m {
+		pc.Matrix[d] = m[d][:]
+	}
+	return pc, nil
+}
+
+// workPattern — доли вкладов в субботу и воскресенье и в будни вне рабочих часов.
+func workPattern(m *[7][24]int) models.WorkPattern {
+	var total, weekend, afterHours int
+	for d := range m {
+		for h, n := range m[d] {
+			total += n
+			switch {
+			case d >= 5:
+				weekend += n
+			case h < WorkdayStart || h >= WorkdayEnd:
+				afterHours += n
+			}
+		}
+	}
+	wp := models.WorkPattern{TimedContributions: total}
+	if total > 0 {
+		wp.WeekendRatio = ratio(weekend, total)
+		wp.AfterHoursRatio = ratio(afterHours, total)
+	}
+	return wp
+}
+
+func ratio(n, total int) float64 {
+	return math.Round(float64(n)/float64(total)*1000) / 1000
+}
+
+// workPatternFor — то же для GetUserStatsWithPeriod, без матрицы.
+func (s *service) workPatternFor(ctx context.Context, userID uuid.UUID, loc *time.Location, p Period) (models.WorkPattern, error) {
+	m, err := s.activityRepo.Punchcard(ctx, userID, loc, p.From, p.To)
+	if err != nil {
+		return models.WorkPattern{}, err
+	}
+	return workPattern(m), nil
+}
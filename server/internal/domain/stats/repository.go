@@ -2,13 +2,21 @@ package stats
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/devsync/server/internal/domain/models"
 )
 
 type RepoRepository interface {
 	Upsert(ctx context.Context, userID uuid.UUID, repos []RepoRow) error
 	ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]RepoRow, error)
+	// Search ищет по репозиториям нескольких пользователей; общий репозиторий (по github_id) возвращается один раз.
+	Search(ctx context.Context, userIDs []uuid.UUID, f RepoFilter) ([]RepoRow, error)
 	GetByUserAndGitHubID(ctx context.Context, userID uuid.UUID, githubID int64) (*uuid.UUID, error)
 }
 
@@ -19,10 +27,14 @@ type ContributionRepository interface {
 
 type DailyStatsRepository interface {
 	Upsert(ctx context.Context, row DailyStatsRow) error
+	// RecordStars сохраняет снимок звёзд собственных репозиториев за день now (в его поясе) и пишет
+	// в stars_received разницу с предыдущим снимком. Первый снимок — только точка отсчёта.
+	RecordStars(ctx context.Context, userID uuid.UUID, now time.Time) error
 	GetByUserDateRange(ctx context.Context, userID uuid.UUID, from, to string) ([]DailyStatsRow, error)
 }
 
 type RepoRow struct {
+	UserID      uuid.UUID // владелец строки; при записи не используется
 	GitHubID    int64
 	Name        string
 	FullName    string
@@ -31,12 +43,69 @@ type RepoRow struct {
 	Forks       int
 	Language    string
 	IsPrivate   bool
+	IsFork      bool
+	IsArchived  bool
+	IsTemplate  bool
+	Topics      []string
+	License     string
+	SizeKB      int
+	OpenIssues  int
+	Homepage    string
+	DefaultBranch string
+	CreatedAt   *time.Time
+	PushedAt    *time.Time
+	UpdatedAt   *time.Time // updated_at на GitHub
+}
+
+func (r RepoRow) toModel(userID uuid.UUID) models.Repo {
+	topics := r.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+	return models.Repo{
+		UserID: userID, GitHubID: r.GitHubID, Name: r.Name, FullName: r.FullName,
+		Description: r.Description, Stars: r.Stars, Forks: r.Forks,
+		Language: r.Language, IsPrivate: r.IsPrivate,
+		IsFork: r.IsFork, IsArchived: r.IsArchived, IsTemplate: r.IsTemplate,
+		Topics: topics, License: r.License, SizeKB: r.SizeKB, OpenIssues: r.OpenIssues,
+		Homepage: r.Homepage, DefaultBranch: r.DefaultBranch,
+		CreatedAt: r.CreatedAt, PushedAt: r.PushedAt, UpdatedAt: r.UpdatedAt,
+	}
+}
+
+// RepoFilter — фильтры и сортировка для GET /api/user/repos. nil-флаги не фильтруют.
+type RepoFilter struct {
+	Fork     *bool
+	Archived *bool
+	Template *bool
+	Private  *bool
+	Topic    string
+	Language string
+	License  string
+	Sort     string // stars, forks, pushed, created, updated, name, size, issues
+	Order    string // asc, desc
+	Limit    int
+	Offset   int
+}
+
+var ErrInvalidRepoFilter = errors.New("invalid repository filter")
+
+var repoSortColumns = map[string]string{
+	"stars":   "stars",
+	"forks":   "forks",
+	"pushed":  "pushed_at",
+	"created": "repo_created_at",
+	"updated": "repo_updated_at",
+	"name":    "LOWER(name)",
+	"size":    "size_kb",
+	"issues":  "open_issues",
 }
 
 type ContributionRow struct {
 	Date  string
 	Count int
 	RepoID *uuid.UUID
+	Granularity string // пусто — день, иначе точка из агрегата
 }
 
 type DailyStatsRow struct {
@@ -46,6 +115,7 @@ type DailyStatsRow struct {
 	PRs           int
 	Issues        int
 	StarsReceived int
+	Granularity   string
 }
 
 type repoRepo struct {
@@ -58,15 +128,27 @@ func NewRepoRepository(pool *pgxpool.Pool) RepoRepository {
 
 func (r *repoRepo) Upsert(ctx context.Context, userID uuid.UUID, repos []RepoRow) error {
 	for _, repo := range repos {
-		query := `INSERT INTO repositories (user_id, github_id, name, full_name, description, stars, forks, language, is_private, last_updated)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		query := `INSERT INTO repositories (user_id, github_id, name, full_name, description, stars, forks, language, is_private,
+				is_fork, is_archived, is_template, topics, license, size_kb, open_issues, homepage, default_branch,
+				repo_created_at, pushed_at, repo_updated_at, last_updated)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), $15, $16, NULLIF($17, ''), NULLIF($18, ''), $19, $20, $21, NOW())
 			ON CONFLICT (user_id, github_id) DO UPDATE SET
 				name = EXCLUDED.name, full_name = EXCLUDED.full_name, description = EXCLUDED.description,
 				stars = EXCLUDED.stars, forks = EXCLUDED.forks, language = EXCLUDED.language,
-				is_private = EXCLUDED.is_private, last_updated = NOW()`
+				is_private = EXCLUDED.is_private, is_fork = EXCLUDED.is_fork, is_archived = EXCLUDED.is_archived,
+				is_template = EXCLUDED.is_template, topics = EXCLUDED.topics, license = EXCLUDED.license,
+				size_kb = EXCLUDED.size_kb, open_issues = EXCLUDED.open_issues, homepage = EXCLUDED.homepage,
+				default_branch = EXCLUDED.default_branch, repo_created_at = EXCLUDED.repo_created_at,
+				pushed_at = EXCLUDED.pushed_at, repo_updated_at = EXCLUDED.repo_updated_at, last_updated = NOW()`
+		topics := repo.Topics
+		if topics == nil {
+			topics = []string{}
+		}
 		_, err := r.pool.Exec(ctx, query,
 			userID, repo.GitHubID, repo.Name, repo.FullName, repo.Description,
 			repo.Stars, repo.Forks, repo.Language, repo.IsPrivate,
+			repo.IsFork, repo.IsArchived, repo.IsTemplate, topics, repo.License, repo.SizeKB, repo.OpenIssues,
+			repo.Homepage, repo.DefaultBranch, repo.CreatedAt, repo.PushedAt, repo.UpdatedAt,
 		)
 		if err != nil {
 			return err
@@ -75,13 +157,85 @@ func (r *repoRepo) Upsert(ctx context.Context, userID uuid.UUID, repos []RepoRow
 	return nil
 }
 
+const repoColumns = `github_id, name, full_name, COALESCE(description,''), stars, forks, COALESCE(language,''), is_private,
+	is_fork, is_archived, is_template, topics, COALESCE(license,''), COALESCE(size_kb,0), COALESCE(open_issues,0),
+	COALESCE(homepage,''), COALESCE(default_branch,''), repo_created_at, pushed_at, repo_updated_at, user_id`
+
 func (r *repoRepo) ListByUser(ctx context.Context, userID uuid.UUID, limit int) ([]RepoRow, error) {
 	if limit <= 0 {
 		limit = 50
 	}
-	query := `SELECT github_id, name, full_name, COALESCE(description,''), stars, forks, COALESCE(language,''), is_private
+	query := `SELECT ` + repoColumns + `
 		FROM repositories WHERE user_id = $1 ORDER BY stars DESC, forks DESC LIMIT $2`
-	rows, err := r.pool.Query(ctx, query, userID, limit)
+	return r.query(ctx, query, userID, limit)
+}
+
+func (r *repoRepo) Search(ctx context.Context, userIDs []uuid.UUID, f RepoFilter) ([]RepoRow, error) {
+	query, args, err := repoSearchQuery(userIDs, f)
+	if err != nil {
+		return nil, err
+	}
+	return r.query(ctx, query, args...)
+}
+
+// repoSearchQuery строит запрос Search; неизвестные сортировка и порядок — ErrInvalidRepoFilter.
+func repoSearchQuery(userIDs []uuid.UUID, f RepoFilter) (string, []interface{}, error) {
+	where := []string{"user_id = ANY($1)"}
+	args := []interface{}{userIDs}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	flags := []struct {
+		col string
+		v   *bool
+	}{{"is_fork", f.Fork}, {"is_archived", f.Archived}, {"is_template", f.Template}, {"is_private", f.Private}}
+	for _, fl := range flags {
+		if fl.v != nil {
+			where = append(where, fl.col+" = "+arg(*fl.v))
+		}
+	}
+	if f.Topic != "" {
+		where = append(where, arg(strings.ToLower(f.Topic))+" = ANY(topics)")
+	}
+	if f.Language != "" {
+		where = append(where, "LOWER(language) = "+arg(strings.ToLower(f.Language)))
+	}
+	if f.License != "" {
+		where = append(where, "LOWER(license) = "+arg(strings.ToLower(f.License)))
+	}
+	sort := f.Sort
+	if sort == "" {
+		sort = "stars"
+	}
+	col, ok := repoSortColumns[sort]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidRepoFilter, f.Sort)
+	}
+	order := strings.ToUpper(f.Order)
+	switch order {
+	case "":
+		order = "DESC"
+		if sort == "name" {
+			order = "ASC"
+		}
+	case "ASC", "DESC":
+	default:
+		return "", nil, fmt.Errorf("%w: unknown order %q", ErrInvalidRepoFilter, f.Order)
+	}
+	limit := f.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	// Один репозиторий могут синхронизировать несколько пользователей (репозитории организаций)
+	query := `SELECT ` + repoColumns + ` FROM (SELECT DISTINCT ON (github_id) * FROM repositories WHERE ` + strings.Join(where, " AND ") +
+		` ORDER BY github_id, last_updated DESC NULLS LAST) repositories` +
+		` ORDER BY ` + col + ` ` + order + ` NULLS LAST, stars DESC, name LIMIT ` + arg(limit) + ` OFFSET ` + arg(f.Offset)
+	return query, args, nil
+}
+
+func (r *repoRepo) query(ctx context.Context, query string, args ...interface{}) ([]RepoRow, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -89,13 +243,15 @@ func (r *repoRepo) ListByUser(ctx context.Context, userID uuid.UUID, limit int)
 	var result []RepoRow
 	for rows.Next() {
 		var row RepoRow
-		err := rows.Scan(&row.GitHubID, &row.Name, &row.FullName, &row.Description, &row.Stars, &row.Forks, &row.Language, &row.IsPrivate)
+		err := rows.Scan(&row.GitHubID, &row.Name, &row.FullName, &row.Description, &row.Stars, &row.Forks, &row.Language, &row.IsPrivate,
+			&row.IsFork, &row.IsArchived, &row.IsTemplate, &row.Topics, &row.License, &row.SizeKB, &row.OpenIssues,
+			&row.Homepage, &row.DefaultBranch, &row.CreatedAt, &row.PushedAt, &row.UpdatedAt, &row.UserID)
 		if err != nil {
 			return nil, err
 		}
 		result = append(result, row)
 	}
-	return result, nil
+	return result, rows.Err()
 }
 
 func (r *repoRepo) GetByUserAndGitHubID(ctx context.Context, userID uuid.UUID, githubID int64) (*uuid.UUID, error) {
@@ -161,6 +317,48 @@ func (r *dailyStatsRepo) Upsert(ctx context.Context, row DailyStatsRow) error {
 	return err
 }
 
+func (r *dailyStatsRepo) RecordStars(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	date := day.Format(dateLayout)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	// Звёзды считаются только у своих репозиториев: /user/repos отдаёт и репозитории организаций
+	var stars int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(SUM(r.stars), 0)
+		FROM repositories r JOIN users u ON u.id = r.user_id
+		WHERE r.user_id = $1 AND LOWER(split_part(r.full_name, '/', 1)) = LOWER(u.username)`, userID).Scan(&stars)
+	if err != nil {
+		return err
+	}
+	var prev int
+	err = tx.QueryRow(ctx, `SELECT stars FROM star_snapshots WHERE user_id = $1 AND date < $2::date
+		ORDER BY date DESC LIMIT 1`, userID, date).Scan(&prev)
+	first := errors.Is(err, pgx.ErrNoRows)
+	if err != nil && !first {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO star_snapshots (user_id, date, stars) VALUES ($1, $2::date, $3)
+		ON CONFLICT (user_id, date) DO UPDATE SET stars = EXCLUDED.stars`, userID, date, stars); err != nil {
+		return err
+	}
+	if first {
+		return tx.Commit(ctx)
+	}
+	// Разница чистая (снятые звёзды вычитаются); если синхронизаций не было несколько дней,
+	// вся она приходится на день синхронизации
+	if _, err := tx.Exec(ctx, `INSERT INTO daily_stats (user_id, date, stars_received) VALUES ($1, $2::date, $3)
+		ON CONFLICT (user_id, date) DO UPDATE SET stars_received = EXCLUDED.stars_received`, userID, date, stars-prev); err != nil {
+		return err
+	}
+	if err := refreshRollups(ctx, tx, userID, day); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 func (r *dailyStatsRepo) GetByUserDateRange(ctx context.Context, userID uuid.UUID, from, to string) ([]DailyStatsRow, error) {
 	query := `SELECT user_id, date::text, commits, prs, issues, stars_received
 		FROM daily_stats WHERE user_id = $1 AND date >= $2::date AND date <= $3::date ORDER BY date`
@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"github.com/google/uuid"
+)
+
+func TestRepoSearchQuerySort(t *testing.T) {
+	users := []uuid.UUID{uuid.New()}
+	tests := []struct {
+		sort, order string
+		want        string
+	}{
+		{"", "", "ORDER BY stars DESC NULLS LAST"},
+		// updated — время изменения на GitHub, а не время синхронизации (last_updated)
+		{"updated", "", "ORDER BY repo_updated_at DESC NULLS LAST"},
+		{"created", "asc", "ORDER BY repo_created_at ASC NULLS LAST"},
+		{"name", "", "ORDER BY LOWER(name) ASC NULLS LAST"},
+	}
+	for _, tt := range tests {
+		query, _, err := repoSearchQuery(users, RepoFilter{Sort: tt.sort, Order: tt.order})
+		if err != nil {
+			t.Fatalf("sort %q: %v", tt.sort, err)
+		}
+		if !strings.Contains(query, tt.want) {
+			t.Errorf("sort %q %q: query %q does not contain %q", tt.sort, tt.order, query, tt.want)
+		}
+	}
+}
+
+func TestRepoSearchQueryArgs(t *testing.T) {
+	users := []uuid.UUID{uuid.New()}
+	fork := false
+	query, args, err := repoSearchQuery(users, RepoFilter{Fork: &fork, Topic: "Go", Language: "Rust", Limit: 10, Offset: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cond := range []string{"is_fork = $2", "$3 = ANY(topics)", "LOWER(language) = $4", "LIMIT $5 OFFSET $6"} {
+		if !strings.Contains(query, cond) {
+			t.Errorf("query %q does not contain %q", query, cond)
+		}
+	}
+	want := []interface{}{users, false, "go", "rust", 10, 20}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestRepoSearchQueryInvalid(t *testing.T) {
+	for _, f := range []RepoFilter{{Sort: "last_updated"}, {Sort: "stars; DROP TABLE repositories"}, {Order: "sideways"}} {
+		if _, _, err := repoSearchQuery(nil, f); !errors.Is(err, ErrInvalidRepoFilter) {
+			t.Errorf("repoSearchQuery(%+v) error = %v, want ErrInvalidRepoFilter", f, err)
+		}
+	}
+}
+
+func TestRepoRowToModel(t *testing.T) {
+	pushed := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	m := RepoRow{Name: "devsync", PushedAt: &pushed, UpdatedAt: &updated}.toModel(uuid.New())
+	if m.UpdatedAt == nil || !m.UpdatedAt.Equal(updated) {
+		t.Errorf("UpdatedAt = %v, want %s", m.UpdatedAt, updated)
+	}
+	if m.Topics == nil {
+		t.Error("Topics = nil, want empty slice")
+	}
+}
@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"context"
+	"log"
+	"time"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/infrastructure/queue"
+)
+
+// retentionHour — час (UTC), в который ставится ночная задача rollup_stats.
+const retentionHour = 3
+
+const retentionBatch = 200
+
+// Retention обновляет агрегаты всех пользователей и удаляет сырые строки старше keepDays.
+type Retention struct {
+	repo     RollupRepository
+	keepDays int
+}
+
+func NewRetention(repo RollupRepository, keepDays int) *Retention {
+	return &Retention{repo: repo, keepDays: keepDays}
+}
+
+// Run проходит по всем пользователям; ошибка одного не останавливает остальных.
+func (r *Retention) Run(ctx context.Context) error {
+	var (
+		after   uuid.UUID
+		users   int
+		deleted int64
+		failed  int
+	)
+	cutoff := time.Now().UTC().AddDate(0, 0, -r.keepDays)
+	for {
+		ids, err := r.repo.UserIDs(ctx, after, retentionBatch)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if r.keepDays > 0 {
+				n, err := r.repo.Prune(ctx, id, cutoff)
+				deleted += n
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					log.Printf("rollup: user %s: %v", id, err)
+					failed++
+				}
+			} else if err := r.repo.Refresh(ctx, id); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Printf("rollup: user %s: %v", id, err)
+				failed++
+			}
+			users++
+		}
+		if len(ids) < retentionBatch {
+			break
+		}
+		after = ids[len(ids)-1]
+	}
+	log.Printf("rollup: %d users, %d raw rows deleted, %d failed", users, deleted, failed)
+	return nil
+}
+
+// Schedule раз в tick ставит задачу rollup_stats на ближайшие 03:00 UTC; дубль не создаётся,
+// пока предыдущая ждёт в очереди.
+func (r *Retention) Schedule(ctx context.Context, jobs queue.Queue, tick time.Duration) {
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		now := time.Now().UTC()
+		runAt := time.Date(now.Year(), now.Month(), now.Day(), retentionHour, 0, 0, 0, time.UTC)
+		if runAt.Before(now) {
+			runAt = runAt.AddDate(0, 0, 1)
+		}
+		_, err := jobs.Enqueue(ctx, queue.EnqueueParams{
+			Type:     queue.TypeRollupStats,
+			Priority: queue.PriorityLow,
+			RunAt:    runAt,
+			DedupKey: queue.TypeRollupStats,
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Printf("rollup: schedule: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
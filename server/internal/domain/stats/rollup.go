@@ -0,0 +1,298 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Гранулярности агрегатов; GranularityDay — сырые строки contributions и daily_stats.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week" // с понедельника, как date_trunc('week')
+	GranularityMonth = "month"
+	GranularityYear  = "year"
+)
+
+// RollupRepository хранит агрегаты по неделям, месяцам и годам и удаляет старые сырые строки.
+// Агрегаты пересчитываются в Rebuild вместе с днями, поэтому всегда совпадают с сырыми данными.
+type RollupRepository interface {
+	// RawSince — дата, начиная с которой сырые строки полные; nil — ничего не удалялось.
+	RawSince(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	Get(ctx context.Context, userID uuid.UUID, granularity string, from, to time.Time) ([]RollupRow, error)
+	// Refresh пересчитывает все агрегаты, которые ещё можно посчитать по сырым строкам.
+	Refresh(ctx context.Context, userID uuid.UUID) error
+	// Prune удаляет сырые строки раньше before (выравнивается до понедельника перед началом месяца),
+	// предварительно обновив агрегаты. Возвращает число удалённых строк.
+	Prune(ctx context.Context, userID uuid.UUID, before time.Time) (int64, error)
+	UserIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error)
+}
+
+type RollupRow struct {
+	Granularity   string
+	PeriodStart   string
+	Contributions int
+	Commits       int
+	PRs           int
+	Issues        int
+	StarsReceived int
+	ActiveDays    int
+}
+
+type rollupRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRollupRepository(pool *pgxpool.Pool) RollupRepository {
+	return &rollupRepo{pool: pool}
+}
+
+// dbtx — общее у пула и транзакции: агрегаты пересчитываются и в Rebuild, и отдельно.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func rawSince(ctx context.Context, db dbtx, userID uuid.UUID) (*time.Time, error) {
+	var t *time.Time
+	err := db.QueryRow(ctx, `SELECT raw_since FROM stats_retention WHERE user_id = $1`, userID).Scan(&t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *rollupRepo) RawSince(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	return rawSince(ctx, r.pool, userID)
+}
+
+func (r *rollupRepo) Get(ctx context.Context, userID uuid.UUID, granularity string, from, to time.Time) ([]RollupRow, error) {
+	rows, err := r.pool.Query(ctx, `SELECT granularity, period_start::text, contributions, commits, prs, issues, stars_received, active_days
+		FROM stats_rollups WHERE user_id = $1 AND granularity = $2 AND period_start >= $3::date AND period_start <= $4::date
+		ORDER BY period_start`, userID, granularity, from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []RollupRow
+	for rows.Next() {
+		var row RollupRow
+		if err := rows.Scan(&row.Granularity, &row.PeriodStart, &row.Contributions, &row.Commits, &row.PRs,
+			&row.Issues, &row.StarsReceived, &row.ActiveDays); err != nil {
+			return nil, err
+		}
+		out = append(out, row)
+	}
+	return out, rows.Err()
+}
+
+func (r *rollupRepo) Refresh(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := refreshRollups(ctx, tx, userID, time.Time{}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *rollupRepo) Prune(ctx context.Context, userID uuid.UUID, before time.Time) (int64, error) {
+	cutoff := alignCutoff(before)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	// Блокировка строки состояния: параллельный Rebuild того же пользователя дождётся удаления
+	if _, err := tx.Exec(ctx, `INSERT INTO stats_retention (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING`, userID); err != nil {
+		return 0, err
+	}
+	var since *time.Time
+	if err := tx.QueryRow(ctx, `SELECT raw_since FROM stats_retention WHERE user_id = $1 FOR UPDATE`, userID).Scan(&since); err != nil {
+		return 0, err
+	}
+	if since != nil && !since.Before(cutoff) {
+		return 0, nil
+	}
+	// Сначала агрегаты по ещё полным сырым данным, потом удаление
+	if err := refreshRollups(ctx, tx, userID, time.Time{}); err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, table := range []string{"contributions", "daily_stats"} {
+		tag, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1 AND date < $2::date`, userID, cutoff.Format(dateLayout))
+		if err != nil {
+			return 0, err
+		}
+		deleted += tag.RowsAffected()
+	}
+	if _, err := tx.Exec(ctx, `UPDATE stats_retention SET raw_since = $2::date, pruned_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1`, userID, cutoff.Format(dateLayout)); err != nil {
+		return 0, err
+	}
+	return deleted, tx.Commit(ctx)
+}
+
+func (r *rollupRepo) UserIDs(ctx context.Context, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	rows, err := r.pool.Query(ctx, `SELECT id FROM users WHERE id > $1 ORDER BY id LIMIT $2`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+const dateLayout = "2006-01-02"
+
+// refreshRollups пересчитывает агрегаты начиная с периода, содержащего from. Недели и месяцы
+// считаются из сырых строк, только если те полные (не раньше raw_since); годы — из месяцев.
+func refreshRollups(ctx context.Context, db dbtx, userID uuid.UUID, from time.Time) error {
+	since, err := rawSince(ctx, db, userID)
+	if err != nil {
+		return err
+	}
+	for _, g := range []string{GranularityWeek, GranularityMonth} {
+		lower := truncateTo(g, from)
+		if since != nil && lower.Before(*since) {
+			lower = ceilTo(g, *since)
+		}
+		if _, err := db.Exec(ctx, `DELETE FROM stats_rollups WHERE user_id = $1 AND granularity = $2 AND period_start >= $3::date`,
+			userID, g, lower.Format(dateLayout)); err != nil {
+			return err
+		}
+		_, err := db.Exec(ctx, `INSERT INTO stats_rollups (user_id, granularity, period_start, contributions, commits, prs, issues, stars_received, active_days)
+			SELECT $1, $2::text, date_trunc($2::text, d.date::timestamp)::date AS bucket, SUM(d.contributions), SUM(d.commits), SUM(d.prs),
+				SUM(d.issues), SUM(d.stars_received), COUNT(*) FILTER (WHERE d.contributions > 0)
+			FROM (
+				SELECT COALESCE(c.date, s.date) AS date, COALESCE(c.cnt, 0) AS contributions, COALESCE(s.commits, 0) AS commits,
+					COALESCE(s.prs, 0) AS prs, COALESCE(s.issues, 0) AS issues, COALESCE(s.stars_received, 0) AS stars_received
+				FROM (SELECT date, SUM(count) AS cnt FROM contributions WHERE user_id = $1 AND date >= $3::date GROUP BY date) c
+				FULL JOIN (SELECT date, commits, prs, issues, stars_received FROM daily_stats WHERE user_id = $1 AND date >= $3::date) s
+					ON s.date = c.date
+			) d
+			GROUP BY bucket`, userID, g, lower.Format(dateLayout))
+		if err != nil {
+			return err
+		}
+	}
+	yearFrom := truncateTo(GranularityYear, from).Format(dateLayout)
+	if _, err := db.Exec(ctx, `DELETE FROM stats_rollups WHERE user_id = $1 AND granularity = 'year' AND period_start >= $2::date`,
+		userID, yearFrom); err != nil {
+		return err
+	}
+	_, err = db.Exec(ctx, `INSERT INTO stats_rollups (user_id, granularity, period_start, contributions, commits, prs, issues, stars_received, active_days)
+		SELECT $1, 'year', date_trunc('year', period_start::timestamp)::date, SUM(contributions), SUM(commits), SUM(prs),
+			SUM(issues), SUM(stars_received), SUM(active_days)
+		FROM stats_rollups WHERE user_id = $1 AND granularity = 'month' AND period_start >= $2::date
+		GROUP BY 3`, userID, yearFrom)
+	return err
+}
+
+// widenRebuild — Rebuild раньше raw_since восстанавливает удалённые сырые строки; начало
+// расширяется до границы, с которой снова полны и недели, и месяцы, и она становится новым raw_since.
+func widenRebuild(ctx context.Context, tx pgx.Tx, userID uuid.UUID, from time.Time) (time.Time, error) {
+	var since *time.Time
+	err := tx.QueryRow(ctx, `SELECT raw_since FROM stats_retention WHERE user_id = $1 FOR UPDATE`, userID).Scan(&since)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && (since == nil || !from.Before(*since))) {
+		return from, nil
+	}
+	if err != nil {
+		return from, err
+	}
+	from = alignCutoff(from)
+	_, err = tx.Exec(ctx, `UPDATE stats_retention SET raw_since = $2::date, updated_at = NOW() WHERE user_id = $1`,
+		userID, from.Format(dateLayout))
+	return from, err
+}
+
+// alignCutoff — понедельник не позже первого числа месяца d: с него полны и недели, и месяцы.
+func alignCutoff(d time.Time) time.Time {
+	return truncateTo(GranularityWeek, truncateTo(GranularityMonth, d))
+}
+
+func truncateTo(g string, d time.Time) time.Time {
+	d = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+	switch g {
+	case GranularityWeek:
+		return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+	case GranularityMonth:
+		return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case GranularityYear:
+		return time.Date(d.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return d
+}
+
+// ceilTo — начало первого периода, который начинается не раньше d.
+func ceilTo(g string, d time.Time) time.Time {
+	t := truncateTo(g, d)
+	if t.Equal(truncateTo(GranularityDay, d)) {
+		return t
+	}
+	return nextPeriod(g, t)
+}
+
+func nextPeriod(g string, start time.Time) time.Time {
+	switch g {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityMonth:
+		return start.AddDate(0, 1, 0)
+	case GranularityYear:
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+type segment struct {
+	Granularity string
+	From, To    time.Time // включительно
+}
+
+// planRange разбивает [from, to] на самые крупные целые периоды, для которых есть агрегаты;
+// остаток — сырые дни. Дни раньше since уже удалены и в сумму не попадают: там точность — неделя.
+func planRange(from, to time.Time, since *time.Time) []segment {
+	return planGrains(truncateTo(GranularityDay, from), truncateTo(GranularityDay, to), since,
+		[]string{GranularityYear, GranularityMonth, GranularityWeek}, nil)
+}
+
+// planGrains: целые периоды первой гранулярности в середине, края — рекурсивно более мелкими.
+func planGrains(from, to time.Time, since *time.Time, grains []string, out []segment) []segment {
+	if from.After(to) {
+		return out
+	}
+	if len(grains) == 0 {
+		if since != nil && from.Before(*since) {
+			from = *since
+		}
+		if !from.After(to) {
+			out = append(out, segment{Granularity: GranularityDay, From: from, To: to})
+		}
+		return out
+	}
+	g := grains[0]
+	start := ceilTo(g, from)
+	end := truncateTo(g, to.AddDate(0, 0, 1)) // начало первого неполного периода после to
+	if !start.Before(end) {
+		return planGrains(from, to, since, grains[1:], out)
+	}
+	out = planGrains(from, start.AddDate(0, 0, -1), since, grains[1:], out)
+	for p := start; p.Before(end); p = nextPeriod(g, p) {
+		out = append(out, segment{Granularity: g, From: p, To: nextPeriod(g, p).AddDate(0, 0, -1)})
+	}
+	return planGrains(end, to, since, grains[1:], out)
+}
@@ -2,97 +2,365 @@ package stats
 
 import (
 	"context"
+	"fmt"
 	"time"
 	"github.com/google/uuid"
 	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/user"
 )
 
 type Service interface {
 	GetUserStats(ctx context.Context, userID uuid.UUID) (*models.UserStats, error)
-	GetUserStatsWithPeriod(ctx context.Context, userID uuid.UUID, period string) (*models.UserStats, error) // period: week, month, year
-	GetContributions(ctx context.Context, userID uuid.UUID, from, to string) ([]models.ContributionDay, error)
-	GetRepos(ctx context.Context, userID uuid.UUID, limit int) ([]models.Repo, error)
+	GetUserStatsWithPeriod(ctx context.Context, userID uuid.UUID, q PeriodQuery) (*models.UserStats, error) // ErrInvalidPeriod — неверный период
+	GetContributions(ctx context.Context, userID uuid.UUID, from, to string) ([]models.ContributionDay, error) // пустые from/to — последний год в поясе пользователя; ErrInvalidPeriod
+	GetRepos(ctx context.Context, userID uuid.UUID, f RepoFilter) ([]models.Repo, error)
+	// GetGroupRepos — репозитории нескольких пользователей (команды) без повторов.
+	GetGroupRepos(ctx context.Context, userIDs []uuid.UUID, f RepoFilter) ([]models.Repo, error)
+	// RebuildActivity пересчитывает дни после смены часового пояса (задача worker rebuild_activity).
+	RebuildActivity(ctx context.Context, userID uuid.UUID) error
+	// ComparePeriods — суммы и доли языков за период и за период сравнения (compare: previous, same_period_last_year).
+	ComparePeriods(ctx context.Context, userID uuid.UUID, q PeriodQuery, compare string) (*models.PeriodComparison, error)
+	// GetPunchcard — матрица 7×24 вкладов за период в поясе пользователя.
+	GetPunchcard(ctx context.Context, userID uuid.UUID, q PeriodQuery) (*models.Punchcard, error)
+	GetStreaks(ctx context.Context, userID uuid.UUID) (*models.Streaks, error)
+	StreakSettings(ctx context.Context, userID uuid.UUID) (*StreakSettings, error)
+	UpdateStreakSettings(ctx context.Context, userID uuid.UUID, set StreakSettings) (*StreakSettings, error)
+	RestDays(ctx context.Context, userID uuid.UUID) ([]RestDay, error)
+	AddRestDays(ctx context.Context, userID uuid.UUID, from, to, note string) error // from/to — YYYY-MM-DD включительно
+	DeleteRestDays(ctx context.Context, userID uuid.UUID, from, to string) (int64, error)
 }
 
 type service struct {
 	repoRepo   RepoRepository
 	contribRepo ContributionRepository
 	dailyRepo   DailyStatsRepository
+	activityRepo ActivityRepository
+	depRepo     DependencyRepository
+	rollupRepo  RollupRepository
+	streakRepo  StreakRepository
+	userSvc     user.Service
 }
 
 func NewService(
 	repoRepo RepoRepository,
 	contribRepo ContributionRepository,
 	dailyRepo DailyStatsRepository,
+	activityRepo ActivityRepository,
+	depRepo DependencyRepository,
+	rollupRepo RollupRepository,
+	streakRepo StreakRepository,
+	userSvc user.Service,
 ) Service {
 	return &service{
 		repoRepo:    repoRepo,
 		contribRepo: contribRepo,
 		dailyRepo:   dailyRepo,
+		activityRepo: activityRepo,
+		depRepo:     depRepo,
+		rollupRepo:  rollupRepo,
+		streakRepo:  streakRepo,
+		userSvc:     userSvc,
 	}
 }
 
 func (s *service) GetUserStats(ctx context.Context, userID uuid.UUID) (*models.UserStats, error) {
-	return s.GetUserStatsWithPeriod(ctx, userID, "year")
+	return s.GetUserStatsWithPeriod(ctx, userID, PeriodQuery{Period: "year"})
 }
 
-func daysForPeriod(period string) int {
-	switch period {
-	case "week":
-		return 7
-	case "month":
-		return 30
-	case "year":
-		return 365
-	default:
-		return 365
+func (s *service) GetUserStatsWithPeriod(ctx context.Context, userID uuid.UUID, q PeriodQuery) (*models.UserStats, error) {
+	loc, err := s.location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.period(ctx, userID, loc, q)
+	if err != nil {
+		return nil, err
 	}
-}
-
-func (s *service) GetUserStatsWithPeriod(ctx context.Context, userID uuid.UUID, period string) (*models.UserStats, error) {
-	to := time.Now().Format("2006-01-02")
-	days := daysForPeriod(period)
-	from := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
 
 	repos, err := s.repoRepo.ListByUser(ctx, userID, 100)
 	if err != nil {
 		return nil, err
 	}
-	contribs, err := s.contribRepo.GetByUserDateRange(ctx, userID, from, to)
+	contribs, daily, totals, err := s.loadRange(ctx, userID, p.From, p.To)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := s.depRepo.TopByUser(ctx, userID, 200)
+	if err != nil {
+		return nil, err
+	}
+	streaks, err := s.streaks(ctx, userID, loc)
 	if err != nil {
 		return nil, err
 	}
-	daily, err := s.dailyRepo.GetByUserDateRange(ctx, userID, from, to)
+	pattern, err := s.workPatternFor(ctx, userID, loc, p)
 	if err != nil {
 		return nil, err
 	}
-	return BuildUserStats(repos, contribs, daily, userID), nil
+	st := BuildUserStats(repos, contribs, daily, userID)
+	st.Period = p.Range()
+	st.ContributionSum = totals.Contributions
+	st.Totals = totals
+	st.Streaks = *streaks
+	st.WorkPattern = pattern
+	st.TechStack = BuildTechStack(deps, techStackLimit)
+	return st, nil
 }
 
+// techStackLimit — сколько фреймворков и библиотек показывать в статистике и отчётах.
+const techStackLimit = 15
+
 func (s *service) GetContributions(ctx context.Context, userID uuid.UUID, from, to string) ([]models.ContributionDay, error) {
-	rows, err := s.contribRepo.GetByUserDateRange(ctx, userID, from, to)
+	loc, err := s.location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(loc)
+	if from == "" {
+		from = now.AddDate(0, 0, -365).Format(dateLayout)
+	}
+	// Те же проверки, что у периодов статистики: неверные даты — ErrInvalidPeriod
+	p, err := ResolvePeriod(PeriodQuery{From: from, To: to}, now)
+	if err != nil {
+		return nil, err
+	}
+	fromDay, toDay := p.From, p.To
+	rows, _, _, err := s.loadRange(ctx, userID, fromDay, toDay)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]models.ContributionDay, 0, len(rows))
 	for _, r := range rows {
-		out = append(out, models.ContributionDay{Date: r.Date, Count: r.Count})
+		out = append(out, models.ContributionDay{Date: r.Date, Count: r.Count, Granularity: r.Granularity})
 	}
 	return out, nil
 }
 
-func (s *service) GetRepos(ctx context.Context, userID uuid.UUID, limit int) ([]models.Repo, error) {
-	rows, err := s.repoRepo.ListByUser(ctx, userID, limit)
+// loadRange — ряды по дням и суммы за [from, to]. Ряды берутся из сырых строк, а там, где они уже
+// удалены, — из недельных агрегатов; суммы — из самых крупных целых периодов (planRange).
+func (s *service) loadRange(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]ContributionRow, []DailyStatsRow, models.PeriodTotals, error) {
+	var totals models.PeriodTotals
+	from, to = truncateTo(GranularityDay, from), truncateTo(GranularityDay, to)
+	since, err := s.rollupRepo.RawSince(ctx, userID)
+	if err != nil {
+		return nil, nil, totals, err
+	}
+	var contribs []ContributionRow
+	var daily []DailyStatsRow
+	rawFrom := from
+	if since != nil && from.Before(*since) {
+		weeks, err := s.rollupRepo.Get(ctx, userID, GranularityWeek, truncateTo(GranularityWeek, from), since.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, nil, totals, err
+		}
+		for _, w := range weeks {
+			contribs = append(contribs, ContributionRow{Date: w.PeriodStart, Count: w.Contributions, Granularity: GranularityWeek})
+			daily = append(daily, DailyStatsRow{UserID: userID, Date: w.PeriodStart, Commits: w.Commits, PRs: w.PRs,
+				Issues: w.Issues, StarsReceived: w.StarsReceived, Granularity: GranularityWeek})
+		}
+		rawFrom = *since
+	}
+	rawContribs, err := s.contribRepo.GetByUserDateRange(ctx, userID, rawFrom.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, nil, totals, err
+	}
+	rawDaily, err := s.dailyRepo.GetByUserDateRange(ctx, userID, rawFrom.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return nil, nil, totals, err
+	}
+	contribs = append(contribs, rawContribs...)
+	daily = append(daily, rawDaily...)
+
+	// Суммы: целые годы, месяцы и недели — из агрегатов (по запросу на гранулярность), края — из уже загруженных дней
+	wanted := make(map[string]map[string]bool)
+	bounds := make(map[string][2]time.Time)
+	for _, seg := range planRange(from, to, since) {
+		if seg.Granularity == GranularityDay {
+			lo, hi := seg.From.Format(dateLayout), seg.To.Format(dateLayout)
+			for _, c := range rawContribs {
+				if c.Date >= lo && c.Date <= hi {
+					totals.Contributions += c.Count
+					if c.Count > 0 {
+						totals.ActiveDays++
+					}
+				}
+			}
+			for _, d := range rawDaily {
+				if d.Date >= lo && d.Date <= hi {
+					totals.Commits += d.Commits
+					totals.PRs += d.PRs
+					totals.Issues += d.Issues
+					totals.StarsReceived += d.StarsReceived
+				}
+			}
+			continue
+		}
+		if wanted[seg.Granularity] == nil {
+			wanted[seg.Granularity] = make(map[string]bool)
+			bounds[seg.Granularity] = [2]time.Time{seg.From, seg.From}
+		}
+		wanted[seg.Granularity][seg.From.Format(dateLayout)] = true
+		bounds[seg.Granularity] = [2]time.Time{bounds[seg.Granularity][0], seg.From}
+	}
+	for g, starts := range wanted {
+		rows, err := s.rollupRepo.Get(ctx, userID, g, bounds[g][0], bounds[g][1])
+		if err != nil {
+			return nil, nil, totals, err
+		}
+		for _, r := range rows {
+			if !starts[r.PeriodStart] {
+				continue
+			}
+			totals.Contributions += r.Contributions
+			totals.Commits += r.Commits
+			totals.PRs += r.PRs
+			totals.Issues += r.Issues
+			totals.StarsReceived += r.StarsReceived
+			totals.ActiveDays += r.ActiveDays
+		}
+	}
+	return contribs, daily, totals, nil
+}
+
+func (s *service) GetRepos(ctx context.Context, userID uuid.UUID, f RepoFilter) ([]models.Repo, error) {
+	return s.GetGroupRepos(ctx, []uuid.UUID{userID}, f)
+}
+
+func (s *service) GetGroupRepos(ctx context.Context, userIDs []uuid.UUID, f RepoFilter) ([]models.Repo, error) {
+	rows, err := s.repoRepo.Search(ctx, userIDs, f)
 	if err != nil {
 		return nil, err
 	}
 	out := make([]models.Repo, 0, len(rows))
 	for _, r := range rows {
-		out = append(out, models.Repo{
-			UserID: userID, GitHubID: r.GitHubID, Name: r.Name, FullName: r.FullName,
-			Description: r.Description, Stars: r.Stars, Forks: r.Forks,
-			Language: r.Language, IsPrivate: r.IsPrivate,
-		})
+		out = append(out, r.toModel(r.UserID))
 	}
 	return out, nil
 }
+
+// RebuildActivity пересчитывает дни только с первого сохранённого события: более ранние строки
+// contributions появились до activity_events, пересчитать их не из чего — они остаются как есть.
+func (s *service) RebuildActivity(ctx context.Context, userID uuid.UUID) error {
+	loc, err := s.location(ctx, userID)
+	if err != nil {
+		return err
+	}
+	first, err := s.activityRepo.FirstEvent(ctx, userID)
+	if err != nil || first == nil {
+		return err
+	}
+	return s.activityRepo.Rebuild(ctx, userID, loc, *first)
+}
+
+func (s *service) GetStreaks(ctx context.Context, userID uuid.UUID) (*models.Streaks, error) {
+	loc, err := s.location(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.streaks(ctx, userID, loc)
+}
+
+// streaks — серии за всю историю, не только за выбранный период.
+func (s *service) streaks(ctx context.Context, userID uuid.UUID, loc *time.Location) (*models.Streaks, error) {
+	set, err := s.streakRepo.Settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	rest, err := s.streakRepo.RestDays(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.activityRepo.ActiveDays(ctx, userID, loc)
+	if err != nil {
+		return nil, err
+	}
+	rules := StreakRules{RestWeekends: set.RestWeekends, MinLength: set.MinLength, RestDays: make(map[string]bool, len(rest))}
+	for _, d := range rest {
+		rules.RestDays[d.Date] = true
+	}
+	st := ComputeStreaks(days, time.Now().In(loc), rules)
+	return &st, nil
+}
+
+func (s *service) StreakSettings(ctx context.Context, userID uuid.UUID) (*StreakSettings, error) {
+	set, err := s.streakRepo.Settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (s *service) UpdateStreakSettings(ctx context.Context, userID uuid.UUID, set StreakSettings) (*StreakSettings, error) {
+	if set.MinLength < 1 || set.MinLength > MaxStreakMinLength {
+		return nil, ErrInvalidStreakSettings
+	}
+	if err := s.streakRepo.SaveSettings(ctx, userID, set); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (s *service) RestDays(ctx context.Context, userID uuid.UUID) ([]RestDay, error) {
+	return s.streakRepo.RestDays(ctx, userID)
+}
+
+func (s *service) AddRestDays(ctx context.Context, userID uuid.UUID, from, to, note string) error {
+	f, t, err := parseRestRange(from, to)
+	if err != nil {
+		return err
+	}
+	return s.streakRepo.AddRestDays(ctx, userID, f, t, note)
+}
+
+func (s *service) DeleteRestDays(ctx context.Context, userID uuid.UUID, from, to string) (int64, error) {
+	f, t, err := parseRestRange(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return s.streakRepo.DeleteRestDays(ctx, userID, f, t)
+}
+
+// parseRestRange — диапазон дат YYYY-MM-DD; пустой to — один день from.
+func parseRestRange(from, to string) (time.Time, time.Time, error) {
+	if to == "" {
+		to = from
+	}
+	f, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return f, f, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidRestDays)
+	}
+	t, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return f, t, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidRestDays)
+	}
+	if t.Before(f) || t.Sub(f) >= MaxRestDaysRange*24*time.Hour {
+		return f, t, fmt.Errorf("%w: to must be within %d days after from", ErrInvalidRestDays, MaxRestDaysRange)
+	}
+	return f, t, nil
+}
+
+// period разрешает q на сегодня в поясе пользователя; у all начало — первый день с вкладами.
+func (s *service) period(ctx context.Context, userID uuid.UUID, loc *time.Location, q PeriodQuery) (Period, error) {
+	p, err := ResolvePeriod(q, time.Now().In(loc))
+	if err != nil || p.Name != PeriodAll {
+		return p, err
+	}
+	first, err := s.activityRepo.FirstDay(ctx, userID, loc)
+	if err != nil {
+		return p, err
+	}
+	p.From = p.To
+	if first != nil {
+		p.From = truncateTo(GranularityDay, *first)
+	}
+	return p, nil
+}
+
+// location — часовой пояс пользователя: границы периодов и «сегодня» считаются в нём.
+func (s *service) location(ctx context.Context, userID uuid.UUID) (*time.Location, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.Location(), nil
+}
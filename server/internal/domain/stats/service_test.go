@@ -0,0 +1,37 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+// stubUsers отдаёт пользователя с поясом timezone; остальные методы user.Service не нужны.
+type stubUsers struct {
+	user.Service
+	timezone string
+}
+
+func (s stubUsers) GetByID(ctx context.Context, id uuid.UUID) (*user.User, error) {
+	return &user.User{ID: id, Timezone: s.timezone}, nil
+}
+
+func TestGetContributionsRejectsInvalidRange(t *testing.T) {
+	svc := NewService(nil, nil, nil, nil, nil, nil, nil, stubUsers{timezone: "Europe/Moscow"})
+	for _, tc := range []struct{ from, to string }{
+		{"2026-13-01", ""},
+		{"yesterday", "2026-10-01"},
+		{"2026-09-01", "01.10.2026"},
+		{"2026-09-10", "2026-09-01"},
+		{"2999-01-01", ""},
+		// без from — последний год, и to раньше его начала
+		{"", "2000-01-01"},
+	} {
+		_, err := svc.GetContributions(context.Background(), uuid.New(), tc.from, tc.to)
+		if !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("GetContributions(%q, %q) error = %v, want ErrInvalidPeriod", tc.from, tc.to, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package stats
+
+import (
+	"context"
+	"errors"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+const (
+	DefaultStreakMinLength = 3
+	MaxStreakMinLength     = 365
+	// MaxRestDaysRange — сколько дней отдыха можно объявить одним запросом.
+	MaxRestDaysRange = 366
+)
+
+var (
+	ErrInvalidStreakSettings = errors.New("min_length must be between 1 and 365")
+	ErrInvalidRestDays       = errors.New("invalid rest days range")
+)
+
+type StreakSettings struct {
+	RestWeekends bool `json:"rest_weekends"`
+	MinLength    int  `json:"min_length"`
+}
+
+type RestDay struct {
+	Date string  `json:"date"`
+	Note *string `json:"note,omitempty"`
+}
+
+// StreakRepository — настройки серий и объявленные дни отдыха.
+type StreakRepository interface {
+	Settings(ctx context.Context, userID uuid.UUID) (StreakSettings, error)
+	SaveSettings(ctx context.Context, userID uuid.UUID, s StreakSettings) error
+	RestDays(ctx context.Context, userID uuid.UUID) ([]RestDay, error)
+	// AddRestDays объявляет днями отдыха даты с from по to включительно.
+	AddRestDays(ctx context.Context, userID uuid.UUID, from, to time.Time, note string) error
+	DeleteRestDays(ctx context.Context, userID uuid.UUID, from, to time.Time) (int64, error)
+}
+
+type streakRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewStreakRepository(pool *pgxpool.Pool) StreakRepository {
+	return &streakRepo{pool: pool}
+}
+
+func (r *streakRepo) Settings(ctx context.Context, userID uuid.UUID) (StreakSettings, error) {
+	s := StreakSettings{MinLength: DefaultStreakMinLength}
+	err := r.pool.QueryRow(ctx, `SELECT rest_weekends, min_length FROM streak_settings WHERE user_id = $1`, userID).
+		Scan(&s.RestWeekends, &s.MinLength)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, nil
+	}
+	return s, err
+}
+
+func (r *streakRepo) SaveSettings(ctx context.Context, userID uuid.UUID, s StreakSettings) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO streak_settings (user_id, rest_weekends, min_length) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET rest_weekends = EXCLUDED.rest_weekends, min_length = EXCLUDED.min_length, updated_at = NOW()`,
+		userID, s.RestWeekends, s.MinLength)
+	return err
+}
+
+func (r *streakRepo) RestDays(ctx context.Context, userID uuid.UUID) ([]RestDay, error) {
+	rows, err := r.pool.Query(ctx, `SELECT day::text, note FROM rest_days WHERE user_id = $1 ORDER BY day`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []RestDay{}
+	for rows.Next() {
+		var d RestDay
+		if err := rows.Scan(&d.Date, &d.Note); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *streakRepo) AddRestDays(ctx context.Context, userID uuid.UUID, from, to time.Time, note string) error {
+	_, err := r.pool.Exec(ctx, `INSERT INTO rest_days (user_id, day, note)
+		SELECT $1, d::date, NULLIF($4, '') FROM generate_series($2::date, $3::date, INTERVAL '1 day') AS d
+		ON CONFLICT (user_id, day) DO UPDATE SET note = EXCLUDED.note`,
+		userID, from.Format(dateLayout), to.Format(dateLayout), note)
+	return err
+}
+
+func (r *streakRepo) DeleteRestDays(ctx context.Context, userID uuid.UUID, from, to time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM rest_days WHERE user_id = $1 AND day >= $2::date AND day <= $3::date`,
+		userID, from.Format(dateLayout), to.Format(dateLayout))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// StreakRules — что считать днём отдыха при подсчёте серий.
+type StreakRules struct {
+	RestWeekends bool
+	RestDays     map[string]bool // даты YYYY-MM-DD
+	MinLength    int
+}
+
+func (r StreakRules) isRest(d time.Time) bool {
+	if r.RestWeekends && (d.Weekday() == time.Saturday || d.Weekday() == time.Sunday) {
+		return true
+	}
+	return r.RestDays[d.Format(dateLayout)]
+}
+
+// bridged — все дни строго между a и b — дни отдыха.
+func (r StreakRules) bridged(a, b time.Time) bool {
+	for d := a.AddDate(0, 0, 1); d.Before(b); d = d.AddDate(0, 0, 1) {
+		if !r.isRest(d) {
+			return false
+		}
+	}
+	return true
+}
+
+// ComputeStreaks считает серии по отсортированным датам с вкладами (YYYY-MM-DD, пояс пользователя).
+// today — сегодняшняя дата пользователя: пока день не кончился, отсутствие вкладов сегодня серию не прерывает.
+func ComputeStreaks(active []string, today time.Time, rules StreakRules) models.Streaks {
+	if rules.MinLength < 1 {
+		rules.MinLength = DefaultStreakMinLength
+	}
+	out := models.Streaks{History: []models.Streak{}, MinLength: rules.MinLength, RestWeekends: rules.RestWeekends}
+	today = truncateTo(GranularityDay, today)
+
+	var all []models.Streak
+	var start, end time.Time
+	length := 0
+	closeStreak := func() {
+		if length > 0 {
+			all = append(all, models.Streak{
+				Start: start.Format(dateLayout), End: end.Format(dateLayout),
+				Length: length, Days: int(end.Sub(start).Hours()/24) + 1,
+			})
+		}
+	}
+	for _, s := range active {
+		d, err := time.Parse(dateLayout, s)
+		if err != nil || d.After(today) {
+			continue
+		}
+		if length > 0 && !d.After(end) {
+			continue // дубли
+		}
+		if length > 0 && rules.bridged(end, d) {
+			end = d
+			length++
+			continue
+		}
+		closeStreak()
+		start, end, length = d, d, 1
+	}
+	closeStreak()
+
+	for i := len(all) - 1; i >= 0; i-- {
+		st := all[i]
+		if st.Length > out.Longest.Length {
+			out.Longest = st
+		}
+		if st.Length >= rules.MinLength {
+			out.History = append(out.History, st)
+		}
+	}
+	if n := len(all); n > 0 {
+		last, _ := time.Parse(dateLayout, all[n-1].End)
+		if last.Equal(today) || rules.bridged(last, today) {
+			out.Current = all[n-1]
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package stats
+
+import (
+	"testing"
+	"time"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+func day(s string) time.Time {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestComputeStreaks(t *testing.T) {
+	tests := []struct {
+		name    string
+		active  []string
+		today   string
+		rules   StreakRules
+		current models.Streak
+		longest models.Streak
+		history int
+	}{
+		{
+			name:  "no activity",
+			today: "2026-10-14",
+		},
+		{
+			name:    "ends today",
+			active:  []string{"2026-10-12", "2026-10-13", "2026-10-14"},
+			today:   "2026-10-14",
+			current: models.Streak{Start: "2026-10-12", End: "2026-10-14", Length: 3, Days: 3},
+			longest: models.Streak{Start: "2026-10-12", End: "2026-10-14", Length: 3, Days: 3},
+			history: 1,
+		},
+		{
+			name:    "today is not over yet",
+			active:  []string{"2026-10-12", "2026-10-13"},
+			today:   "2026-10-14",
+			current: models.Streak{Start: "2026-10-12", End: "2026-10-13", Length: 2, Days: 2},
+			longest: models.Streak{Start: "2026-10-12", End: "2026-10-13", Length: 2, Days: 2},
+		},
+		{
+			name:    "missed day breaks the current streak",
+			active:  []string{"2026-10-11", "2026-10-12"},
+			today:   "2026-10-14",
+			longest: models.Streak{Start: "2026-10-11", End: "2026-10-12", Length: 2, Days: 2},
+		},
+		{
+			name:    "weekend breaks without rest weekends",
+			active:  []string{"2026-10-08", "2026-10-09", "2026-10-12"},
+			today:   "2026-10-12",
+			current: models.Streak{Start: "2026-10-12", End: "2026-10-12", Length: 1, Days: 1},
+			longest: models.Streak{Start: "2026-10-08", End: "2026-10-09", Length: 2, Days: 2},
+		},
+		{
+			name:    "rest weekends bridge Friday to Monday",
+			active:  []string{"2026-10-08", "2026-10-09", "2026-10-12"},
+			today:   "2026-10-12",
+			rules:   StreakRules{RestWeekends: true},
+			current: models.Streak{Start: "2026-10-08", End: "2026-10-12", Length: 3, Days: 5},
+			longest: models.Streak{Start: "2026-10-08", End: "2026-10-12", Length: 3, Days: 5},
+			history: 1,
+		},
+		{
+			name:    "declared rest days bridge a gap and keep the streak alive",
+			active:  []string{"2026-10-05", "2026-10-06", "2026-10-07"},
+			today:   "2026-10-09",
+			rules:   StreakRules{RestDays: map[string]bool{"2026-10-08": true}},
+			current: models.Streak{Start: "2026-10-05", End: "2026-10-07", Length: 3, Days: 3},
+			longest: models.Streak{Start: "2026-10-05", End: "2026-10-07", Length: 3, Days: 3},
+			history: 1,
+		},
+		{
+			name:    "duplicates and future days are ignored",
+			active:  []string{"2026-10-13", "2026-10-13", "2026-10-14", "2026-10-20"},
+			today:   "2026-10-14",
+			current: models.Streak{Start: "2026-10-13", End: "2026-10-14", Length: 2, Days: 2},
+			longest: models.Streak{Start: "2026-10-13", End: "2026-10-14", Length: 2, Days: 2},
+		},
+		{
+			name:    "history respects min length",
+			active:  []string{"2026-10-01", "2026-10-02", "2026-10-05", "2026-10-06", "2026-10-07"},
+			today:   "2026-10-14",
+			rules:   StreakRules{MinLength: 2},
+			longest: models.Streak{Start: "2026-10-05", End: "2026-10-07", Length: 3, Days: 3},
+			history: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeStreaks(tt.active, day(tt.today), tt.rules)
+			if got.Current != tt.current {
+				t.Errorf("current = %+v, want %+v", got.Current, tt.current)
+			}
+			if got.Longest != tt.longest {
+				t.Errorf("longest = %+v, want %+v", got.Longest, tt.longest)
+			}
+			if len(got.History) != tt.history {
+				t.Errorf("history = %+v, want %d streaks", got.History, tt.history)
+			}
+		})
+	}
+}
+
+func TestComputeStreaksHistoryNewestFirst(t *testing.T) {
+	active := []string{"2026-09-01", "2026-09-02", "2026-09-03", "2026-09-10", "2026-09-11", "2026-09-12"}
+	got := ComputeStreaks(active, day("2026-10-14"), StreakRules{})
+	if len(got.History) != 2 || got.History[0].Start != "2026-09-10" || got.History[1].Start != "2026-09-01" {
+		t.Fatalf("history = %+v, want the 09-10 streak before the 09-01 one", got.History)
+	}
+	// при равной длине самой длинной считается более новая серия
+	if got.Longest.Start != "2026-09-10" {
+		t.Errorf("longest = %+v, want the newer streak", got.Longest)
+	}
+	if got.MinLength != DefaultStreakMinLength {
+		t.Errorf("min length = %d, want default %d", got.MinLength, DefaultStreakMinLength)
+	}
+}
@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+// knownFrameworks — зависимости, которые считаем фреймворками (остальное — библиотеки).
+// Ключ: ecosystem + ":" + имя пакета (для go и maven — префикс модуля).
+var knownFrameworks = map[string]string{
+	"npm:react":                        "React",
+	"npm:next":                         "Next.js",
+	"npm:vue":                          "Vue",
+	"npm:nuxt":                         "Nuxt",
+	"npm:svelte":                       "Svelte",
+	"npm:@sveltejs/kit":                "SvelteKit",
+	"npm:@angular/core":                "Angular",
+	"npm:express":                      "Express",
+	"npm:@nestjs/core":                 "NestJS",
+	"npm:fastify":                      "Fastify",
+	"npm:koa":                          "Koa",
+	"npm:electron":                     "Electron",
+	"npm:react-native":                 "React Native",
+	"npm:vite":                         "Vite",
+	"npm:tailwindcss":                  "Tailwind CSS",
+	"go:github.com/gin-gonic/gin":      "Gin",
+	"go:github.com/labstack/echo":      "Echo",
+	"go:github.com/gofiber/fiber":      "Fiber",
+	"go:github.com/go-chi/chi":         "chi",
+	"go:github.com/gorilla/mux":        "Gorilla Mux",
+	"go:google.golang.org/grpc":        "gRPC",
+	"go:gorm.io/gorm":                  "GORM",
+	"go:github.com/spf13/cobra":        "Cobra",
+	"pypi:django":                      "Django",
+	"pypi:flask":                       "Flask",
+	"pypi:fastapi":                     "FastAPI",
+	"pypi:tornado":                     "Tornado",
+	"pypi:aiohttp":                     "aiohttp",
+	"pypi:pytorch":                     "PyTorch",
+	"pypi:torch":                       "PyTorch",
+	"pypi:tensorflow":                  "TensorFlow",
+	"pypi:scikit-learn":                "scikit-learn",
+	"pypi:pandas":                      "pandas",
+	"pypi:sqlalchemy":                  "SQLAlchemy",
+	"cargo:actix-web":                  "Actix Web",
+	"cargo:axum":                       "Axum",
+	"cargo:rocket":                     "Rocket",
+	"cargo:tokio":                      "Tokio",
+	"cargo:bevy":                       "Bevy",
+	"cargo:tauri":                      "Tauri",
+	"maven:org.springframework.boot":   "Spring Boot",
+	"maven:org.springframework":        "Spring",
+	"maven:io.quarkus":                 "Quarkus",
+	"maven:io.micronaut":               "Micronaut",
+	"maven:org.hibernate":              "Hibernate",
+}
+
+// frameworkName возвращает человекочитаемое имя фреймворка или "", если это обычная библиотека.
+func frameworkName(ecosystem, name string) string {
+	key := ecosystem + ":" + strings.ToLower(name)
+	if n, ok := knownFrameworks[key]; ok {
+		return n
+	}
+	switch ecosystem {
+	case "go":
+		// github.com/labstack/echo/v4 → github.com/labstack/echo
+		for prefix, n := range knownFrameworks {
+			if strings.HasPrefix(key, prefix+"/") {
+				return n
+			}
+		}
+	case "maven":
+		// org.springframework.boot:spring-boot-starter-web → по groupId
+		group, _, _ := strings.Cut(strings.ToLower(name), ":")
+		for group != "" {
+			if n, ok := knownFrameworks["maven:"+group]; ok {
+				return n
+			}
+			i := strings.LastIndex(group, ".")
+			if i < 0 {
+				break
+			}
+			group = group[:i]
+		}
+	}
+	return ""
+}
+
+// BuildTechStack раскладывает зависимости на фреймворки и библиотеки. Один фреймворк
+// из нескольких пакетов (Spring Boot starters) считается по максимальному числу репозиториев.
+func BuildTechStack(usages []DependencyUsage, limit int) models.TechStack {
+	stack := models.TechStack{Frameworks: []models.TechItem{}, Libraries: []models.TechItem{}}
+	frameworkIdx := make(map[string]int)
+	for _, u := range usages {
+		if fw := frameworkName(u.Ecosystem, u.Name); fw != "" {
+			if i, ok := frameworkIdx[fw]; ok {
+				if u.Repos > stack.Frameworks[i].Repos {
+					stack.Frameworks[i].Repos = u.Repos
+				}
+				continue
+			}
+			frameworkIdx[fw] = len(stack.Frameworks)
+			stack.Frameworks = append(stack.Frameworks, models.TechItem{Name: fw, Ecosystem: u.Ecosystem, Repos: u.Repos})
+			continue
+		}
+		if u.DevOnly || len(stack.Libraries) >= limit {
+			continue
+		}
+		stack.Libraries = append(stack.Libraries, models.TechItem{Name: u.Name, Ecosystem: u.Ecosystem, Repos: u.Repos})
+	}
+	sort.SliceStable(stack.Frameworks, func(i, j int) bool {
+		return stack.Frameworks[i].Repos > stack.Frameworks[j].Repos
+	})
+	if len(stack.Frameworks) > limit {
+		stack.Frameworks = stack.Frameworks[:limit]
+	}
+	return stack
+}
@@ -0,0 +1,184 @@
+package team
+
+import (
+	"math"
+	"sort"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/stats"
+)
+
+// teamRepoLimit — сколько репозиториев участников учитывать в суммах, языках и топе команды.
+const teamRepoLimit = 1000
+
+const (
+	topRepos       = 10
+	techStackLimit = 15
+)
+
+// aggregate собирает статистику команды из статистики участников (в том же порядке, что members)
+// и общего списка репозиториев без повторов.
+func aggregate(t models.Team, members []models.TeamMember, per []*models.UserStats, repos []models.Repo) *models.TeamStats {
+	ts := &models.TeamStats{Team: t, Members: make([]models.TeamMemberStats, 0, len(members))}
+	st := &ts.UserStats
+	st.TotalRepos = len(repos)
+	for i, r := range repos {
+		st.TotalStars += r.Stars
+		st.TotalForks += r.Forks
+		if i < topRepos {
+			st.TopRepos = append(st.TopRepos, r)
+		}
+	}
+	st.Languages = stats.CalculateLanguageStats(repos)
+	st.Streaks.History = []models.Streak{}
+	st.Goals = []models.GoalProgress{}
+
+	var contribs []models.ContributionDay
+	var daily []models.DailyStats
+	var frameworks, libraries []models.TechItem
+	weekly := false
+	maxActive, timed := 0, 0
+	var weekend, afterHours float64
+	for i, m := range members {
+		s := per[i]
+		if i == 0 || s.Period.From < st.Period.From {
+			st.Period.From = s.Period.From
+		}
+		if s.Period.To > st.Period.To {
+			st.Period.To = s.Period.To
+		}
+		st.Period.Name = s.Period.Name
+		contribs = append(contribs, s.Contributions...)
+		daily = append(daily, s.DailyStats...)
+		st.Totals.Contributions += s.Totals.Contributions
+		st.Totals.Commits += s.Totals.Commits
+		st.Totals.PRs += s.Totals.PRs
+		st.Totals.Issues += s.Totals.Issues
+		st.Totals.StarsReceived += s.Totals.StarsReceived
+		if s.Totals.ActiveDays > maxActive {
+			maxActive = s.Totals.ActiveDays
+		}
+		for _, c := range s.Contributions {
+			if c.Granularity != "" {
+				weekly = true
+			}
+		}
+		w := float64(s.WorkPattern.TimedContributions)
+		timed += s.WorkPattern.TimedContributions
+		weekend += s.WorkPattern.WeekendRatio * w
+		afterHours += s.WorkPattern.AfterHoursRatio * w
+		frameworks = append(frameworks, s.TechStack.Frameworks...)
+		libraries = append(libraries, s.TechStack.Libraries...)
+
+		ts.Members = append(ts.Members, models.TeamMemberStats{
+			UserID: m.UserID, Username: m.Username, Role: m.Role,
+			Totals: s.Totals, TotalRepos: s.TotalRepos, TotalStars: s.TotalStars,
+			CurrentStreak: s.Streaks.Current.Length, LongestStreak: s.Streaks.Longest.Length,
+			WorkPattern: s.WorkPattern,
+		})
+	}
+	st.Contributions = mergeContributions(contribs)
+	st.DailyStats = mergeDaily(daily)
+	st.ContributionSum = st.Totals.Contributions
+
+	// Активный день команды — день, когда вклад был хотя бы у одного участника. Для периодов,
+	// где остались только недельные агрегаты, дни не восстановить — берём лучшего участника.
+	if weekly {
+		st.Totals.ActiveDays = maxActive
+	} else {
+		for _, c := range st.Contributions {
+			if c.Count > 0 {
+				st.Totals.ActiveDays++
+			}
+		}
+	}
+	st.WorkPattern.TimedContributions = timed
+	if timed > 0 {
+		st.WorkPattern.WeekendRatio = round(weekend/float64(timed), 3)
+		st.WorkPattern.AfterHoursRatio = round(afterHours/float64(timed), 3)
+	}
+	st.TechStack = models.TechStack{Frameworks: mergeTech(frameworks), Libraries: mergeTech(libraries)}
+
+	for i := range ts.Members {
+		if st.Totals.Contributions > 0 {
+			ts.Members[i].Share = round(float64(ts.Members[i].Totals.Contributions)*100/float64(st.Totals.Contributions), 1)
+		}
+	}
+	sort.SliceStable(ts.Members, func(i, j int) bool {
+		return ts.Members[i].Totals.Contributions > ts.Members[j].Totals.Contributions
+	})
+	return ts
+}
+
+// mergeContributions суммирует точки участников с одинаковой датой и гранулярностью.
+func mergeContributions(days []models.ContributionDay) []models.ContributionDay {
+	idx := make(map[[2]string]int)
+	out := []models.ContributionDay{}
+	for _, d := range days {
+		key := [2]string{d.Date, d.Granularity}
+		if i, ok := idx[key]; ok {
+			out[i].Count += d.Count
+			continue
+		}
+		idx[key] = len(out)
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].Granularity < out[j].Granularity
+	})
+	return out
+}
+
+// mergeDaily — как mergeContributions; UserID у командных точек пустой.
+func mergeDaily(rows []models.DailyStats) []models.DailyStats {
+	idx := make(map[[2]string]int)
+	out := []models.DailyStats{}
+	for _, d := range rows {
+		key := [2]string{d.Date, d.Granularity}
+		if i, ok := idx[key]; ok {
+			out[i].Commits += d.Commits
+			out[i].PRs += d.PRs
+			out[i].Issues += d.Issues
+			out[i].StarsReceived += d.StarsReceived
+			continue
+		}
+		d.UserID = uuid.Nil
+		idx[key] = len(out)
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].Granularity < out[j].Granularity
+	})
+	return out
+}
+
+// mergeTech суммирует число репозиториев по фреймворку или библиотеке и оставляет самые частые.
+func mergeTech(items []models.TechItem) []models.TechItem {
+	idx := make(map[[2]string]int)
+	out := []models.TechItem{}
+	for _, t := range items {
+		key := [2]string{t.Ecosystem, t.Name}
+		if i, ok := idx[key]; ok {
+			out[i].Repos += t.Repos
+			continue
+		}
+		idx[key] = len(out)
+		out = append(out, t)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Repos > out[j].Repos })
+	if len(out) > techStackLimit {
+		out = out[:techStackLimit]
+	}
+	return out
+}
+
+func round(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
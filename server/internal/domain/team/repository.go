@@ -0,0 +1,261 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+type Repository interface {
+	// Create создаёт команду и делает создателя её владельцем.
+	Create(ctx context.Context, t *models.Team) error
+	// Get — команда глазами участника userID; ErrNotFound, если он в ней не состоит.
+	Get(ctx context.Context, teamID, userID uuid.UUID) (*models.Team, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]models.Team, error)
+	// SharesTeam — состоят ли оба пользователя хотя бы в одной общей команде.
+	SharesTeam(ctx context.Context, userID, otherID uuid.UUID) (bool, error)
+	Rename(ctx context.Context, teamID uuid.UUID, name string) error
+	Delete(ctx context.Context, teamID uuid.UUID) error
+
+	Members(ctx context.Context, teamID uuid.UUID) ([]models.TeamMember, error)
+	SetRole(ctx context.Context, teamID, userID uuid.UUID, role string) error
+	RemoveMember(ctx context.Context, teamID, userID uuid.UUID) error
+
+	// Invite создаёт приглашение; ErrAlreadyInvited — на этот логин уже есть ожидающее.
+	Invite(ctx context.Context, inv *models.TeamInvitation, invitedBy uuid.UUID) error
+	Invitation(ctx context.Context, id uuid.UUID) (*models.TeamInvitation, error)
+	// Invitations — ожидающие приглашения команды.
+	Invitations(ctx context.Context, teamID uuid.UUID) ([]models.TeamInvitation, error)
+	// InvitationsFor — ожидающие приглашения на логин (без учёта регистра).
+	InvitationsFor(ctx context.Context, username string) ([]models.TeamInvitation, error)
+	CancelInvitation(ctx context.Context, teamID, id uuid.UUID) error
+	// Respond закрывает ожидающее приглашение; при accept добавляет userID в команду.
+	Respond(ctx context.Context, id, userID uuid.UUID, accept bool) error
+}
+
+type repo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &repo{pool: pool}
+}
+
+const teamColumns = `t.id, t.name, t.owner_id, m.role,
+	(SELECT COUNT(*) FROM team_members c WHERE c.team_id = t.id), t.created_at`
+
+func scanTeam(row pgx.Row, t *models.Team) error {
+	return row.Scan(&t.ID, &t.Name, &t.OwnerID, &t.Role, &t.Members, &t.CreatedAt)
+}
+
+func (r *repo) Create(ctx context.Context, t *models.Team) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	err = tx.QueryRow(ctx, `INSERT INTO teams (name, owner_id) VALUES ($1, $2) RETURNING id, created_at`,
+		t.Name, t.OwnerID).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)`,
+		t.ID, t.OwnerID, RoleOwner); err != nil {
+		return err
+	}
+	t.Role, t.Members = RoleOwner, 1
+	return tx.Commit(ctx)
+}
+
+func (r *repo) Get(ctx context.Context, teamID, userID uuid.UUID) (*models.Team, error) {
+	var t models.Team
+	err := scanTeam(r.pool.QueryRow(ctx, `SELECT `+teamColumns+`
+		FROM teams t JOIN team_members m ON m.team_id = t.id AND m.user_id = $2
+		WHERE t.id = $1`, teamID, userID), &t)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *repo) ListByUser(ctx context.Context, userID uuid.UUID) ([]models.Team, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+teamColumns+`
+		FROM teams t JOIN team_members m ON m.team_id = t.id AND m.user_id = $1
+		ORDER BY t.name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.Team{}
+	for rows.Next() {
+		var t models.Team
+		if err := scanTeam(rows, &t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) SharesTeam(ctx context.Context, userID, otherID uuid.UUID) (bool, error) {
+	var ok bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM team_members a
+		JOIN team_members b ON b.team_id = a.team_id AND b.user_id = $2
+		WHERE a.user_id = $1)`, userID, otherID).Scan(&ok)
+	return ok, err
+}
+
+func (r *repo) Rename(ctx context.Context, teamID uuid.UUID, name string) error {
+	return r.exec(ctx, ErrNotFound, `UPDATE teams SET name = $2, updated_at = NOW() WHERE id = $1`, teamID, name)
+}
+
+func (r *repo) Delete(ctx context.Context, teamID uuid.UUID) error {
+	return r.exec(ctx, ErrNotFound, `DELETE FROM teams WHERE id = $1`, teamID)
+}
+
+func (r *repo) Members(ctx context.Context, teamID uuid.UUID) ([]models.TeamMember, error) {
+	rows, err := r.pool.Query(ctx, `SELECT m.user_id, u.username, u.avatar_url, m.role, m.joined_at
+		FROM team_members m JOIN users u ON u.id = m.user_id
+		WHERE m.team_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, LOWER(u.username)`, teamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.TeamMember{}
+	for rows.Next() {
+		var m models.TeamMember
+		if err := rows.Scan(&m.UserID, &m.Username, &m.AvatarURL, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) SetRole(ctx context.Context, teamID, userID uuid.UUID, role string) error {
+	return r.exec(ctx, ErrMemberNotFound, `UPDATE team_members SET role = $3 WHERE team_id = $1 AND user_id = $2`, teamID, userID, role)
+}
+
+func (r *repo) RemoveMember(ctx context.Context, teamID, userID uuid.UUID) error {
+	return r.exec(ctx, ErrMemberNotFound, `DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`, teamID, userID)
+}
+
+const invitationColumns = `i.id, i.team_id, t.name, i.username, i.role, COALESCE(u.username, ''), i.status, i.created_at, i.responded_at`
+
+const invitationFrom = ` FROM team_invitations i JOIN teams t ON t.id = i.team_id LEFT JOIN users u ON u.id = i.invited_by`
+
+func scanInvitation(row pgx.Row, inv *models.TeamInvitation) error {
+	return row.Scan(&inv.ID, &inv.TeamID, &inv.TeamName, &inv.Username, &inv.Role, &inv.InvitedBy, &inv.Status, &inv.CreatedAt, &inv.RespondedAt)
+}
+
+func (r *repo) Invite(ctx context.Context, inv *models.TeamInvitation, invitedBy uuid.UUID) error {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `INSERT INTO team_invitations (team_id, username, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (team_id, LOWER(username)) WHERE status = 'pending' DO NOTHING
+		RETURNING id`, inv.TeamID, inv.Username, inv.Role, invitedBy).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrAlreadyInvited
+	}
+	if err != nil {
+		return err
+	}
+	saved, err := r.Invitation(ctx, id)
+	if err != nil {
+		return err
+	}
+	*inv = *saved
+	return nil
+}
+
+func (r *repo) Invitation(ctx context.Context, id uuid.UUID) (*models.TeamInvitation, error) {
+	var inv models.TeamInvitation
+	err := scanInvitation(r.pool.QueryRow(ctx, `SELECT `+invitationColumns+invitationFrom+` WHERE i.id = $1`, id), &inv)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+func (r *repo) Invitations(ctx context.Context, teamID uuid.UUID) ([]models.TeamInvitation, error) {
+	return r.invitations(ctx, `SELECT `+invitationColumns+invitationFrom+`
+		WHERE i.team_id = $1 AND i.status = 'pending' ORDER BY i.created_at DESC`, teamID)
+}
+
+func (r *repo) InvitationsFor(ctx context.Context, username string) ([]models.TeamInvitation, error) {
+	return r.invitations(ctx, `SELECT `+invitationColumns+invitationFrom+`
+		WHERE LOWER(i.username) = LOWER($1) AND i.status = 'pending' ORDER BY i.created_at DESC`, username)
+}
+
+func (r *repo) invitations(ctx context.Context, query string, args ...interface{}) ([]models.TeamInvitation, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []models.TeamInvitation{}
+	for rows.Next() {
+		var inv models.TeamInvitation
+		if err := scanInvitation(rows, &inv); err != nil {
+			return nil, err
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+func (r *repo) CancelInvitation(ctx context.Context, teamID, id uuid.UUID) error {
+	return r.exec(ctx, ErrInvitationNotFound, `DELETE FROM team_invitations WHERE team_id = $1 AND id = $2 AND status = 'pending'`, teamID, id)
+}
+
+func (r *repo) Respond(ctx context.Context, id, userID uuid.UUID, accept bool) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	status := InvitationDeclined
+	if accept {
+		status = InvitationAccepted
+	}
+	var teamID uuid.UUID
+	var role string
+	err = tx.QueryRow(ctx, `UPDATE team_invitations SET status = $2, responded_at = NOW()
+		WHERE id = $1 AND status = 'pending' RETURNING team_id, role`, id, status).Scan(&teamID, &role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvitationNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if accept {
+		// Уже состоящий в команде (например, по другому приглашению) сохраняет свою роль
+		if _, err := tx.Exec(ctx, `INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (team_id, user_id) DO NOTHING`, teamID, userID, role); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// exec выполняет запрос и возвращает notFound, если он не затронул ни одной строки.
+func (r *repo) exec(ctx context.Context, notFound error, query string, args ...interface{}) error {
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package team
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/stats"
+	"github.com/devsync/server/internal/domain/user"
+)
+
+// Роли в команде: владелец один; администраторы приглашают и исключают участников.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+const (
+	MaxMembers    = 50
+	MaxNameLength = 100
+)
+
+var (
+	ErrNotFound           = errors.New("team not found")
+	ErrMemberNotFound     = errors.New("team member not found")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvalidTeam        = errors.New("invalid team")
+	ErrForbidden          = errors.New("not allowed for your team role")
+	ErrAlreadyInvited     = errors.New("user already invited")
+	ErrAlreadyMember      = errors.New("user already in team")
+	ErrTeamFull           = fmt.Errorf("at most %d members per team", MaxMembers)
+)
+
+// StatsProvider — статистика участников; команда собирает из неё свою.
+type StatsProvider interface {
+	GetUserStatsWithPeriod(ctx context.Context, userID uuid.UUID, q stats.PeriodQuery) (*models.UserStats, error)
+	GetContributions(ctx context.Context, userID uuid.UUID, from, to string) ([]models.ContributionDay, error)
+	GetGroupRepos(ctx context.Context, userIDs []uuid.UUID, f stats.RepoFilter) ([]models.Repo, error)
+}
+
+type Service interface {
+	Create(ctx context.Context, userID uuid.UUID, name string) (*models.Team, error)
+	List(ctx context.Context, userID uuid.UUID) ([]models.Team, error)
+	Get(ctx context.Context, userID, teamID uuid.UUID) (*models.Team, error)
+	// SharesTeam — видит ли userID статистику otherID как коллега по команде.
+	SharesTeam(ctx context.Context, userID, otherID uuid.UUID) (bool, error)
+	Rename(ctx context.Context, userID, teamID uuid.UUID, name string) (*models.Team, error)
+	Delete(ctx context.Context, userID, teamID uuid.UUID) error
+
+	Members(ctx context.Context, userID, teamID uuid.UUID) ([]models.TeamMember, error)
+	// UpdateMember меняет роль участника (admin или member); только владелец.
+	UpdateMember(ctx context.Context, userID, teamID, memberID uuid.UUID, role string) error
+	// RemoveMember исключает участника; memberID == userID — выйти из команды (владельцу нельзя).
+	RemoveMember(ctx context.Context, userID, teamID, memberID uuid.UUID) error
+
+	Invite(ctx context.Context, userID, teamID uuid.UUID, username, role string) (*models.TeamInvitation, error)
+	Invitations(ctx context.Context, userID, teamID uuid.UUID) ([]models.TeamInvitation, error)
+	CancelInvitation(ctx context.Context, userID, teamID, invitationID uuid.UUID) error
+	// MyInvitations — ожидающие приглашения текущего пользователя (по его логину GitHub).
+	MyInvitations(ctx context.Context, userID uuid.UUID) ([]models.TeamInvitation, error)
+	// Respond принимает или отклоняет приглашение; при принятии возвращает команду.
+	Respond(ctx context.Context, userID, invitationID uuid.UUID, accept bool) (*models.Team, error)
+
+	Stats(ctx context.Context, userID, teamID uuid.UUID, q stats.PeriodQuery) (*models.TeamStats, error) // stats.ErrInvalidPeriod — неверный период
+	Contributions(ctx context.Context, userID, teamID uuid.UUID, from, to string) ([]models.ContributionDay, error)
+	Repos(ctx context.Context, userID, teamID uuid.UUID, f stats.RepoFilter) ([]models.Repo, error) // stats.ErrInvalidRepoFilter
+}
+
+type service struct {
+	repo     Repository
+	userSvc  user.Service
+	statsSvc StatsProvider
+}
+
+func NewService(repo Repository, userSvc user.Service, statsSvc StatsProvider) Service {
+	return &service{repo: repo, userSvc: userSvc, statsSvc: statsSvc}
+}
+
+func (s *service) Create(ctx context.Context, userID uuid.UUID, name string) (*models.Team, error) {
+	name, err := validName(name)
+	if err != nil {
+		return nil, err
+	}
+	t := &models.Team{Name: name, OwnerID: userID}
+	if err := s.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *service) List(ctx context.Context, userID uuid.UUID) ([]models.Team, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *service) Get(ctx context.Context, userID, teamID uuid.UUID) (*models.Team, error) {
+	return s.repo.Get(ctx, teamID, userID)
+}
+
+func (s *service) SharesTeam(ctx context.Context, userID, otherID uuid.UUID) (bool, error) {
+	return s.repo.SharesTeam(ctx, userID, otherID)
+}
+
+func (s *service) Rename(ctx context.Context, userID, teamID uuid.UUID, name string) (*models.Team, error) {
+	name, err := validName(name)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.require(ctx, userID, teamID, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Rename(ctx, teamID, name); err != nil {
+		return nil, err
+	}
+	t.Name = name
+	return t, nil
+}
+
+func (s *service) Delete(ctx context.Context, userID, teamID uuid.UUID) error {
+	if _, err := s.require(ctx, userID, teamID, RoleOwner); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, teamID)
+}
+
+func (s *service) Members(ctx context.Context, userID, teamID uuid.UUID) ([]models.TeamMember, error) {
+	if _, err := s.repo.Get(ctx, teamID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Members(ctx, teamID)
+}
+
+func (s *service) UpdateMember(ctx context.Context, userID, teamID, memberID uuid.UUID, role string) error {
+	if role != RoleAdmin && role != RoleMember {
+		return fmt.Errorf("%w: role must be admin or member", ErrInvalidTeam)
+	}
+	t, err := s.require(ctx, userID, teamID, RoleOwner)
+	if err != nil {
+		return err
+	}
+	if memberID == t.OwnerID {
+		return fmt.Errorf("%w: owner role cannot be changed", ErrInvalidTeam)
+	}
+	return s.repo.SetRole(ctx, teamID, memberID, role)
+}
+
+func (s *service) RemoveMember(ctx context.Context, userID, teamID, memberID uuid.UUID) error {
+	t, err := s.repo.Get(ctx, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if memberID == t.OwnerID {
+		return fmt.Errorf("%w: owner cannot leave the team, delete it instead", ErrInvalidTeam)
+	}
+	if memberID == userID {
+		return s.repo.RemoveMember(ctx, teamID, memberID)
+	}
+	if rank(t.Role) < rank(RoleAdmin) {
+		return ErrForbidden
+	}
+	// Администратора исключает только владелец
+	target, err := s.repo.Get(ctx, teamID, memberID)
+	if errors.Is(err, ErrNotFound) {
+		return ErrMemberNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if target.Role == RoleAdmin && t.Role != RoleOwner {
+		return ErrForbidden
+	}
+	return s.repo.RemoveMember(ctx, teamID, memberID)
+}
+
+func (s *service) Invite(ctx context.Context, userID, teamID uuid.UUID, username, role string) (*models.TeamInvitation, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if username == "" {
+		return nil, fmt.Errorf("%w: username is required", ErrInvalidTeam)
+	}
+	if role == "" {
+		role = RoleMember
+	}
+	if role != RoleAdmin && role != RoleMember {
+		return nil, fmt.Errorf("%w: role must be admin or member", ErrInvalidTeam)
+	}
+	t, err := s.require(ctx, userID, teamID, RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if role == RoleAdmin && t.Role != RoleOwner {
+		return nil, ErrForbidden
+	}
+	if t.Members >= MaxMembers {
+		return nil, ErrTeamFull
+	}
+	if u, err := s.userSvc.GetByUsername(ctx, username); err == nil {
+		if _, err := s.repo.Get(ctx, teamID, u.ID); err == nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+	inv := &models.TeamInvitation{TeamID: teamID, Username: username, Role: role}
+	if err := s.repo.Invite(ctx, inv, userID); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (s *service) Invitations(ctx context.Context, userID, teamID uuid.UUID) ([]models.TeamInvitation, error) {
+	if _, err := s.require(ctx, userID, teamID, RoleAdmin); err != nil {
+		return nil, err
+	}
+	return s.repo.Invitations(ctx, teamID)
+}
+
+func (s *service) CancelInvitation(ctx context.Context, userID, teamID, invitationID uuid.UUID) error {
+	if _, err := s.require(ctx, userID, teamID, RoleAdmin); err != nil {
+		return err
+	}
+	return s.repo.CancelInvitation(ctx, teamID, invitationID)
+}
+
+func (s *service) MyInvitations(ctx context.Context, userID uuid.UUID) ([]models.TeamInvitation, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.InvitationsFor(ctx, u.Username)
+}
+
+func (s *service) Respond(ctx context.Context, userID, invitationID uuid.UUID, accept bool) (*models.Team, error) {
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	inv, err := s.repo.Invitation(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	// Чужое приглашение не раскрываем
+	if !strings.EqualFold(inv.Username, u.Username) || inv.Status != InvitationPending {
+		return nil, ErrInvitationNotFound
+	}
+	if accept {
+		members, err := s.repo.Members(ctx, inv.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) >= MaxMembers {
+			return nil, ErrTeamFull
+		}
+	}
+	if err := s.repo.Respond(ctx, invitationID, userID, accept); err != nil {
+		return nil, err
+	}
+	if !accept {
+		return nil, nil
+	}
+	return s.repo.Get(ctx, inv.TeamID, userID)
+}
+
+func (s *service) Stats(ctx context.Context, userID, teamID uuid.UUID, q stats.PeriodQuery) (*models.TeamStats, error) {
+	t, err := s.repo.Get(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.Members(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	per := make([]*models.UserStats, 0, len(members))
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		st, err := s.statsSvc.GetUserStatsWithPeriod(ctx, m.UserID, q)
+		if err != nil {
+			return nil, err
+		}
+		per = append(per, st)
+		ids = append(ids, m.UserID)
+	}
+	repos, err := s.statsSvc.GetGroupRepos(ctx, ids, stats.RepoFilter{Limit: teamRepoLimit})
+	if err != nil {
+		return nil, err
+	}
+	return aggregate(*t, members, per, repos), nil
+}
+
+func (s *service) Contributions(ctx context.Context, userID, teamID uuid.UUID, from, to string) ([]models.ContributionDay, error) {
+	members, err := s.members(ctx, userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	var all []models.ContributionDay
+	for _, m := range members {
+		days, err := s.statsSvc.GetContributions(ctx, m.UserID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, days...)
+	}
+	return mergeContributions(all), nil
+}
+
+func (s *service) Repos(ctx context.Context, userID, teamID uuid.UUID, f stats.RepoFilter) ([]models.Repo, error) {
+	members, err := s.members(ctx, userID, teamID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return s.statsSvc.GetGroupRepos(ctx, ids, f)
+}
+
+// members — участники команды, если userID в ней состоит.
+func (s *service) members(ctx context.Context, userID, teamID uuid.UUID) ([]models.TeamMember, error) {
+	if _, err := s.repo.Get(ctx, teamID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Members(ctx, teamID)
+}
+
+// require — команда, если у userID роль не ниже role.
+func (s *service) require(ctx context.Context, userID, teamID uuid.UUID, role string) (*models.Team, error) {
+	t, err := s.repo.Get(ctx, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rank(t.Role) < rank(role) {
+		return nil, ErrForbidden
+	}
+	return t, nil
+}
+
+func rank(role string) int {
+	switch role {
+	case RoleOwner:
+		return 2
+	case RoleAdmin:
+		return 1
+	}
+	return 0
+}
+
+func validName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MaxNameLength {
+		return "", fmt.Errorf("%w: name must be 1-%d characters", ErrInvalidTeam, MaxNameLength)
+	}
+	return name, nil
+}
@@ -2,8 +2,13 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/devsync/server/internal/infrastructure/secrets"
 )
 
 type Repository interface {
@@ -11,11 +16,14 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*User, error)
 	GetByIDWithToken(ctx context.Context, id uuid.UUID) (*User, error) // internal: for sync
 	GetByGitHubID(ctx context.Context, githubID int64) (*User, error)
-	GetByUsername(ctx context.Context, username string) (*User, error)
-	ListIDsWithToken(ctx context.Context) ([]uuid.UUID, error) // для worker
+	GetByUsername(ctx context.Context, username string) (*User, error) // без учёта регистра, как на GitHub; ErrNotFound
+	ListIDsWithToken(ctx context.Context) ([]uuid.UUID, error) // для worker, без пользователей с reauth_required
 	Update(ctx context.Context, u *User) error
-	UpdateTokens(ctx context.Context, id uuid.UUID, accessToken, refreshToken string) error
+	UpdateTokens(ctx context.Context, id uuid.UUID, accessToken string, refreshToken *string, expiresAt *time.Time) error
+	SetReauthRequired(ctx context.Context, id uuid.UUID, required bool) error
 	UpdateLastSynced(ctx context.Context, id uuid.UUID) error
+	UpdateTimezone(ctx context.Context, id uuid.UUID, timezone string) error
+	RotateTokens(ctx context.Context, batchSize int) (int, error) // перешифровать токены активным ключом
 }
 
 type User struct {
@@ -26,6 +34,9 @@ type User struct {
 	AvatarURL    *string    `json:"avatar_url,omitempty"`
 	AccessToken  string     `json:"-"`
 	RefreshToken *string    `json:"-"`
+	TokenExpiresAt *time.Time `json:"-"`
+	ReauthRequired bool     `json:"reauth_required"`
+	Timezone     string     `json:"timezone"`
 	LastSyncedAt *string    `json:"last_synced_at,omitempty"`
 	CreatedAt    string     `json:"created_at"`
 	UpdatedAt    string     `json:"updated_at"`
@@ -33,35 +44,53 @@ type User struct {
 
 type repo struct {
 	pool *pgxpool.Pool
+	keys *secrets.Keyring // nil — токены хранятся без шифрования
 }
 
-func NewRepository(pool *pgxpool.Pool) Repository {
-	return &repo{pool: pool}
+func NewRepository(pool *pgxpool.Pool, keys *secrets.Keyring) Repository {
+	return &repo{pool: pool, keys: keys}
 }
 
 func (r *repo) Create(ctx context.Context, u *User) error {
-	query := `INSERT INTO users (id, github_id, username, email, avatar_url, access_token, refresh_token)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	// Create вызывается при входе через GitHub — заодно отмечаем время входа
+	// Токены шифруются с привязкой к id строки, а он известен только после upsert
+	query := `INSERT INTO users (id, github_id, username, email, avatar_url, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
 		ON CONFLICT (github_id) DO UPDATE SET
 			username = EXCLUDED.username,
 			email = EXCLUDED.email,
 			avatar_url = EXCLUDED.avatar_url,
-			access_token = EXCLUDED.access_token,
-			refresh_token = EXCLUDED.refresh_token,
+			reauth_required = false,
+			last_login_at = NOW(),
 			updated_at = NOW()
 		RETURNING id, created_at::text, updated_at::text`
-	return r.pool.QueryRow(ctx, query,
-		u.ID, u.GitHubID, u.Username, u.Email, u.AvatarURL, u.AccessToken, u.RefreshToken,
-	).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := tx.QueryRow(ctx, query, u.ID, u.GitHubID, u.Username, u.Email, u.AvatarURL).Scan(&u.ID, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return err
+	}
+	access, refresh, err := r.encryptTokens(u.ID, u.AccessToken, u.RefreshToken)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET access_token=$2, refresh_token=$3, token_expires_at=$4 WHERE id=$1`,
+		u.ID, access, refresh, u.TokenExpiresAt); err != nil {
+		return err
+	}
+	u.ReauthRequired = false
+	return tx.Commit(ctx)
 }
 
 func (r *repo) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
-	query := `SELECT id, github_id, username, email, avatar_url, last_synced_at::text, created_at::text, updated_at::text
+	query := `SELECT id, github_id, username, email, avatar_url, reauth_required, timezone, last_synced_at::text, created_at::text, updated_at::text
 		FROM users WHERE id = $1`
 	u := &User{}
 	var email, avatar, lastSynced *string
 	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &u.ReauthRequired, &u.Timezone, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -73,12 +102,12 @@ func (r *repo) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 }
 
 func (r *repo) GetByIDWithToken(ctx context.Context, id uuid.UUID) (*User, error) {
-	query := `SELECT id, github_id, username, email, avatar_url, access_token, refresh_token, last_synced_at::text, created_at::text, updated_at::text
+	query := `SELECT id, github_id, username, email, avatar_url, access_token, refresh_token, token_expires_at, reauth_required, timezone, last_synced_at::text, created_at::text, updated_at::text
 		FROM users WHERE id = $1`
 	u := &User{}
 	var email, avatar, refresh, lastSynced *string
 	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &u.AccessToken, &refresh, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &u.AccessToken, &refresh, &u.TokenExpiresAt, &u.ReauthRequired, &u.Timezone, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -87,17 +116,20 @@ func (r *repo) GetByIDWithToken(ctx context.Context, id uuid.UUID) (*User, error
 	u.AvatarURL = avatar
 	u.RefreshToken = refresh
 	u.LastSyncedAt = lastSynced
+	if err := r.decryptTokens(u); err != nil {
+		return nil, err
+	}
 	return u, nil
 }
 
 func (r *repo) GetByGitHubID(ctx context.Context, githubID int64) (*User, error) {
-	query := `SELECT id, github_id, username, email, avatar_url, access_token, refresh_token, last_synced_at::text, created_at::text, updated_at::text
+	query := `SELECT id, github_id, username, email, avatar_url, access_token, refresh_token, token_expires_at, reauth_required, timezone, last_synced_at::text, created_at::text, updated_at::text
 		FROM users WHERE github_id = $1`
 	u := &User{}
 	var email, avatar, refresh *string
 	var lastSynced *string
 	err := r.pool.QueryRow(ctx, query, githubID).Scan(
-		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &u.AccessToken, &refresh, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &u.AccessToken, &refresh, &u.TokenExpiresAt, &u.ReauthRequired, &u.Timezone, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -106,11 +138,14 @@ func (r *repo) GetByGitHubID(ctx context.Context, githubID int64) (*User, error)
 	u.AvatarURL = avatar
 	u.RefreshToken = refresh
 	u.LastSyncedAt = lastSynced
+	if err := r.decryptTokens(u); err != nil {
+		return nil, err
+	}
 	return u, nil
 }
 
 func (r *repo) ListIDsWithToken(ctx context.Context) ([]uuid.UUID, error) {
-	query := `SELECT id FROM users WHERE access_token IS NOT NULL AND access_token != ''`
+	query := `SELECT id FROM users WHERE access_token IS NOT NULL AND access_token != '' AND NOT reauth_required`
 	rows, err := r.pool.Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -128,13 +163,16 @@ func (r *repo) ListIDsWithToken(ctx context.Context) ([]uuid.UUID, error) {
 }
 
 func (r *repo) GetByUsername(ctx context.Context, username string) (*User, error) {
-	query := `SELECT id, github_id, username, email, avatar_url, last_synced_at::text, created_at::text, updated_at::text
-		FROM users WHERE username = $1`
+	query := `SELECT id, github_id, username, email, avatar_url, reauth_required, timezone, last_synced_at::text, created_at::text, updated_at::text
+		FROM users WHERE LOWER(username) = LOWER($1)`
 	u := &User{}
 	var email, avatar, lastSynced *string
 	err := r.pool.QueryRow(ctx, query, username).Scan(
-		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
+		&u.ID, &u.GitHubID, &u.Username, &email, &avatar, &u.ReauthRequired, &u.Timezone, &lastSynced, &u.CreatedAt, &u.UpdatedAt,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -150,9 +188,19 @@ func (r *repo) Update(ctx context.Context, u *User) error {
 	return err
 }
 
-func (r *repo) UpdateTokens(ctx context.Context, id uuid.UUID, accessToken, refreshToken string) error {
-	query := `UPDATE users SET access_token=$2, refresh_token=$3, updated_at=NOW() WHERE id=$1`
-	_, err := r.pool.Exec(ctx, query, id, accessToken, &refreshToken)
+func (r *repo) UpdateTokens(ctx context.Context, id uuid.UUID, accessToken string, refreshToken *string, expiresAt *time.Time) error {
+	access, refresh, err := r.encryptTokens(id, accessToken, refreshToken)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE users SET access_token=$2, refresh_token=$3, token_expires_at=$4, reauth_required=false, updated_at=NOW() WHERE id=$1`
+	_, err = r.pool.Exec(ctx, query, id, access, refresh, expiresAt)
+	return err
+}
+
+func (r *repo) SetReauthRequired(ctx context.Context, id uuid.UUID, required bool) error {
+	query := `UPDATE users SET reauth_required=$2, updated_at=NOW() WHERE id=$1`
+	_, err := r.pool.Exec(ctx, query, id, required)
 	return err
 }
 
@@ -161,3 +209,131 @@ func (r *repo) UpdateLastSynced(ctx context.Context, id uuid.UUID) error {
 	_, err := r.pool.Exec(ctx, query, id)
 	return err
 }
+
+func (r *repo) UpdateTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	query := `UPDATE users SET timezone=$2, updated_at=NOW() WHERE id=$1`
+	_, err := r.pool.Exec(ctx, query, id, timezone)
+	return err
+}
+
+// Location — часовой пояс пользователя для разбивки активности по дням; UTC, если не задан.
+func (u *User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// RotateTokens проходит по всем пользователям пачками и перешифровывает токены активным ключом.
+// Запись обновляется, только если токены не поменялись с момента чтения (параллельный логин
+// не затирается), поэтому ротацию можно запускать на работающем сервисе.
+func (r *repo) RotateTokens(ctx context.Context, batchSize int) (int, error) {
+	if r.keys == nil {
+		return 0, fmt.Errorf("rotate tokens: no encryption keys configured")
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	rotated := 0
+	last := uuid.Nil
+	for {
+		rows, err := r.pool.Query(ctx, `SELECT id, COALESCE(access_token, ''), refresh_token FROM users
+			WHERE id > $1 ORDER BY id LIMIT $2`, last, batchSize)
+		if err != nil {
+			return rotated, err
+		}
+		type tokenRow struct {
+			id      uuid.UUID
+			access  string
+			refresh *string
+		}
+		var batch []tokenRow
+		for rows.Next() {
+			var t tokenRow
+			if err := rows.Scan(&t.id, &t.access, &t.refresh); err != nil {
+				rows.Close()
+				return rotated, err
+			}
+			batch = append(batch, t)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rotated, err
+		}
+		if len(batch) == 0 {
+			return rotated, nil
+		}
+		for _, t := range batch {
+			last = t.id
+			access, accessChanged, err := r.keys.Rotate(t.access, tokenAAD(t.id, columnAccessToken))
+			if err != nil {
+				return rotated, fmt.Errorf("user %s: %w", t.id, err)
+			}
+			refresh := t.refresh
+			refreshChanged := false
+			if t.refresh != nil {
+				v, changed, err := r.keys.Rotate(*t.refresh, tokenAAD(t.id, columnRefreshToken))
+				if err != nil {
+					return rotated, fmt.Errorf("user %s: %w", t.id, err)
+				}
+				refresh, refreshChanged = &v, changed
+			}
+			if !accessChanged && !refreshChanged {
+				continue
+			}
+			tag, err := r.pool.Exec(ctx, `UPDATE users SET access_token=$2, refresh_token=$3
+				WHERE id=$1 AND COALESCE(access_token, '') = $4 AND refresh_token IS NOT DISTINCT FROM $5`,
+				t.id, access, refresh, t.access, t.refresh)
+			if err != nil {
+				return rotated, err
+			}
+			rotated += int(tag.RowsAffected())
+		}
+	}
+}
+
+// Столбцы токенов: вместе с id пользователя — associated data шифрования, поэтому зашифрованный
+// токен нельзя подставить другому пользователю или в другой столбец.
+const (
+	columnAccessToken  = "access_token"
+	columnRefreshToken = "refresh_token"
+)
+
+func tokenAAD(userID uuid.UUID, column string) string {
+	return userID.String() + "|" + column
+}
+
+func (r *repo) encryptTokens(userID uuid.UUID, access string, refresh *string) (string, *string, error) {
+	encAccess, err := r.keys.Encrypt(access, tokenAAD(userID, columnAccessToken))
+	if err != nil {
+		return "", nil, fmt.Errorf("encrypt access token: %w", err)
+	}
+	if refresh == nil {
+		return encAccess, nil, nil
+	}
+	encRefresh, err := r.keys.Encrypt(*refresh, tokenAAD(userID, columnRefreshToken))
+	if err != nil {
+		return "", nil, fmt.Errorf("encrypt refresh token: %w", err)
+	}
+	return encAccess, &encRefresh, nil
+}
+
+func (r *repo) decryptTokens(u *User) error {
+	access, err := r.keys.Decrypt(u.AccessToken, tokenAAD(u.ID, columnAccessToken))
+	if err != nil {
+		return fmt.Errorf("decrypt access token: %w", err)
+	}
+	u.AccessToken = access
+	if u.RefreshToken != nil {
+		refresh, err := r.keys.Decrypt(*u.RefreshToken, tokenAAD(u.ID, columnRefreshToken))
+		if err != nil {
+			return fmt.Errorf("decrypt refresh token: %w", err)
+		}
+		u.RefreshToken = &refresh
+	}
+	return nil
+}
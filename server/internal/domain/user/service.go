@@ -2,6 +2,8 @@ package user
 
 import (
 	"context"
+	"errors"
+	"time"
 	"github.com/google/uuid"
 )
 
@@ -12,9 +14,17 @@ type Service interface {
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	ListIDsWithToken(ctx context.Context) ([]uuid.UUID, error)
 	CreateOrUpdate(ctx context.Context, u *User) error
+	UpdateTokens(ctx context.Context, id uuid.UUID, accessToken string, refreshToken *string, expiresAt *time.Time) error
+	MarkReauthRequired(ctx context.Context, id uuid.UUID) error
 	UpdateLastSynced(ctx context.Context, id uuid.UUID) error
+	UpdateTimezone(ctx context.Context, id uuid.UUID, timezone string) error
 }
 
+var (
+	ErrInvalidTimezone = errors.New("invalid timezone, expected IANA name like Europe/Moscow")
+	ErrNotFound        = errors.New("user not found")
+)
+
 type service struct {
 	repo Repository
 }
@@ -50,6 +60,24 @@ func (s *service) CreateOrUpdate(ctx context.Context, u *User) error {
 	return s.repo.Create(ctx, u)
 }
 
+func (s *service) UpdateTokens(ctx context.Context, id uuid.UUID, accessToken string, refreshToken *string, expiresAt *time.Time) error {
+	return s.repo.UpdateTokens(ctx, id, accessToken, refreshToken, expiresAt)
+}
+
+func (s *service) MarkReauthRequired(ctx context.Context, id uuid.UUID) error {
+	return s.repo.SetReauthRequired(ctx, id, true)
+}
+
 func (s *service) UpdateLastSynced(ctx context.Context, id uuid.UUID) error {
 	return s.repo.UpdateLastSynced(ctx, id)
 }
+
+func (s *service) UpdateTimezone(ctx context.Context, id uuid.UUID, timezone string) error {
+	if timezone == "" || timezone == "Local" {
+		return ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	return s.repo.UpdateTimezone(ctx, id, timezone)
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Channel — канал Redis, через который worker передаёт события в WebSocket-хаб сервера.
+const Channel = "devsync:events"
+
+type Message struct {
+	UserID uuid.UUID       `json:"user_id"`
+	Event  string          `json:"event"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// Broadcaster — получатель событий (websocket.Hub).
+type Broadcaster interface {
+	BroadcastToUser(userID uuid.UUID, event string, data interface{})
+}
+
+type Publisher struct {
+	client *redis.Client
+}
+
+func NewPublisher(url string) (*Publisher, error) {
+	client, err := connect(url)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{client: client}, nil
+}
+
+// BroadcastToUser публикует событие; ошибки только логируются, как и в Hub.
+func (p *Publisher) BroadcastToUser(userID uuid.UUID, event string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("events: marshal %s: %v", event, err)
+		return
+	}
+	payload, _ := json.Marshal(Message{UserID: userID, Event: event, Data: raw})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := p.client.Publish(ctx, Channel, payload).Err(); err != nil {
+		log.Printf("events: publish %s: %v", event, err)
+	}
+}
+
+func (p *Publisher) Close() error {
+	return p.client.Close()
+}
+
+// Relay пересылает события из Redis в локальный хаб до отмены ctx. Потеряв Redis, подключается
+// заново с нарастающей паузой (до минуты); ошибкой завершается только при неверном url.
+func Relay(ctx context.Context, url string, target Broadcaster) error {
+	if _, err := redis.ParseURL(url); err != nil {
+		return fmt.Errorf("parse redis url: %w", err)
+	}
+	backoff := time.Second
+	for {
+		subscribed, err := relay(ctx, url, target)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if subscribed {
+			backoff = time.Second
+		}
+		log.Printf("events: relay stopped: %v; reconnecting in %s", err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+// relay — одно подключение: пересылает события, пока подписка жива; subscribed — подписка состоялась.
+func relay(ctx context.Context, url string, target Broadcaster) (bool, error) {
+	client, err := connect(url)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+	sub := client.Subscribe(ctx, Channel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		return false, fmt.Errorf("subscribe: %w", err)
+	}
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case m, ok := <-ch:
+			if !ok {
+				return true, errors.New("subscription closed")
+			}
+			var msg Message
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				log.Printf("events: bad message: %v", err)
+				continue
+			}
+			target.BroadcastToUser(msg.UserID, msg.Event, msg.Data)
+		}
+	}
+}
+
+func connect(url string) (*redis.Client, error) {
+	opt, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	client := redis.NewClient(opt)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis ping: %w", err)
+	}
+	return client, nil
+}
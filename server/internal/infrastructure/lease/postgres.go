@@ -0,0 +1,137 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Scheduler — аренда планировщика: ставить синхронизации в очередь должна только одна реплика worker.
+const Scheduler = "scheduler"
+
+type Lease struct {
+	Name       string    `json:"name"`
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type Store interface {
+	// Acquire берёт или продлевает аренду; true — holder сейчас лидер.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name, holder string) error
+	// Get — текущая аренда; nil, если её никто не брал.
+	Get(ctx context.Context, name string) (*Lease, error)
+}
+
+type pgStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(pool *pgxpool.Pool) Store {
+	return &pgStore{pool: pool}
+}
+
+func (s *pgStore) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	// Чужую аренду можно перехватить, только когда она истекла (реплика умерла, не продлив её)
+	var got string
+	err := s.pool.QueryRow(ctx, `INSERT INTO worker_leases (name, holder, acquired_at, renewed_at, expires_at)
+		VALUES ($1, $2, NOW(), NOW(), NOW() + $3 * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE SET
+			holder = EXCLUDED.holder,
+			acquired_at = CASE WHEN worker_leases.holder = EXCLUDED.holder THEN worker_leases.acquired_at ELSE NOW() END,
+			renewed_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+		WHERE worker_leases.holder = EXCLUDED.holder OR worker_leases.expires_at < NOW()
+		RETURNING holder`, name, holder, ttl.Milliseconds()).Scan(&got)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *pgStore) Release(ctx context.Context, name, holder string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM worker_leases WHERE name = $1 AND holder = $2`, name, holder)
+	return err
+}
+
+func (s *pgStore) Get(ctx context.Context, name string) (*Lease, error) {
+	l := &Lease{}
+	err := s.pool.QueryRow(ctx, `SELECT name, holder, acquired_at, renewed_at, expires_at FROM worker_leases WHERE name = $1`, name).
+		Scan(&l.Name, &l.Holder, &l.AcquiredAt, &l.RenewedAt, &l.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Elector держит аренду name и запускает работу лидера, пока аренда за этой репликой.
+type Elector struct {
+	store  Store
+	name   string
+	holder string
+	ttl    time.Duration
+}
+
+func NewElector(store Store, name, holder string, ttl time.Duration) *Elector {
+	return &Elector{store: store, name: name, holder: holder, ttl: ttl}
+}
+
+// Run продлевает аренду каждые ttl/3. Получив её, запускает lead с контекстом, который отменяется
+// при потере аренды; при остановке ждёт lead и освобождает аренду, чтобы другая реплика не ждала ttl.
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	var stop context.CancelFunc
+	done := make(chan struct{})
+	resign := func() {
+		if stop != nil {
+			stop()
+			<-done
+			stop = nil
+		}
+	}
+	defer func() {
+		resign()
+		if err := e.store.Release(context.Background(), e.name, e.holder); err != nil {
+			log.Printf("lease %s: release: %v", e.name, err)
+		}
+	}()
+	for {
+		ok, err := e.store.Acquire(ctx, e.name, e.holder, e.ttl)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// Не смогли продлить — считаем, что аренду потеряли: вдруг её уже забрали
+			log.Printf("lease %s: %v", e.name, err)
+			ok = false
+		}
+		switch {
+		case ok && stop == nil:
+			log.Printf("lease %s: %s is now the leader", e.name, e.holder)
+			leadCtx, cancel := context.WithCancel(ctx)
+			stop = cancel
+			done = make(chan struct{})
+			go func(done chan struct{}) {
+				defer close(done)
+				lead(leadCtx)
+			}(done)
+		case !ok && stop != nil:
+			log.Printf("lease %s: %s lost leadership", e.name, e.holder)
+			resign()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
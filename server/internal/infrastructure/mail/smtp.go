@@ -0,0 +1,122 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+type Mailer interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+var ErrNotConfigured = errors.New("smtp is not configured")
+
+type smtpMailer struct {
+	addr     string
+	host     string
+	user     string
+	password string
+	from     string
+}
+
+// NewSMTP — отправка через SMTP-сервер host:port. Без user письма уходят без авторизации
+// (MailHog, локальный relay). STARTTLS включается, если сервер его предлагает.
+func NewSMTP(host, port, user, password, from string) Mailer {
+	return &smtpMailer{addr: net.JoinHostPort(host, port), host: host, user: user, password: password, from: from}
+}
+
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	if m.host == "" || m.from == "" {
+		return ErrNotConfigured
+	}
+	if len(msg.To) == 0 {
+		return errors.New("no recipients")
+	}
+	body, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if m.user != "" {
+		auth = smtp.PlainAuth("", m.user, m.password, m.host)
+	}
+	// net/smtp не принимает context — отправляем в горутине, чтобы остановка worker не ждала таймаута
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(m.addr, auth, m.from, msg.To, body) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// build собирает multipart/mixed: текст письма и вложения в base64.
+func (m *smtpMailer) build(msg *Message) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", m.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: base64\r\n\r\n")
+	writeBase64(&b, []byte(msg.Text))
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s\r\n", a.ContentType)
+		b.WriteString("Content-Transfer-Encoding: base64\r\n")
+		fmt.Fprintf(&b, "Content-Disposition: attachment; filename=%q\r\n\r\n", a.Filename)
+		writeBase64(&b, a.Data)
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
+
+// writeBase64 — строки по 76 символов, как требует RFC 2045.
+func writeBase64(b *bytes.Buffer, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		b.WriteString(enc[:76])
+		b.WriteString("\r\n")
+		enc = enc[76:]
+	}
+	b.WriteString(enc)
+	b.WriteString("\r\n")
+}
+
+func randomBoundary() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("devsync-%x", buf), nil
+}
@@ -0,0 +1,272 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Типы задач worker.
+const (
+	TypeSyncUser        = "sync_user"
+	TypeBackfill        = "backfill"
+	TypeGenerateReport  = "generate_report"
+	TypeRollupStats     = "rollup_stats"     // агрегаты и удаление старых сырых строк у всех пользователей
+	TypeRebuildActivity = "rebuild_activity" // пересчёт дней пользователя после смены часового пояса
+)
+
+const (
+	StatusPending = "pending"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// Приоритеты: ручные действия пользователя обгоняют плановые задачи.
+const (
+	PriorityLow    = 1
+	PriorityNormal = 5
+	PriorityHigh   = 10
+)
+
+type Job struct {
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	UserID      *uuid.UUID      `json:"user_id,omitempty"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	Priority    int             `json:"priority"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   *string         `json:"last_error,omitempty"`
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+}
+
+type EnqueueParams struct {
+	Type        string
+	UserID      *uuid.UUID
+	Payload     interface{} // сериализуется в JSON
+	Priority    int         // 0 → PriorityNormal
+	MaxAttempts int         // 0 → 5
+	RunAt       time.Time   // нулевое — сразу
+	DedupKey    string      // если задача с таким ключом уже ждёт в очереди, возвращается её ID
+}
+
+type Queue interface {
+	Enqueue(ctx context.Context, p EnqueueParams) (uuid.UUID, error)
+	// Dequeue забирает задачу одного из types и блокирует её на visibility; nil, если очередь пуста.
+	Dequeue(ctx context.Context, workerID string, types []string, visibility time.Duration) (*Job, error)
+	// Extend продлевает блокировку; ErrLeaseLost — задача уже не принадлежит workerID.
+	Extend(ctx context.Context, id uuid.UUID, workerID string, visibility time.Duration) error
+	// Complete, Fail, Release и Postpone меняют задачу, только пока её держит workerID: после истечения
+	// блокировки задача могла уйти другому worker.
+	Complete(ctx context.Context, id uuid.UUID, workerID string) error
+	// Fail возвращает задачу в очередь с экспоненциальной задержкой или помечает failed.
+	Fail(ctx context.Context, job *Job, workerID string, cause error, retry bool) error
+	// Release отдаёт задачу обратно без траты попытки (остановка worker).
+	Release(ctx context.Context, id uuid.UUID, workerID string) error
+	// Postpone откладывает задачу до at без траты попытки (например, ресурс занят другой задачей).
+	Postpone(ctx context.Context, id uuid.UUID, workerID string, at time.Time, reason string) error
+	Get(ctx context.Context, id uuid.UUID) (*Job, error)
+}
+
+var (
+	ErrNotFound  = errors.New("job not found")
+	ErrLeaseLost = errors.New("job lease lost")
+)
+
+// UserKey — ключ дедупликации «одна ожидающая задача типа на пользователя».
+func UserKey(jobType string, userID uuid.UUID) string {
+	return jobType + ":" + userID.String()
+}
+
+type pgQueue struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgres(pool *pgxpool.Pool) Queue {
+	return &pgQueue{pool: pool}
+}
+
+func (q *pgQueue) Enqueue(ctx context.Context, p EnqueueParams) (uuid.UUID, error) {
+	if p.Priority == 0 {
+		p.Priority = PriorityNormal
+	}
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = 5
+	}
+	if p.RunAt.IsZero() {
+		p.RunAt = time.Now()
+	}
+	var payload []byte
+	if p.Payload != nil {
+		var err error
+		if payload, err = json.Marshal(p.Payload); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	var dedup *string
+	if p.DedupKey != "" {
+		dedup = &p.DedupKey
+	}
+	var id uuid.UUID
+	err := q.pool.QueryRow(ctx, `INSERT INTO task_queue (type, user_id, payload, priority, max_attempts, run_at, dedup_key, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'pending')
+		ON CONFLICT (dedup_key) WHERE status = 'pending' AND dedup_key IS NOT NULL
+		DO UPDATE SET priority = GREATEST(task_queue.priority, EXCLUDED.priority),
+			run_at = LEAST(task_queue.run_at, EXCLUDED.run_at), updated_at = NOW()
+		RETURNING id`,
+		p.Type, p.UserID, payload, p.Priority, p.MaxAttempts, p.RunAt, dedup,
+	).Scan(&id)
+	return id, err
+}
+
+func (q *pgQueue) Dequeue(ctx context.Context, workerID string, types []string, visibility time.Duration) (*Job, error) {
+	// Зависшие running-задачи (worker упал, не продлив блокировку) подбираются снова.
+	row := q.pool.QueryRow(ctx, `UPDATE task_queue SET status = 'running', attempts = attempts + 1,
+			locked_until = NOW() + $3 * INTERVAL '1 millisecond', locked_by = $1, updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM task_queue
+			WHERE type = ANY($2)
+				AND ((status = 'pending' AND run_at <= NOW()) OR (status = 'running' AND locked_until < NOW()))
+			ORDER BY priority DESC, run_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1)
+		RETURNING `+jobColumns,
+		workerID, types, visibility.Milliseconds())
+	job, err := scanJob(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (q *pgQueue) Extend(ctx context.Context, id uuid.UUID, workerID string, visibility time.Duration) error {
+	tag, err := q.pool.Exec(ctx, `UPDATE task_queue SET locked_until = NOW() + $3 * INTERVAL '1 millisecond', updated_at = NOW()
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'`, id, workerID, visibility.Milliseconds())
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+func (q *pgQueue) Complete(ctx context.Context, id uuid.UUID, workerID string) error {
+	_, err := q.pool.Exec(ctx, `UPDATE task_queue SET status = 'done', processed_at = NOW(), locked_until = NULL,
+		last_error = NULL, updated_at = NOW() WHERE id = $1 AND locked_by = $2 AND status = 'running'`, id, workerID)
+	return err
+}
+
+func (q *pgQueue) Fail(ctx context.Context, job *Job, workerID string, cause error, retry bool) error {
+	msg := cause.Error()
+	if retry && job.Attempts < job.MaxAttempts {
+		runAt := time.Now().Add(Backoff(job.Attempts))
+		return q.requeue(ctx, job.ID, workerID, &runAt, `UPDATE task_queue SET status = 'pending', run_at = $3, locked_until = NULL,
+			locked_by = NULL, last_error = $4, updated_at = NOW() WHERE id = $1 AND locked_by = $2 AND status = 'running'`, job.ID, workerID, runAt, msg)
+	}
+	_, err := q.pool.Exec(ctx, `UPDATE task_queue SET status = 'failed', processed_at = NOW(), locked_until = NULL,
+		last_error = $3, updated_at = NOW() WHERE id = $1 AND locked_by = $2 AND status = 'running'`, job.ID, workerID, msg)
+	return err
+}
+
+func (q *pgQueue) Release(ctx context.Context, id uuid.UUID, workerID string) error {
+	return q.requeue(ctx, id, workerID, nil, `UPDATE task_queue SET status = 'pending', attempts = GREATEST(attempts - 1, 0),
+		locked_until = NULL, locked_by = NULL, updated_at = NOW() WHERE id = $1 AND locked_by = $2 AND status = 'running'`, id, workerID)
+}
+
+func (q *pgQueue) Postpone(ctx context.Context, id uuid.UUID, workerID string, at time.Time, reason string) error {
+	return q.requeue(ctx, id, workerID, &at, `UPDATE task_queue SET status = 'pending', attempts = GREATEST(attempts - 1, 0), run_at = $3,
+		last_error = $4, locked_until = NULL, locked_by = NULL, updated_at = NOW() WHERE id = $1 AND locked_by = $2 AND status = 'running'`, id, workerID, at, reason)
+}
+
+// requeue возвращает running-задачу в pending запросом query. Если такая же задача (тот же dedup_key)
+// уже ждёт в очереди, уникальный индекс не даёт второй pending-строки — тогда задачи сливаются.
+func (q *pgQueue) requeue(ctx context.Context, id uuid.UUID, workerID string, runAt *time.Time, query string, args ...interface{}) error {
+	for attempt := 0; attempt < 3; attempt++ {
+		_, err := q.pool.Exec(ctx, query, args...)
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+			return err
+		}
+		merged, err := q.merge(ctx, id, workerID, runAt)
+		if err != nil || merged {
+			return err
+		}
+		// Ожидающую задачу успели забрать — индекс свободен, пробуем вернуть эту ещё раз
+	}
+	return fmt.Errorf("requeue %s: pending duplicate keeps changing", id)
+}
+
+// merge переносит в ожидающую задачу с тем же dedup_key более ранний run_at и больший приоритет, а эту
+// завершает. runAt nil — срок самой задачи. false — ожидающей задачи уже нет.
+func (q *pgQueue) merge(ctx context.Context, id uuid.UUID, workerID string, runAt *time.Time) (bool, error) {
+	tx, err := q.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	tag, err := tx.Exec(ctx, `UPDATE task_queue p SET run_at = LEAST(p.run_at, COALESCE($3, j.run_at)),
+			priority = GREATEST(p.priority, j.priority), updated_at = NOW()
+		FROM task_queue j
+		WHERE j.id = $1 AND j.locked_by = $2 AND j.status = 'running'
+			AND p.dedup_key = j.dedup_key AND p.status = 'pending' AND p.id <> j.id`, id, workerID, runAt)
+	if err != nil || tag.RowsAffected() == 0 {
+		return false, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE task_queue SET status = 'done', processed_at = NOW(), locked_until = NULL,
+		last_error = NULL, updated_at = NOW() WHERE id = $1 AND locked_by = $2 AND status = 'running'`, id, workerID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (q *pgQueue) Get(ctx context.Context, id uuid.UUID) (*Job, error) {
+	job, err := scanJob(q.pool.QueryRow(ctx, `SELECT `+jobColumns+` FROM task_queue WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return job, err
+}
+
+// Backoff — 30s, 1m, 2m, 4m ... но не больше часа, с разбросом ±20%.
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := 30 * time.Second
+	for i := 1; i < attempt && d < time.Hour; i++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/5*2)) - d/5
+	return d + jitter
+}
+
+const jobColumns = `id, type, user_id, payload, priority, status, attempts, max_attempts, last_error, run_at, COALESCE(created_at, NOW()), processed_at`
+
+func scanJob(row pgx.Row) (*Job, error) {
+	j := &Job{}
+	var createdAt time.Time
+	var processedAt *time.Time
+	err := row.Scan(&j.ID, &j.Type, &j.UserID, &j.Payload, &j.Priority, &j.Status, &j.Attempts, &j.MaxAttempts,
+		&j.LastError, &j.RunAt, &createdAt, &processedAt)
+	if err != nil {
+		return nil, err
+	}
+	j.CreatedAt = createdAt
+	j.ProcessedAt = processedAt
+	return j, nil
+}
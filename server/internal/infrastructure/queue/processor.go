@@ -0,0 +1,191 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+// permanentError — ошибка, после которой повторять задачу бессмысленно.
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent помечает ошибку обработчика как неповторяемую: задача сразу уходит в failed.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+// postponeError — задачу пока нельзя выполнять: она вернётся в очередь к at без траты попытки.
+type postponeError struct {
+	err error
+	at  time.Time
+}
+
+func (e postponeError) Error() string { return e.err.Error() }
+func (e postponeError) Unwrap() error { return e.err }
+
+// Postpone откладывает задачу до at, например, пока тот же ресурс занят другой задачей.
+func Postpone(err error, at time.Time) error {
+	if err == nil {
+		return nil
+	}
+	return postponeError{err: err, at: at}
+}
+
+// Processor забирает задачи из очереди и выполняет зарегистрированные обработчики.
+// Задачи типов без обработчика остаются в очереди для других worker.
+type Processor struct {
+	q            Queue
+	workerID     string
+	handlers     map[string]HandlerFunc
+	Concurrency  int           // сколько задач выполняется одновременно
+	Visibility   time.Duration // на сколько блокируется задача; продлевается, пока обработчик работает
+	PollInterval time.Duration // пауза, когда очередь пуста
+}
+
+func NewProcessor(q Queue, workerID string) *Processor {
+	return &Processor{
+		q:            q,
+		workerID:     workerID,
+		handlers:     make(map[string]HandlerFunc),
+		Concurrency:  1,
+		Visibility:   5 * time.Minute,
+		PollInterval: 5 * time.Second,
+	}
+}
+
+func (p *Processor) Register(jobType string, h HandlerFunc) {
+	p.handlers[jobType] = h
+}
+
+func (p *Processor) types() []string {
+	types := make([]string, 0, len(p.handlers))
+	for t := range p.handlers {
+		types = append(types, t)
+	}
+	return types
+}
+
+// Run запускает Concurrency обработчиков и ждёт, пока все они завершатся после отмены ctx.
+// Задачи, прерванные отменой, возвращаются в очередь. У каждого обработчика свой ID (workerID/N):
+// задачу с истёкшей блокировкой может забрать соседняя горутина того же процесса.
+func (p *Processor) Run(ctx context.Context) error {
+	n := max(p.Concurrency, 1)
+	types := p.types()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(worker string) {
+			defer wg.Done()
+			p.loop(ctx, worker, types)
+		}(fmt.Sprintf("%s/%d", p.workerID, i))
+	}
+	wg.Wait()
+	return nil
+}
+
+func (p *Processor) loop(ctx context.Context, worker string, types []string) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		job, err := p.q.Dequeue(ctx, worker, types, p.Visibility)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("queue: dequeue: %v", err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(p.PollInterval):
+			}
+			continue
+		}
+		p.process(ctx, worker, job)
+	}
+}
+
+func (p *Processor) process(ctx context.Context, worker string, job *Job) {
+	// Статус пишем вне ctx: при остановке задачу нужно успеть вернуть в очередь.
+	store := context.Background()
+	if job.Attempts > job.MaxAttempts {
+		// Задачу брали уже слишком часто, но так и не завершили (worker падал).
+		p.q.Fail(store, job, worker, fmt.Errorf("gave up after %d attempts", job.MaxAttempts), false)
+		return
+	}
+
+	jobCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go p.heartbeat(jobCtx, cancel, worker, job)
+
+	err := p.run(jobCtx, job)
+	switch {
+	case errors.Is(context.Cause(jobCtx), ErrLeaseLost):
+		// Задача уже у другого worker — её состояние не трогаем
+		log.Printf("queue: %s job %s lost its lease and was stopped", job.Type, job.ID)
+	case err == nil:
+		if err := p.q.Complete(store, job.ID, worker); err != nil {
+			log.Printf("queue: complete %s: %v", job.ID, err)
+		}
+	case ctx.Err() != nil:
+		// Остановка worker, а не ошибка задачи.
+		if err := p.q.Release(store, job.ID, worker); err != nil {
+			log.Printf("queue: release %s: %v", job.ID, err)
+		}
+	default:
+		var later postponeError
+		if errors.As(err, &later) {
+			log.Printf("queue: %s job %s postponed until %s: %v", job.Type, job.ID, later.at.Format(time.RFC3339), err)
+			if err := p.q.Postpone(store, job.ID, worker, later.at, err.Error()); err != nil {
+				log.Printf("queue: postpone %s: %v", job.ID, err)
+			}
+			return
+		}
+		var perm permanentError
+		retry := !errors.As(err, &perm)
+		log.Printf("queue: %s job %s failed (attempt %d/%d): %v", job.Type, job.ID, job.Attempts, job.MaxAttempts, err)
+		if err := p.q.Fail(store, job, worker, err, retry); err != nil {
+			log.Printf("queue: fail %s: %v", job.ID, err)
+		}
+	}
+}
+
+func (p *Processor) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return p.handlers[job.Type](ctx, job)
+}
+
+// heartbeat продлевает блокировку, пока обработчик работает, и останавливает его, если блокировка потеряна.
+func (p *Processor) heartbeat(ctx context.Context, cancel context.CancelCauseFunc, worker string, job *Job) {
+	ticker := time.NewTicker(p.Visibility / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := p.q.Extend(ctx, job.ID, worker, p.Visibility)
+			if errors.Is(err, ErrLeaseLost) {
+				cancel(ErrLeaseLost)
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				log.Printf("queue: extend %s: %v", job.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package secrets
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Формат зашифрованного значения: enc:<key id>:<DEK, зашифрованный ключом key id>:<данные, зашифрованные DEK>.
+// Каждое значение шифруется своим случайным ключом данных (DEK), а DEK — мастер-ключом (KEK),
+// поэтому ротация мастер-ключа перешифровывает только DEK. Данные привязаны к месту хранения через
+// associated data (например, "<user id>|access_token"): значение, перенесённое в другую строку или столбец, не расшифруется.
+const prefix = "enc:"
+
+var (
+	ErrUnknownKey = errors.New("secrets: unknown key id")
+	ErrNoKeys     = errors.New("secrets: no encryption keys configured")
+)
+
+type Keyring struct {
+	keys   map[string]cipher.AEAD
+	active string
+}
+
+// NewKeyring — keys: id → 32-байтовый ключ AES-256, active — id ключа для новых записей.
+func NewKeyring(keys map[string][]byte, active string) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("secrets: no keys")
+	}
+	k := &Keyring{keys: make(map[string]cipher.AEAD, len(keys)), active: active}
+	for id, raw := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("secrets: invalid key id %q", id)
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, fmt.Errorf("secrets: key %s: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[active]; !ok {
+		return nil, fmt.Errorf("secrets: active key %q not found", active)
+	}
+	return k, nil
+}
+
+// Load собирает keyring из строки "id:base64,id:base64" и/или файла (по ключу на строку).
+// Без ключей — ErrNoKeys, а с allowPlaintext — nil: токены хранятся как есть.
+func Load(spec, file, active string, allowPlaintext bool) (*Keyring, error) {
+	var entries []string
+	for _, e := range strings.Split(spec, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("secrets: open key file: %w", err)
+		}
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, line)
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("secrets: read key file: %w", err)
+		}
+	}
+	if len(entries) == 0 {
+		if allowPlaintext {
+			return nil, nil
+		}
+		return nil, ErrNoKeys
+	}
+	keys := make(map[string][]byte, len(entries))
+	for _, e := range entries {
+		id, b64, ok := strings.Cut(e, ":")
+		if !ok {
+			return nil, fmt.Errorf("secrets: key entry must be id:base64")
+		}
+		id = strings.TrimSpace(id)
+		if _, dup := keys[id]; dup {
+			return nil, fmt.Errorf("secrets: duplicate key id %q", id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(b64))
+		if err != nil {
+			return nil, fmt.Errorf("secrets: key %s: %w", id, err)
+		}
+		keys[id] = raw
+		if active == "" {
+			active = id // по умолчанию — первый указанный ключ
+		}
+	}
+	return NewKeyring(keys, active)
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	if k == nil {
+		return ""
+	}
+	return k.active
+}
+
+// Encrypt шифрует значение активным ключом; aad — место хранения значения, то же передаётся в Decrypt.
+// Пустые строки и nil keyring — без изменений.
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	dataAEAD, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	data, err := seal(dataAEAD, []byte(plaintext), []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.active], dek, []byte(k.active))
+	if err != nil {
+		return "", err
+	}
+	return prefix + k.active + ":" + encode(wrapped) + ":" + encode(data), nil
+}
+
+// Decrypt расшифровывает значение любой известной версией ключа; значения без префикса
+// (записанные до включения шифрования) возвращаются как есть.
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if k == nil {
+		return "", errors.New("secrets: value is encrypted but no keys configured")
+	}
+	id, wrapped, data, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	kek, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	dek, err := open(kek, wrapped, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("secrets: unwrap data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(dataAEAD, data, []byte(aad))
+	if err != nil {
+		return "", fmt.Errorf("secrets: decrypt: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Rotate перешифровывает значение под активный ключ: для зашифрованных — только DEK,
+// открытые значения шифруются целиком с aad. changed=false, если значение уже на активном ключе.
+func (k *Keyring) Rotate(value, aad string) (rotated string, changed bool, err error) {
+	if k == nil || value == "" {
+		return value, false, nil
+	}
+	if !IsEncrypted(value) {
+		enc, err := k.Encrypt(value, aad)
+		return enc, err == nil, err
+	}
+	id, wrapped, data, err := parse(value)
+	if err != nil {
+		return "", false, err
+	}
+	if id == k.active {
+		return value, false, nil
+	}
+	kek, ok := k.keys[id]
+	if !ok {
+		return "", false, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+	dek, err := open(kek, wrapped, []byte(id))
+	if err != nil {
+		return "", false, fmt.Errorf("secrets: unwrap data key: %w", err)
+	}
+	rewrapped, err := seal(k.keys[k.active], dek, []byte(k.active))
+	if err != nil {
+		return "", false, err
+	}
+	return prefix + k.active + ":" + encode(rewrapped) + ":" + encode(data), true, nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+func parse(value string) (id string, wrapped, data []byte, err error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("secrets: malformed value")
+	}
+	if wrapped, err = decode(parts[1]); err != nil {
+		return "", nil, nil, fmt.Errorf("secrets: malformed value: %w", err)
+	}
+	if data, err = decode(parts[2]); err != nil {
+		return "", nil, nil, fmt.Errorf("secrets: malformed value: %w", err)
+	}
+	return parts[0], wrapped, data, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal возвращает nonce||ciphertext.
+func seal(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ct := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ct, aad)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,202 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const aad = "0b7c1a2e-4f6d-4c1e-9a57-3d2f8e6b1c90|access_token"
+
+func key(b byte) []byte { return bytes.Repeat([]byte{b}, 32) }
+
+func keyring(t *testing.T, active string) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(map[string][]byte{"v1": key(1), "v2": key(2)}, active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := keyring(t, "v1")
+	enc, err := k.Encrypt("gho_secret", aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(enc, "enc:v1:") || strings.Contains(enc, "gho_secret") {
+		t.Fatalf("Encrypt = %q, want enc:v1:… without plaintext", enc)
+	}
+	if got, err := k.Decrypt(enc, aad); err != nil || got != "gho_secret" {
+		t.Errorf("Decrypt = %q, %v, want gho_secret", got, err)
+	}
+	// каждое значение — со своим DEK и nonce
+	if again, _ := k.Encrypt("gho_secret", aad); again == enc {
+		t.Error("Encrypt returned the same ciphertext twice")
+	}
+}
+
+func TestDecryptWrongAAD(t *testing.T) {
+	k := keyring(t, "v1")
+	enc, err := k.Encrypt("gho_secret", aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// значение, скопированное в другой столбец или другому пользователю, не расшифровывается
+	for _, other := range []string{
+		"0b7c1a2e-4f6d-4c1e-9a57-3d2f8e6b1c90|refresh_token",
+		"5e2d9f0a-1b3c-4d5e-8f7a-6b9c0d1e2f3a|access_token",
+		"",
+	} {
+		if _, err := k.Decrypt(enc, other); err == nil {
+			t.Errorf("Decrypt with aad %q succeeded", other)
+		}
+	}
+}
+
+func TestDecryptPlaintextAndEmpty(t *testing.T) {
+	k := keyring(t, "v1")
+	for _, v := range []string{"", "gho_legacy"} {
+		if got, err := k.Decrypt(v, aad); err != nil || got != v {
+			t.Errorf("Decrypt(%q) = %q, %v", v, got, err)
+		}
+	}
+	if got, _ := k.Encrypt("", aad); got != "" {
+		t.Errorf("Encrypt(\"\") = %q, want empty", got)
+	}
+}
+
+func TestDecryptUnknownKey(t *testing.T) {
+	enc, err := keyring(t, "v2").Encrypt("gho_secret", aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := NewKeyring(map[string][]byte{"v1": key(1)}, "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Decrypt(enc, aad); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Decrypt error = %v, want ErrUnknownKey", err)
+	}
+	if _, _, err := k.Rotate(enc, aad); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Rotate error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	old, err := keyring(t, "v1").Encrypt("gho_secret", aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := keyring(t, "v2")
+	rotated, changed, err := k.Rotate(old, aad)
+	if err != nil || !changed {
+		t.Fatalf("Rotate = %v, %v, want changed", changed, err)
+	}
+	if !strings.HasPrefix(rotated, "enc:v2:") {
+		t.Errorf("Rotate = %q, want enc:v2:…", rotated)
+	}
+	// перешифровывается только DEK, данные остаются прежними
+	if old[strings.LastIndex(old, ":"):] != rotated[strings.LastIndex(rotated, ":"):] {
+		t.Error("Rotate re-encrypted the data, want only the data key rewrapped")
+	}
+	if got, err := k.Decrypt(rotated, aad); err != nil || got != "gho_secret" {
+		t.Errorf("Decrypt after Rotate = %q, %v", got, err)
+	}
+	if again, changed, err := k.Rotate(rotated, aad); err != nil || changed || again != rotated {
+		t.Errorf("Rotate on active key = %v, %v, want unchanged", changed, err)
+	}
+}
+
+func TestRotatePlaintext(t *testing.T) {
+	k := keyring(t, "v2")
+	enc, changed, err := k.Rotate("gho_legacy", aad)
+	if err != nil || !changed || !strings.HasPrefix(enc, "enc:v2:") {
+		t.Fatalf("Rotate plaintext = %q, %v, %v", enc, changed, err)
+	}
+	if got, err := k.Decrypt(enc, aad); err != nil || got != "gho_legacy" {
+		t.Errorf("Decrypt = %q, %v", got, err)
+	}
+	if v, changed, err := k.Rotate("", aad); err != nil || changed || v != "" {
+		t.Errorf("Rotate(\"\") = %q, %v, %v", v, changed, err)
+	}
+}
+
+func TestNilKeyring(t *testing.T) {
+	var k *Keyring
+	if got, err := k.Encrypt("gho_secret", aad); err != nil || got != "gho_secret" {
+		t.Errorf("nil Encrypt = %q, %v", got, err)
+	}
+	if got, err := k.Decrypt("gho_secret", aad); err != nil || got != "gho_secret" {
+		t.Errorf("nil Decrypt = %q, %v", got, err)
+	}
+	enc, _ := keyring(t, "v1").Encrypt("gho_secret", aad)
+	if _, err := k.Decrypt(enc, aad); err == nil {
+		t.Error("nil Decrypt of encrypted value succeeded")
+	}
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   map[string][]byte
+		active string
+	}{
+		{"no keys", nil, "v1"},
+		{"short key", map[string][]byte{"v1": key(1)[:16]}, "v1"},
+		{"colon in id", map[string][]byte{"v:1": key(1)}, "v:1"},
+		{"empty id", map[string][]byte{"": key(1)}, ""},
+		{"missing active", map[string][]byte{"v1": key(1)}, "v2"},
+	}
+	for _, tt := range tests {
+		if _, err := NewKeyring(tt.keys, tt.active); err == nil {
+			t.Errorf("%s: NewKeyring succeeded", tt.name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	b64 := func(b byte) string { return base64.StdEncoding.EncodeToString(key(b)) }
+	file := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(file, []byte("# старый ключ\nv1:"+b64(1)+"\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := Load("v2:"+b64(2), file, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// по умолчанию активен первый ключ из строки
+	if k.ActiveKeyID() != "v2" {
+		t.Errorf("active = %q, want v2", k.ActiveKeyID())
+	}
+	old, _ := keyring(t, "v1").Encrypt("gho_secret", aad)
+	if got, err := k.Decrypt(old, aad); err != nil || got != "gho_secret" {
+		t.Errorf("Decrypt with key from file = %q, %v", got, err)
+	}
+
+	if _, err := Load("v1:"+b64(1)+",v1:"+b64(2), "", "", false); err == nil {
+		t.Error("Load with duplicate ids succeeded")
+	}
+	if _, err := Load("v1:"+b64(2), file, "", false); err == nil {
+		t.Error("Load with id duplicated in file succeeded")
+	}
+	if _, err := Load("v1", "", "", false); err == nil {
+		t.Error("Load without base64 part succeeded")
+	}
+}
+
+func TestLoadWithoutKeys(t *testing.T) {
+	if _, err := Load(" , ", "", "", false); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("Load error = %v, want ErrNoKeys", err)
+	}
+	// явный отказ от шифрования
+	if k, err := Load("", "", "", true); err != nil || k != nil {
+		t.Errorf("Load with plaintext allowed = %v, %v, want nil keyring", k, err)
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/achievements"
+)
+
+type AchievementsHandler struct {
+	achievementSvc achievements.Service
+}
+
+func NewAchievementsHandler(achievementSvc achievements.Service) *AchievementsHandler {
+	return &AchievementsHandler{achievementSvc: achievementSvc}
+}
+
+// List — GET /api/achievements: полученные достижения и закрытые с прогрессом.
+func (h *AchievementsHandler) List(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	list, err := h.achievementSvc.List(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"io"
+	"log"
 	"net/http"
 	"time"
 	"github.com/gin-gonic/gin"
@@ -10,6 +11,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/devsync/server/internal/domain/user"
+	"github.com/devsync/server/internal/domain/backfill"
 )
 
 type AuthHandler struct {
@@ -17,13 +19,14 @@ type AuthHandler struct {
 	jwtSecret      string
 	jwtExpireHours int
 	userSvc        user.Service
+	backfillSvc    backfill.Service
 }
 
-func NewAuthHandler(oauth *oauth2.Config, jwtSecret string, jwtExpireHours int, userSvc user.Service) *AuthHandler {
+func NewAuthHandler(oauth *oauth2.Config, jwtSecret string, jwtExpireHours int, userSvc user.Service, backfillSvc backfill.Service) *AuthHandler {
 	if jwtExpireHours <= 0 {
 		jwtExpireHours = 24
 	}
-	return &AuthHandler{oauth: oauth, jwtSecret: jwtSecret, jwtExpireHours: jwtExpireHours, userSvc: userSvc}
+	return &AuthHandler{oauth: oauth, jwtSecret: jwtSecret, jwtExpireHours: jwtExpireHours, userSvc: userSvc, backfillSvc: backfillSvc}
 }
 
 // GitHubCheck — проверка настроек OAuth без редиректа (для фронта: показать ошибку на странице входа).
@@ -140,10 +143,17 @@ func (h *AuthHandler) GitHubCallback(c *gin.Context) {
 	if token.RefreshToken != "" {
 		u.RefreshToken = &token.RefreshToken
 	}
+	if !token.Expiry.IsZero() {
+		u.TokenExpiresAt = &token.Expiry
+	}
 	if err := h.userSvc.CreateOrUpdate(c.Request.Context(), u); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "save user failed", "detail": err.Error()})
 		return
 	}
+	// Новому пользователю подтягиваем историю активности; вход от этого не зависит
+	if err := h.backfillSvc.EnsureStarted(c.Request.Context(), u.ID); err != nil {
+		log.Printf("auth: start backfill for %s: %v", u.ID, err)
+	}
 	claims := &middlewareClaims{
 		UserID: u.ID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
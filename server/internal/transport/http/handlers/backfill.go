@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/backfill"
+)
+
+type BackfillHandler struct {
+	backfillSvc backfill.Service
+}
+
+func NewBackfillHandler(backfillSvc backfill.Service) *BackfillHandler {
+	return &BackfillHandler{backfillSvc: backfillSvc}
+}
+
+// Status — GET /api/user/backfill: прогресс загрузки истории.
+func (h *BackfillHandler) Status(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	st, err := h.backfillSvc.Status(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// Start — POST /api/user/backfill {"years": 3}: загрузить историю заново; 202 с job_id.
+// Ход приходит по websocket: backfill_progress, backfill_finished.
+func (h *BackfillHandler) Start(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	var body struct {
+		Years int `json:"years"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	jobID, err := h.backfillSvc.Start(c.Request.Context(), userID, body.Years)
+	switch {
+	case errors.Is(err, backfill.ErrInvalidYears):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, backfill.ErrRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": "backfill_in_progress", "job_id": jobID})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"job_id": jobID, "status": backfill.StatusPending})
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/collab"
+)
+
+type CollaborationHandler struct {
+	collabSvc collab.Service
+}
+
+func NewCollaborationHandler(collabSvc collab.Service) *CollaborationHandler {
+	return &CollaborationHandler{collabSvc: collabSvc}
+}
+
+// Collaborators — GET /api/user/collaborators?limit=20
+func (h *CollaborationHandler) Collaborators(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	limit, ok := queryLimit(c, 20, 100)
+	if !ok {
+		return
+	}
+	list, err := h.collabSvc.TopCollaborators(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Graph — GET /api/user/collaborators/graph?limit=30: nodes/edges для отрисовки графа.
+func (h *CollaborationHandler) Graph(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	limit, ok := queryLimit(c, 30, 100)
+	if !ok {
+		return
+	}
+	g, err := h.collabSvc.Graph(c.Request.Context(), userID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+// queryLimit читает ?limit= в пределах (0, max]; при ошибке отвечает 400 и возвращает false.
+func queryLimit(c *gin.Context, def, max int) (int, bool) {
+	v := c.Query("limit")
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+		return 0, false
+	}
+	if n > max {
+		n = max
+	}
+	return n, true
+}
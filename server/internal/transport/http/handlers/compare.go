@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/compare"
+	"github.com/devsync/server/internal/domain/stats"
+)
+
+type CompareHandler struct {
+	compareSvc compare.Service
+}
+
+func NewCompareHandler(compareSvc compare.Service) *CompareHandler {
+	return &CompareHandler{compareSvc: compareSvc}
+}
+
+// Compare — GET /api/compare?users=alice,bob,carol&period=quarter: выровненная статистика пользователей.
+func (h *CompareHandler) Compare(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	cmp, err := h.compareSvc.Compare(c.Request.Context(), userIDVal.(uuid.UUID), c.QueryArray("users"), periodQuery(c))
+	if err != nil {
+		compareError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, cmp)
+}
+
+func compareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, compare.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, compare.ErrInvalidUsers), errors.Is(err, stats.ErrInvalidPeriod):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/goals"
+	"github.com/devsync/server/internal/domain/models"
+)
+
+type GoalsHandler struct {
+	goalSvc goals.Service
+}
+
+func NewGoalsHandler(goalSvc goals.Service) *GoalsHandler {
+	return &GoalsHandler{goalSvc: goalSvc}
+}
+
+type goalRequest struct {
+	Metric string `json:"metric"`
+	Target int    `json:"target"`
+	Period string `json:"period"`
+	Active *bool  `json:"active"`
+}
+
+func (r *goalRequest) goal() *models.Goal {
+	g := &models.Goal{Metric: r.Metric, Target: r.Target, Period: r.Period, Active: true}
+	if r.Active != nil {
+		g.Active = *r.Active
+	}
+	return g
+}
+
+// List — GET /api/goals.
+func (h *GoalsHandler) List(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	list, err := h.goalSvc.List(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Create — POST /api/goals {"metric":"commits","target":20,"period":"week"}.
+func (h *GoalsHandler) Create(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var body goalRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	g := body.goal()
+	if err := h.goalSvc.Create(c.Request.Context(), userIDVal.(uuid.UUID), g); err != nil {
+		goalError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+// Update — PUT /api/goals/:id, тело как при создании (active: false — приостановить).
+func (h *GoalsHandler) Update(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "goal not found"})
+		return
+	}
+	var body goalRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	g := body.goal()
+	g.ID = id
+	if err := h.goalSvc.Update(c.Request.Context(), userIDVal.(uuid.UUID), g); err != nil {
+		goalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+// Delete — DELETE /api/goals/:id.
+func (h *GoalsHandler) Delete(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "goal not found"})
+		return
+	}
+	if err := h.goalSvc.Delete(c.Request.Context(), userIDVal.(uuid.UUID), id); err != nil {
+		goalError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// History — GET /api/goals/:id/history?limit=12: итоги по периодам, новые первыми.
+func (h *GoalsHandler) History(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "goal not found"})
+		return
+	}
+	limit, ok := queryLimit(c, 12, 120)
+	if !ok {
+		return
+	}
+	list, err := h.goalSvc.History(c.Request.Context(), userIDVal.(uuid.UUID), id, limit)
+	if err != nil {
+		goalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func goalError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, goals.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "goal not found"})
+	case errors.Is(err, goals.ErrInvalidGoal):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, goals.ErrTooManyGoals):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/models"
+	"github.com/devsync/server/internal/domain/profile"
+	"github.com/devsync/server/internal/domain/stats"
+)
+
+type ProfileHandler struct {
+	profileSvc profile.Service
+}
+
+func NewProfileHandler(profileSvc profile.Service) *ProfileHandler {
+	return &ProfileHandler{profileSvc: profileSvc}
+}
+
+// Visibility — GET /api/user/profile/visibility
+func (h *ProfileHandler) Visibility(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	v, err := h.profileSvc.Visibility(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+// UpdateVisibility — PUT /api/user/profile/visibility {"public": true, "sections": ["totals", "streaks"]}
+func (h *ProfileHandler) UpdateVisibility(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var v models.ProfileVisibility
+	if err := c.ShouldBindJSON(&v); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	err := h.profileSvc.UpdateVisibility(c.Request.Context(), userIDVal.(uuid.UUID), &v)
+	if errors.Is(err, profile.ErrInvalidVisibility) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, v)
+}
+
+// Stats — GET /api/public/:username/stats?period=month: открытые разделы профиля, без авторизации.
+func (h *ProfileHandler) Stats(c *gin.Context) {
+	p, err := h.profileSvc.Stats(c.Request.Context(), c.Param("username"), periodQuery(c))
+	if err != nil {
+		profileError(c, err)
+		return
+	}
+	publicCache(c)
+	c.JSON(http.StatusOK, p)
+}
+
+// Contributions — GET /api/public/:username/contributions?period=year
+func (h *ProfileHandler) Contributions(c *gin.Context) {
+	days, err := h.profileSvc.Contributions(c.Request.Context(), c.Param("username"), periodQuery(c))
+	if err != nil {
+		profileError(c, err)
+		return
+	}
+	publicCache(c)
+	c.JSON(http.StatusOK, days)
+}
+
+// Repos — GET /api/public/:username/repos: те же фильтры, что у /api/user/repos, только публичные репозитории.
+func (h *ProfileHandler) Repos(c *gin.Context) {
+	f, ok := repoFilter(c)
+	if !ok {
+		return
+	}
+	repos, err := h.profileSvc.Repos(c.Request.Context(), c.Param("username"), f)
+	if err != nil {
+		profileError(c, err)
+		return
+	}
+	publicCache(c)
+	c.JSON(http.StatusOK, repos)
+}
+
+// publicCache разрешает кэшировать ответ так же долго, как его держит сервер.
+func publicCache(c *gin.Context) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(profile.CacheTTL.Seconds())))
+}
+
+func profileError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, profile.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, stats.ErrInvalidPeriod):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
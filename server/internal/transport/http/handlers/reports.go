@@ -1,109 +1,214 @@
 package handlers
 
 import (
-	"context"
-	"fmt"
+	"errors"
 	"net/http"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/devsync/server/internal/domain/user"
-	"github.com/devsync/server/internal/domain/models"
-	"github.com/devsync/server/pkg/pdf"
+	"github.com/devsync/server/internal/domain/report"
+	"github.com/devsync/server/internal/domain/stats"
 )
 
 type ReportsHandler struct {
-	userSvc  user.Service
-	statsSvc reportsStatsService
-	pdfGen   *pdf.Generator
+	reportSvc report.Service
 }
 
-type reportsStatsService interface {
-	GetUserStats(ctx context.Context, userID uuid.UUID) (*models.UserStats, error)
+func NewReportsHandler(reportSvc report.Service) *ReportsHandler {
+	return &ReportsHandler{reportSvc: reportSvc}
 }
 
-func NewReportsHandler(userSvc user.Service, statsSvc reportsStatsService, pdfGen *pdf.Generator) *ReportsHandler {
-	return &ReportsHandler{userSvc: userSvc, statsSvc: statsSvc, pdfGen: pdfGen}
+func (h *ReportsHandler) PDF(c *gin.Context) {
+	h.download(c, report.FormatPDF)
 }
 
-func (h *ReportsHandler) PDF(c *gin.Context) {
+func (h *ReportsHandler) Markdown(c *gin.Context) {
+	h.download(c, report.FormatMarkdown)
+}
+
+// download — GET /api/reports/{pdf,markdown}?period=last_quarter или ?from=&to= (по умолчанию year).
+func (h *ReportsHandler) download(c *gin.Context, format string) {
 	userIDVal, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 	userID := userIDVal.(uuid.UUID)
-	u, err := h.userSvc.GetByID(c.Request.Context(), userID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	doc, err := h.reportSvc.Build(c.Request.Context(), userID, format, periodQuery(c))
+	if errors.Is(err, stats.ErrInvalidPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	statsData, err := h.statsSvc.GetUserStats(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	rd := toReportData(u.Username, statsData)
-	data, err := h.pdfGen.Generate(u.Username, rd)
+	c.Header("Content-Disposition", "attachment; filename="+doc.Filename)
+	c.Data(http.StatusOK, doc.ContentType, doc.Data)
+}
+
+// ComparePDF — GET /api/reports/compare/pdf?users=alice,bob&period=quarter: PDF сравнения, доступ как у /api/compare.
+func (h *ReportsHandler) ComparePDF(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	doc, err := h.reportSvc.BuildComparison(c.Request.Context(), userIDVal.(uuid.UUID), c.QueryArray("users"), periodQuery(c))
+	if err != nil {
+		compareError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+doc.Filename)
+	c.Data(http.StatusOK, doc.ContentType, doc.Data)
+}
+
+type subscriptionRequest struct {
+	Format     string   `json:"format"`
+	Period     string   `json:"period"`
+	Cadence    string   `json:"cadence"`
+	Recipients []string `json:"recipients"`
+	Active     *bool    `json:"active"`
+}
+
+func (r *subscriptionRequest) subscription() *report.Subscription {
+	s := &report.Subscription{Format: r.Format, Period: r.Period, Cadence: r.Cadence, Recipients: r.Recipients, Active: true}
+	if r.Active != nil {
+		s.Active = *r.Active
+	}
+	return s
+}
+
+// Subscriptions — GET /api/reports/subscriptions.
+func (h *ReportsHandler) Subscriptions(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	subs, err := h.reportSvc.List(c.Request.Context(), userIDVal.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.Header("Content-Disposition", "attachment; filename=devsync-report.pdf")
-	c.Data(http.StatusOK, "application/pdf", data)
+	c.JSON(http.StatusOK, subs)
 }
 
-func (h *ReportsHandler) Markdown(c *gin.Context) {
+// CreateSubscription — POST /api/reports/subscriptions
+// {"format":"pdf","period":"week","cadence":"weekly","recipients":["me@example.com"]}.
+func (h *ReportsHandler) CreateSubscription(c *gin.Context) {
 	userIDVal, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	userID := userIDVal.(uuid.UUID)
-	u, err := h.userSvc.GetByID(c.Request.Context(), userID)
+	var body subscriptionRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	sub, err := h.reportSvc.Create(c.Request.Context(), userIDVal.(uuid.UUID), body.subscription())
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		subscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// UpdateSubscription — PUT /api/reports/subscriptions/:id, тело как при создании.
+func (h *ReportsHandler) UpdateSubscription(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	statsData, err := h.statsSvc.GetUserStats(c.Request.Context(), userID)
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
 		return
 	}
-	md := generateMarkdown(u.Username, statsData)
-	c.Header("Content-Disposition", "attachment; filename=README-stats.md")
-	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(md))
+	var body subscriptionRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	sub := body.subscription()
+	sub.ID = id
+	sub, err = h.reportSvc.Update(c.Request.Context(), userIDVal.(uuid.UUID), sub)
+	if err != nil {
+		subscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubscription — DELETE /api/reports/subscriptions/:id.
+func (h *ReportsHandler) DeleteSubscription(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+	if err := h.reportSvc.Delete(c.Request.Context(), userIDVal.(uuid.UUID), id); err != nil {
+		subscriptionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries — GET /api/reports/subscriptions/:id/deliveries: последние отправки и их статус.
+func (h *ReportsHandler) Deliveries(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
+	}
+	ds, err := h.reportSvc.Deliveries(c.Request.Context(), userIDVal.(uuid.UUID), id)
+	if err != nil {
+		subscriptionError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, ds)
 }
 
-func toReportData(username string, s *models.UserStats) *pdf.ReportData {
-	rd := &pdf.ReportData{
-		Username:        username,
-		TotalRepos:      s.TotalRepos,
-		TotalStars:      s.TotalStars,
-		TotalForks:      s.TotalForks,
-		ContributionSum: s.ContributionSum,
+// SendNow — POST /api/reports/subscriptions/:id/send: отправить отчёт вне расписания; 202 с доставкой.
+func (h *ReportsHandler) SendNow(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
 	}
-	for _, r := range s.TopRepos {
-		rd.TopRepos = append(rd.TopRepos, pdf.RepoSummary{Name: r.Name, Stars: r.Stars, Forks: r.Forks, Language: r.Language})
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+		return
 	}
-	for _, l := range s.Languages {
-		rd.Languages = append(rd.Languages, pdf.LangSummary{Language: l.Language, Percent: l.Percent})
+	d, err := h.reportSvc.SendNow(c.Request.Context(), userIDVal.(uuid.UUID), id)
+	if err != nil {
+		subscriptionError(c, err)
+		return
 	}
-	return rd
+	c.JSON(http.StatusAccepted, d)
 }
 
-func generateMarkdown(username string, s *models.UserStats) string {
-	md := fmt.Sprintf("# GitHub Stats — %s\n\n", username)
-	md += fmt.Sprintf("- **Repositories:** %d\n", s.TotalRepos)
-	md += fmt.Sprintf("- **Stars:** %d\n", s.TotalStars)
-	md += fmt.Sprintf("- **Forks:** %d\n", s.TotalForks)
-	md += fmt.Sprintf("- **Contributions (year):** %d\n\n", s.ContributionSum)
-	md += "## Top Repositories\n\n"
-	for _, r := range s.TopRepos {
-		md += fmt.Sprintf("- [%s](https://github.com/%s) — ⭐ %d | 🍴 %d | %s\n", r.Name, r.FullName, r.Stars, r.Forks, r.Language)
-	}
-	md += "\n## Languages\n\n"
-	for _, l := range s.Languages {
-		md += fmt.Sprintf("- %s: %.1f%%\n", l.Language, l.Percent)
-	}
-	return md
+func subscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, report.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+	case errors.Is(err, report.ErrInvalidSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, report.ErrTooManySubscriptions):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/schedule"
+)
+
+type ScheduleHandler struct {
+	scheduleSvc schedule.Service
+}
+
+func NewScheduleHandler(scheduleSvc schedule.Service) *ScheduleHandler {
+	return &ScheduleHandler{scheduleSvc: scheduleSvc}
+}
+
+// Get — GET /api/user/sync/schedule
+func (h *ScheduleHandler) Get(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	s, err := h.scheduleSvc.Get(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// Update — PUT /api/user/sync/schedule: {"mode":"adaptive"}, {"mode":"interval","interval_minutes":360}
+// или {"mode":"cron","cron":"0 9 * * 1-5"} (cron — в часовом поясе пользователя).
+func (h *ScheduleHandler) Update(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	var body struct {
+		Mode            string  `json:"mode" binding:"required"`
+		IntervalMinutes *int    `json:"interval_minutes"`
+		Cron            *string `json:"cron"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	s, err := h.scheduleSvc.Update(c.Request.Context(), userID, &schedule.Schedule{
+		Mode:            body.Mode,
+		IntervalMinutes: body.IntervalMinutes,
+		Cron:            body.Cron,
+	})
+	if errors.Is(err, schedule.ErrInvalidSchedule) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
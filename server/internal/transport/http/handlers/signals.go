@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/signals"
+)
+
+type SignalsHandler struct {
+	signalSvc signals.Service
+}
+
+func NewSignalsHandler(signalSvc signals.Service) *SignalsHandler {
+	return &SignalsHandler{signalSvc: signalSvc}
+}
+
+// Signals — GET /api/user/signals?limit=20: сигналы по последней неделе и история сохранённых.
+func (h *SignalsHandler) Signals(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	limit, ok := queryLimit(c, 20, 100)
+	if !ok {
+		return
+	}
+	rep, err := h.signalSvc.Get(c.Request.Context(), userIDVal.(uuid.UUID), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
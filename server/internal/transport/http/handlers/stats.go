@@ -1,21 +1,27 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"time"
+	"strconv"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/goals"
 	"github.com/devsync/server/internal/domain/stats"
 )
 
 type StatsHandler struct {
 	statsSvc stats.Service
+	goalSvc  goals.Service
 }
 
-func NewStatsHandler(statsSvc stats.Service) *StatsHandler {
-	return &StatsHandler{statsSvc: statsSvc}
+func NewStatsHandler(statsSvc stats.Service, goalSvc goals.Service) *StatsHandler {
+	return &StatsHandler{statsSvc: statsSvc, goalSvc: goalSvc}
 }
 
+// UserStats — GET /api/user/stats?period=last_month&compare=previous или ?from=2026-01-01&to=2026-03-31
+// (period — см. stats.ResolvePeriod; compare — previous или same_period_last_year)
 func (h *StatsHandler) UserStats(c *gin.Context) {
 	userIDVal, exists := c.Get("user_id")
 	if !exists {
@@ -23,24 +29,75 @@ func (h *StatsHandler) UserStats(c *gin.Context) {
 		return
 	}
 	userID := userIDVal.(uuid.UUID)
-	period := c.DefaultQuery("period", "year") // week, month, year
-	s, err := h.statsSvc.GetUserStatsWithPeriod(c.Request.Context(), userID, period)
+	q := periodQuery(c)
+	s, err := h.statsSvc.GetUserStatsWithPeriod(c.Request.Context(), userID, q)
+	if errors.Is(err, stats.ErrInvalidPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if compare := c.Query("compare"); compare != "" {
+		cmp, err := h.statsSvc.ComparePeriods(c.Request.Context(), userID, q, compare)
+		if errors.Is(err, stats.ErrInvalidCompare) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		s.Comparison = cmp
+	}
+	if s.Goals, err = h.goalSvc.Current(c.Request.Context(), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusOK, s)
 }
 
+// Punchcard — GET /api/user/punchcard?period=last_month: вклады по дню недели и часу.
+func (h *StatsHandler) Punchcard(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	pc, err := h.statsSvc.GetPunchcard(c.Request.Context(), userIDVal.(uuid.UUID), periodQuery(c))
+	if errors.Is(err, stats.ErrInvalidPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pc)
+}
+
+// periodQuery — period, from и to из строки запроса; общий для статистики и отчётов.
+func periodQuery(c *gin.Context) stats.PeriodQuery {
+	return stats.PeriodQuery{Period: c.Query("period"), From: c.Query("from"), To: c.Query("to")}
+}
+
+// Repos — GET /api/user/repos?fork=false&archived=false&topic=go&language=Go&sort=pushed&order=desc&limit=50
 func (h *StatsHandler) Repos(c *gin.Context) {
 	userIDVal, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	userID := userIDVal.(uuid.UUID)
-	limit := 50
-	repos, err := h.statsSvc.GetRepos(c.Request.Context(), userID, limit)
+	f, ok := repoFilter(c)
+	if !ok {
+		return
+	}
+	repos, err := h.statsSvc.GetRepos(c.Request.Context(), userIDVal.(uuid.UUID), f)
+	if errors.Is(err, stats.ErrInvalidRepoFilter) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -55,18 +112,63 @@ func (h *StatsHandler) Contributions(c *gin.Context) {
 		return
 	}
 	userID := userIDVal.(uuid.UUID)
-	to := time.Now().Format("2006-01-02")
-	from := time.Now().AddDate(0, 0, -365).Format("2006-01-02")
-	if f := c.Query("from"); f != "" {
-		from = f
-	}
-	if t := c.Query("to"); t != "" {
-		to = t
-	}
+	// по умолчанию — последний год в часовом поясе пользователя
+	from, to := c.Query("from"), c.Query("to")
 	contribs, err := h.statsSvc.GetContributions(c.Request.Context(), userID, from, to)
+	if errors.Is(err, stats.ErrInvalidPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, contribs)
 }
+
+// repoFilter разбирает фильтры списка репозиториев; при ошибке уже ответил 400.
+func repoFilter(c *gin.Context) (stats.RepoFilter, bool) {
+	f := stats.RepoFilter{
+		Topic:    c.Query("topic"),
+		Language: c.Query("language"),
+		License:  c.Query("license"),
+		Sort:     c.Query("sort"),
+		Order:    c.Query("order"),
+		Limit:    50,
+	}
+	for name, dst := range map[string]**bool{"fork": &f.Fork, "archived": &f.Archived, "template": &f.Template, "private": &f.Private} {
+		v, err := queryBool(c, name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return f, false
+		}
+		*dst = v
+	}
+	for name, dst := range map[string]*int{"limit": &f.Limit, "offset": &f.Offset} {
+		if v := c.Query(name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+				return f, false
+			}
+			*dst = n
+		}
+	}
+	if f.Limit == 0 || f.Limit > 100 {
+		f.Limit = 100
+	}
+	return f, true
+}
+
+// queryBool — необязательный булев query-параметр: nil, если не передан.
+func queryBool(c *gin.Context, name string) (*bool, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: expected true or false", name)
+	}
+	return &b, nil
+}
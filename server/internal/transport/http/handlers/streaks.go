@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/stats"
+)
+
+// Streaks — GET /api/user/streaks: текущая и самая длинная серия и история серий.
+func (h *StatsHandler) Streaks(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	st, err := h.statsSvc.GetStreaks(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// StreakSettings — GET /api/user/streaks/settings.
+func (h *StatsHandler) StreakSettings(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	set, err := h.statsSvc.StreakSettings(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, set)
+}
+
+// UpdateStreakSettings — PUT /api/user/streaks/settings {"rest_weekends": true, "min_length": 3}.
+func (h *StatsHandler) UpdateStreakSettings(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	cur, err := h.statsSvc.StreakSettings(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Поля, которых нет в теле, остаются прежними
+	if err := c.ShouldBindJSON(cur); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	set, err := h.statsSvc.UpdateStreakSettings(c.Request.Context(), userID, *cur)
+	if errors.Is(err, stats.ErrInvalidStreakSettings) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, set)
+}
+
+// RestDays — GET /api/user/rest-days: объявленные дни отдыха.
+func (h *StatsHandler) RestDays(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	days, err := h.statsSvc.RestDays(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, days)
+}
+
+// AddRestDays — POST /api/user/rest-days {"from": "2026-08-01", "to": "2026-08-14", "note": "vacation"}.
+func (h *StatsHandler) AddRestDays(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var body struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Note string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	if err := h.statsSvc.AddRestDays(c.Request.Context(), userIDVal.(uuid.UUID), body.From, body.To, body.Note); err != nil {
+		restDaysError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteRestDays — DELETE /api/user/rest-days?from=2026-08-01&to=2026-08-14 (без to — один день).
+func (h *StatsHandler) DeleteRestDays(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	n, err := h.statsSvc.DeleteRestDays(c.Request.Context(), userIDVal.(uuid.UUID), c.Query("from"), c.Query("to"))
+	if err != nil {
+		restDaysError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": n})
+}
+
+func restDaysError(c *gin.Context, err error) {
+	if errors.Is(err, stats.ErrInvalidRestDays) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/devsync/server/internal/domain/report"
+	"github.com/devsync/server/internal/domain/stats"
+	"github.com/devsync/server/internal/domain/team"
+)
+
+type TeamsHandler struct {
+	teamSvc   team.Service
+	reportSvc report.Service
+}
+
+func NewTeamsHandler(teamSvc team.Service, reportSvc report.Service) *TeamsHandler {
+	return &TeamsHandler{teamSvc: teamSvc, reportSvc: reportSvc}
+}
+
+type teamRequest struct {
+	Name string `json:"name"`
+}
+
+type inviteRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+type roleRequest struct {
+	Role string `json:"role"`
+}
+
+// List — GET /api/teams: команды, в которых состоит пользователь, с его ролью.
+func (h *TeamsHandler) List(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	list, err := h.teamSvc.List(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Create — POST /api/teams {"name":"Platform"}: создатель становится владельцем.
+func (h *TeamsHandler) Create(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	var body teamRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	t, err := h.teamSvc.Create(c.Request.Context(), userIDVal.(uuid.UUID), body.Name)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, t)
+}
+
+// Get — GET /api/teams/:id.
+func (h *TeamsHandler) Get(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	t, err := h.teamSvc.Get(c.Request.Context(), userID, teamID)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Rename — PUT /api/teams/:id {"name":"..."}: владелец или администратор.
+func (h *TeamsHandler) Rename(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	var body teamRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	t, err := h.teamSvc.Rename(c.Request.Context(), userID, teamID, body.Name)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Delete — DELETE /api/teams/:id: только владелец.
+func (h *TeamsHandler) Delete(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	if err := h.teamSvc.Delete(c.Request.Context(), userID, teamID); err != nil {
+		teamError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Members — GET /api/teams/:id/members.
+func (h *TeamsHandler) Members(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	list, err := h.teamSvc.Members(c.Request.Context(), userID, teamID)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// UpdateMember — PUT /api/teams/:id/members/:user_id {"role":"admin"}: только владелец.
+func (h *TeamsHandler) UpdateMember(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": team.ErrMemberNotFound.Error()})
+		return
+	}
+	var body roleRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	if err := h.teamSvc.UpdateMember(c.Request.Context(), userID, teamID, memberID, body.Role); err != nil {
+		teamError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveMember — DELETE /api/teams/:id/members/:user_id; свой user_id — выйти из команды.
+func (h *TeamsHandler) RemoveMember(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": team.ErrMemberNotFound.Error()})
+		return
+	}
+	if err := h.teamSvc.RemoveMember(c.Request.Context(), userID, teamID, memberID); err != nil {
+		teamError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Invitations — GET /api/teams/:id/invitations: ожидающие приглашения команды.
+func (h *TeamsHandler) Invitations(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	list, err := h.teamSvc.Invitations(c.Request.Context(), userID, teamID)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Invite — POST /api/teams/:id/invitations {"username":"octocat","role":"member"}.
+func (h *TeamsHandler) Invite(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	var body inviteRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	inv, err := h.teamSvc.Invite(c.Request.Context(), userID, teamID, body.Username, body.Role)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, inv)
+}
+
+// CancelInvitation — DELETE /api/teams/:id/invitations/:invitation_id.
+func (h *TeamsHandler) CancelInvitation(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("invitation_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": team.ErrInvitationNotFound.Error()})
+		return
+	}
+	if err := h.teamSvc.CancelInvitation(c.Request.Context(), userID, teamID, id); err != nil {
+		teamError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// MyInvitations — GET /api/teams/invitations: приглашения текущему пользователю.
+func (h *TeamsHandler) MyInvitations(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	list, err := h.teamSvc.MyInvitations(c.Request.Context(), userIDVal.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Accept — POST /api/teams/invitations/:id/accept: возвращает команду.
+func (h *TeamsHandler) Accept(c *gin.Context) {
+	h.respond(c, true)
+}
+
+// Decline — POST /api/teams/invitations/:id/decline.
+func (h *TeamsHandler) Decline(c *gin.Context) {
+	h.respond(c, false)
+}
+
+func (h *TeamsHandler) respond(c *gin.Context, accept bool) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": team.ErrInvitationNotFound.Error()})
+		return
+	}
+	t, err := h.teamSvc.Respond(c.Request.Context(), userIDVal.(uuid.UUID), id, accept)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	if !accept {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Stats — GET /api/teams/:id/stats?period=quarter: как /api/user/stats плюс members.
+func (h *TeamsHandler) Stats(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	st, err := h.teamSvc.Stats(c.Request.Context(), userID, teamID, periodQuery(c))
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// Contributions — GET /api/teams/:id/contributions?from=&to=: сумма вкладов участников по дням.
+func (h *TeamsHandler) Contributions(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	days, err := h.teamSvc.Contributions(c.Request.Context(), userID, teamID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, days)
+}
+
+// Repos — GET /api/teams/:id/repos: фильтры как у /api/user/repos, общие репозитории — один раз.
+func (h *TeamsHandler) Repos(c *gin.Context) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	f, ok := repoFilter(c)
+	if !ok {
+		return
+	}
+	repos, err := h.teamSvc.Repos(c.Request.Context(), userID, teamID, f)
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, repos)
+}
+
+func (h *TeamsHandler) PDF(c *gin.Context) {
+	h.download(c, report.FormatPDF)
+}
+
+func (h *TeamsHandler) Markdown(c *gin.Context) {
+	h.download(c, report.FormatMarkdown)
+}
+
+// download — GET /api/teams/:id/reports/{pdf,markdown}?period=...
+func (h *TeamsHandler) download(c *gin.Context, format string) {
+	userID, teamID, ok := teamParams(c)
+	if !ok {
+		return
+	}
+	doc, err := h.reportSvc.BuildTeam(c.Request.Context(), userID, teamID, format, periodQuery(c))
+	if err != nil {
+		teamError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename="+doc.Filename)
+	c.Data(http.StatusOK, doc.ContentType, doc.Data)
+}
+
+// teamParams — текущий пользователь и :id команды; при ошибке уже ответил.
+func teamParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	teamID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": team.ErrNotFound.Error()})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userIDVal.(uuid.UUID), teamID, true
+}
+
+func teamError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, team.ErrNotFound), errors.Is(err, team.ErrMemberNotFound), errors.Is(err, team.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, team.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, team.ErrInvalidTeam), errors.Is(err, stats.ErrInvalidPeriod), errors.Is(err, stats.ErrInvalidRepoFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, team.ErrAlreadyInvited), errors.Is(err, team.ErrAlreadyMember), errors.Is(err, team.ErrTeamFull):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"github.com/gin-gonic/gin"
@@ -8,18 +9,21 @@ import (
 	"github.com/devsync/server/internal/domain/user"
 	"github.com/devsync/server/internal/domain/stats"
 	"github.com/devsync/server/internal/domain/github"
+	"github.com/devsync/server/internal/infrastructure/queue"
+	"github.com/devsync/server/internal/infrastructure/lease"
 	"github.com/devsync/server/internal/transport/websocket"
 )
 
 type UserHandler struct {
 	userSvc  user.Service
 	statsSvc stats.Service
-	syncSvc  github.SyncService
+	jobs     queue.Queue
+	locks    lease.Store    // блокировки синхронизации (github.SyncLockName)
 	wsHub    *websocket.Hub // опционально: рассылка после sync
 }
 
-func NewUserHandler(userSvc user.Service, statsSvc stats.Service, syncSvc github.SyncService, wsHub *websocket.Hub) *UserHandler {
-	return &UserHandler{userSvc: userSvc, statsSvc: statsSvc, syncSvc: syncSvc, wsHub: wsHub}
+func NewUserHandler(userSvc user.Service, statsSvc stats.Service, jobs queue.Queue, locks lease.Store, wsHub *websocket.Hub) *UserHandler {
+	return &UserHandler{userSvc: userSvc, statsSvc: statsSvc, jobs: jobs, locks: locks, wsHub: wsHub}
 }
 
 func (h *UserHandler) Me(c *gin.Context) {
@@ -38,6 +42,9 @@ func (h *UserHandler) Me(c *gin.Context) {
 	c.JSON(http.StatusOK, u)
 }
 
+// Sync — POST /api/user/sync: ставит синхронизацию в очередь worker и сразу отвечает 202 с job_id;
+// если она уже выполняется — 409 sync_in_progress с job_id идущей задачи.
+// Ход синхронизации приходит по websocket (sync_started, sync_progress, sync_finished/sync_failed).
 func (h *UserHandler) Sync(c *gin.Context) {
 	userIDVal, exists := c.Get("user_id")
 	if !exists {
@@ -45,12 +52,104 @@ func (h *UserHandler) Sync(c *gin.Context) {
 		return
 	}
 	userID := userIDVal.(uuid.UUID)
-	if err := h.syncSvc.SyncUser(c.Request.Context(), userID); err != nil {
+	u, err := h.userSvc.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if u.ReauthRequired {
+		c.JSON(http.StatusForbidden, gin.H{"error": "reauth_required", "detail": github.ErrReauthRequired.Error()})
+		return
+	}
+	// Синхронизация уже идёт (другая вкладка, плановая задача) — отдаём её ID, вторую не ставим
+	running, err := github.RunningSync(c.Request.Context(), h.locks, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if running != uuid.Nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "sync_in_progress", "job_id": running})
+		return
+	}
+	// Если плановая синхронизация уже ждёт в очереди, вернётся её ID с повышенным приоритетом
+	jobID, err := h.jobs.Enqueue(c.Request.Context(), queue.EnqueueParams{
+		Type:     queue.TypeSyncUser,
+		UserID:   &userID,
+		Priority: queue.PriorityHigh,
+		DedupKey: queue.UserKey(queue.TypeSyncUser, userID),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job_id": jobID, "status": queue.StatusPending})
+}
+
+// SyncStatus — GET /api/user/sync/:id: состояние задачи синхронизации.
+func (h *UserHandler) SyncStatus(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid job id"})
+		return
+	}
+	job, err := h.jobs.Get(c.Request.Context(), jobID)
+	if errors.Is(err, queue.ErrNotFound) || err == nil && (job.UserID == nil || *job.UserID != userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if h.wsHub != nil {
-		h.wsHub.BroadcastToUser(userID, "stats_updated", nil)
+	c.JSON(http.StatusOK, job)
+}
+
+// UpdateSettings — PUT /api/user/settings: часовой пояс (IANA, например Europe/Moscow).
+// После смены пояса worker пересчитывает активность по дням заново.
+func (h *UserHandler) UpdateSettings(c *gin.Context) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	userID := userIDVal.(uuid.UUID)
+	var body struct {
+		Timezone *string `json:"timezone"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid body"})
+		return
+	}
+	if body.Timezone != nil {
+		if err := h.userSvc.UpdateTimezone(c.Request.Context(), userID, *body.Timezone); err != nil {
+			if errors.Is(err, user.ErrInvalidTimezone) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// Пересчёт всей истории — в worker; по готовности придёт stats_updated
+		if _, err := h.jobs.Enqueue(c.Request.Context(), queue.EnqueueParams{
+			Type:     queue.TypeRebuildActivity,
+			UserID:   &userID,
+			Priority: queue.PriorityHigh,
+			DedupKey: queue.UserKey(queue.TypeRebuildActivity, userID),
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
 	}
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	u, err := h.userSvc.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	c.JSON(http.StatusOK, u)
 }
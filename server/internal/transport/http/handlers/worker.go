@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"github.com/gin-gonic/gin"
+	"github.com/devsync/server/internal/infrastructure/lease"
+)
+
+type WorkerHandler struct {
+	leases lease.Store
+}
+
+func NewWorkerHandler(leases lease.Store) *WorkerHandler {
+	return &WorkerHandler{leases: leases}
+}
+
+// Status — GET /api/worker/status: какая реплика worker сейчас лидер (планирует синхронизации).
+// alive=false — лидер не продлевал аренду дольше её срока; её заберёт следующая живая реплика.
+func (h *WorkerHandler) Status(c *gin.Context) {
+	l, err := h.leases.Get(c.Request.Context(), lease.Scheduler)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if l == nil {
+		c.JSON(http.StatusOK, gin.H{"leader": nil, "alive": false})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"leader": l, "alive": l.ExpiresAt.After(time.Now())})
+}
@@ -17,6 +17,16 @@ type Router struct {
 	User   *handlers.UserHandler
 	Stats  *handlers.StatsHandler
 	Reports *handlers.ReportsHandler
+	Collab *handlers.CollaborationHandler
+	Schedule *handlers.ScheduleHandler
+	Worker *handlers.WorkerHandler
+	Backfill *handlers.BackfillHandler
+	Signals *handlers.SignalsHandler
+	Goals  *handlers.GoalsHandler
+	Achievements *handlers.AchievementsHandler
+	Teams  *handlers.TeamsHandler
+	Profile *handlers.ProfileHandler
+	Compare *handlers.CompareHandler
 	JWT    *JWTMiddleware
 	WSHub  *websocket.Hub
 	JWTSecret string
@@ -30,12 +40,22 @@ func (m *JWTMiddleware) Handler() gin.HandlerFunc {
 	return middleware.JWT(m.Secret)
 }
 
-func NewRouter(auth *handlers.AuthHandler, user *handlers.UserHandler, stats *handlers.StatsHandler, reports *handlers.ReportsHandler, jwtSecret string, wsHub *websocket.Hub) *Router {
+func NewRouter(auth *handlers.AuthHandler, user *handlers.UserHandler, stats *handlers.StatsHandler, reports *handlers.ReportsHandler, collab *handlers.CollaborationHandler, schedule *handlers.ScheduleHandler, worker *handlers.WorkerHandler, backfill *handlers.BackfillHandler, signals *handlers.SignalsHandler, goals *handlers.GoalsHandler, achievements *handlers.AchievementsHandler, teams *handlers.TeamsHandler, profile *handlers.ProfileHandler, compare *handlers.CompareHandler, jwtSecret string, wsHub *websocket.Hub) *Router {
 	return &Router{
 		Auth:       auth,
 		User:       user,
 		Stats:      stats,
 		Reports:    reports,
+		Collab:     collab,
+		Schedule:   schedule,
+		Worker:     worker,
+		Backfill:   backfill,
+		Signals:    signals,
+		Goals:      goals,
+		Achievements: achievements,
+		Teams:      teams,
+		Profile:    profile,
+		Compare:    compare,
 		JWT:        &JWTMiddleware{Secret: jwtSecret},
 		WSHub:      wsHub,
 		JWTSecret:  jwtSecret,
@@ -92,16 +112,77 @@ func (r *Router) Setup(engine *gin.Engine) {
 		authGroup.GET("/github/callback", r.Auth.GitHubCallback)
 	}
 
+	// Публичные профили — без авторизации и со своим, более строгим лимитом
+	public := api.Group("/public")
+	public.Use(middleware.RateLimit(30))
+	{
+		public.GET("/:username/stats", r.Profile.Stats)
+		public.GET("/:username/contributions", r.Profile.Contributions)
+		public.GET("/:username/repos", r.Profile.Repos)
+	}
+
 	protected := api.Group("")
 	protected.Use(middleware.RateLimit(100))
 	protected.Use(r.JWT.Handler())
 	{
 		protected.GET("/user", r.User.Me)
+		protected.PUT("/user/settings", r.User.UpdateSettings)
 		protected.POST("/user/sync", r.User.Sync)
+		protected.GET("/user/sync/:id", r.User.SyncStatus)
+		protected.GET("/user/sync/schedule", r.Schedule.Get)
+		protected.PUT("/user/sync/schedule", r.Schedule.Update)
+		protected.GET("/user/backfill", r.Backfill.Status)
+		protected.POST("/user/backfill", r.Backfill.Start)
 		protected.GET("/user/stats", r.Stats.UserStats)
 		protected.GET("/user/repos", r.Stats.Repos)
 		protected.GET("/user/contributions", r.Stats.Contributions)
+		protected.GET("/user/punchcard", r.Stats.Punchcard)
+		protected.GET("/user/streaks", r.Stats.Streaks)
+		protected.GET("/user/streaks/settings", r.Stats.StreakSettings)
+		protected.PUT("/user/streaks/settings", r.Stats.UpdateStreakSettings)
+		protected.GET("/user/rest-days", r.Stats.RestDays)
+		protected.POST("/user/rest-days", r.Stats.AddRestDays)
+		protected.DELETE("/user/rest-days", r.Stats.DeleteRestDays)
+		protected.GET("/user/signals", r.Signals.Signals)
+		protected.GET("/user/profile/visibility", r.Profile.Visibility)
+		protected.PUT("/user/profile/visibility", r.Profile.UpdateVisibility)
+		protected.GET("/user/collaborators", r.Collab.Collaborators)
+		protected.GET("/user/collaborators/graph", r.Collab.Graph)
+		protected.GET("/goals", r.Goals.List)
+		protected.POST("/goals", r.Goals.Create)
+		protected.PUT("/goals/:id", r.Goals.Update)
+		protected.DELETE("/goals/:id", r.Goals.Delete)
+		protected.GET("/goals/:id/history", r.Goals.History)
+		protected.GET("/achievements", r.Achievements.List)
+		protected.GET("/teams", r.Teams.List)
+		protected.POST("/teams", r.Teams.Create)
+		protected.GET("/teams/invitations", r.Teams.MyInvitations)
+		protected.POST("/teams/invitations/:id/accept", r.Teams.Accept)
+		protected.POST("/teams/invitations/:id/decline", r.Teams.Decline)
+		protected.GET("/teams/:id", r.Teams.Get)
+		protected.PUT("/teams/:id", r.Teams.Rename)
+		protected.DELETE("/teams/:id", r.Teams.Delete)
+		protected.GET("/teams/:id/members", r.Teams.Members)
+		protected.PUT("/teams/:id/members/:user_id", r.Teams.UpdateMember)
+		protected.DELETE("/teams/:id/members/:user_id", r.Teams.RemoveMember)
+		protected.GET("/teams/:id/invitations", r.Teams.Invitations)
+		protected.POST("/teams/:id/invitations", r.Teams.Invite)
+		protected.DELETE("/teams/:id/invitations/:invitation_id", r.Teams.CancelInvitation)
+		protected.GET("/teams/:id/stats", r.Teams.Stats)
+		protected.GET("/teams/:id/contributions", r.Teams.Contributions)
+		protected.GET("/teams/:id/repos", r.Teams.Repos)
+		protected.GET("/teams/:id/reports/pdf", r.Teams.PDF)
+		protected.GET("/teams/:id/reports/markdown", r.Teams.Markdown)
+		protected.GET("/compare", r.Compare.Compare)
+		protected.GET("/worker/status", r.Worker.Status)
 		protected.GET("/reports/pdf", r.Reports.PDF)
 		protected.GET("/reports/markdown", r.Reports.Markdown)
+		protected.GET("/reports/compare/pdf", r.Reports.ComparePDF)
+		protected.GET("/reports/subscriptions", r.Reports.Subscriptions)
+		protected.POST("/reports/subscriptions", r.Reports.CreateSubscription)
+		protected.PUT("/reports/subscriptions/:id", r.Reports.UpdateSubscription)
+		protected.DELETE("/reports/subscriptions/:id", r.Reports.DeleteSubscription)
+		protected.GET("/reports/subscriptions/:id/deliveries", r.Reports.Deliveries)
+		protected.POST("/reports/subscriptions/:id/send", r.Reports.SendNow)
 	}
 }
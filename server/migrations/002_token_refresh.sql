@@ -0,0 +1,3 @@
+-- OAuth token expiry and re-authorization tracking
+ALTER TABLE users ADD COLUMN IF NOT EXISTS token_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS reauth_required BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,19 @@
+-- per-user timezone for day bucketing
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- raw GitHub activity with full timestamps; contributions and daily_stats are rebuilt from it
+CREATE TABLE IF NOT EXISTS activity_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    github_event_id VARCHAR(64) NOT NULL,
+    type VARCHAR(100) NOT NULL,
+    action VARCHAR(50),
+    repo_github_id BIGINT,
+    repo_name VARCHAR(512),
+    is_public BOOLEAN DEFAULT true,
+    commits INTEGER DEFAULT 0,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    UNIQUE(user_id, github_event_id)
+);
+
+CREATE INDEX idx_activity_events_user_time ON activity_events(user_id, occurred_at);
@@ -0,0 +1,17 @@
+-- extended repository metadata from GitHub
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS is_fork BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS is_archived BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS is_template BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS topics TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS license VARCHAR(100);
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS size_kb INTEGER DEFAULT 0;
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS open_issues INTEGER DEFAULT 0;
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS homepage TEXT;
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS default_branch VARCHAR(255);
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS repo_created_at TIMESTAMPTZ;
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS pushed_at TIMESTAMPTZ;
+-- updated_at on GitHub; last_updated is when DevSync last synced the row
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS repo_updated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_repositories_user_pushed ON repositories(user_id, pushed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_repositories_topics ON repositories USING GIN (topics);
@@ -0,0 +1,16 @@
+-- dependencies parsed from manifests in each repository's default branch
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS deps_scanned_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS repo_dependencies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    repo_id UUID REFERENCES repositories(id) ON DELETE CASCADE,
+    ecosystem VARCHAR(32) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    version VARCHAR(100),
+    manifest VARCHAR(100) NOT NULL,
+    is_dev BOOLEAN DEFAULT false,
+    UNIQUE(repo_id, ecosystem, name)
+);
+
+CREATE INDEX idx_repo_dependencies_user ON repo_dependencies(user_id, ecosystem, name);
@@ -0,0 +1,43 @@
+-- co-contributors and pull request interactions in users' repositories
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS collab_scanned_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS repo_contributors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    repo_id UUID REFERENCES repositories(id) ON DELETE CASCADE,
+    login VARCHAR(255) NOT NULL,
+    avatar_url TEXT,
+    contributions INTEGER DEFAULT 0,
+    UNIQUE(repo_id, login)
+);
+
+CREATE INDEX idx_repo_contributors_user ON repo_contributors(user_id);
+
+CREATE TABLE IF NOT EXISTS pull_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    repo_id UUID REFERENCES repositories(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    author_login VARCHAR(255) NOT NULL,
+    author_avatar_url TEXT,
+    merged BOOLEAN DEFAULT false,
+    created_at TIMESTAMPTZ,
+    UNIQUE(repo_id, number)
+);
+
+CREATE INDEX idx_pull_requests_user ON pull_requests(user_id);
+
+CREATE TABLE IF NOT EXISTS pull_request_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    repo_id UUID REFERENCES repositories(id) ON DELETE CASCADE,
+    pr_number INTEGER NOT NULL,
+    reviewer_login VARCHAR(255) NOT NULL,
+    reviewer_avatar_url TEXT,
+    reviews INTEGER DEFAULT 0,
+    last_state VARCHAR(50),
+    submitted_at TIMESTAMPTZ,
+    UNIQUE(repo_id, pr_number, reviewer_login)
+);
+
+CREATE INDEX idx_pull_request_reviews_user ON pull_request_reviews(user_id);
@@ -0,0 +1,12 @@
+-- task_queue as a real job queue: retries with backoff, visibility timeouts, dedup
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS max_attempts INTEGER NOT NULL DEFAULT 5;
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS run_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS locked_by VARCHAR(255);
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS last_error TEXT;
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS dedup_key VARCHAR(255);
+ALTER TABLE task_queue ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_task_queue_ready ON task_queue(priority DESC, run_at) WHERE status IN ('pending', 'running');
+CREATE UNIQUE INDEX IF NOT EXISTS idx_task_queue_dedup ON task_queue(dedup_key) WHERE status = 'pending' AND dedup_key IS NOT NULL;
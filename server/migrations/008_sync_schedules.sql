@@ -0,0 +1,16 @@
+-- when the user last logged in; drives adaptive sync frequency
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
+
+-- per-user sync schedule; users without a row use the adaptive policy
+CREATE TABLE IF NOT EXISTS sync_schedules (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    mode VARCHAR(20) NOT NULL DEFAULT 'adaptive' CHECK (mode IN ('adaptive', 'interval', 'cron')),
+    interval_minutes INTEGER,
+    cron_expr VARCHAR(100),
+    next_run_at TIMESTAMPTZ,
+    last_enqueued_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    CHECK ((mode <> 'interval' OR interval_minutes IS NOT NULL) AND (mode <> 'cron' OR cron_expr IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_schedules_next_run ON sync_schedules(next_run_at);
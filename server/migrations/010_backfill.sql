@@ -0,0 +1,17 @@
+-- historical backfill progress per user; cursor is the checkpoint to resume from
+CREATE TABLE IF NOT EXISTS backfill_state (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    years INTEGER NOT NULL,
+    range_start TIMESTAMPTZ,
+    range_end TIMESTAMPTZ,
+    cursor TIMESTAMPTZ,
+    chunks_done INTEGER NOT NULL DEFAULT 0,
+    chunks_total INTEGER NOT NULL DEFAULT 0,
+    commits_found INTEGER NOT NULL DEFAULT 0,
+    last_job_id UUID,
+    last_error TEXT,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,45 @@
+-- contributions and daily_stats rolled up by week (starting Monday), month and year
+CREATE TABLE IF NOT EXISTS stats_rollups (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    granularity VARCHAR(10) NOT NULL CHECK (granularity IN ('week', 'month', 'year')),
+    period_start DATE NOT NULL,
+    contributions INTEGER NOT NULL DEFAULT 0,
+    commits INTEGER NOT NULL DEFAULT 0,
+    prs INTEGER NOT NULL DEFAULT 0,
+    issues INTEGER NOT NULL DEFAULT 0,
+    stars_received INTEGER NOT NULL DEFAULT 0,
+    active_days INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, granularity, period_start)
+);
+
+-- raw_since: first date with complete raw contributions/daily_stats rows; NULL if nothing was pruned
+CREATE TABLE IF NOT EXISTS stats_retention (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    raw_since DATE,
+    pruned_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- initial rollups from existing days; Rebuild and the rollup_stats job keep them current
+INSERT INTO stats_rollups (user_id, granularity, period_start, contributions, commits, prs, issues, stars_received, active_days)
+SELECT d.user_id, g.granularity, date_trunc(g.granularity, d.date::timestamp)::date, SUM(d.contributions), SUM(d.commits),
+    SUM(d.prs), SUM(d.issues), SUM(d.stars_received), COUNT(*) FILTER (WHERE d.contributions > 0)
+FROM (
+    SELECT COALESCE(c.user_id, s.user_id) AS user_id, COALESCE(c.date, s.date) AS date, COALESCE(c.cnt, 0) AS contributions,
+        COALESCE(s.commits, 0) AS commits, COALESCE(s.prs, 0) AS prs, COALESCE(s.issues, 0) AS issues,
+        COALESCE(s.stars_received, 0) AS stars_received
+    FROM (SELECT user_id, date, SUM(count) AS cnt FROM contributions GROUP BY user_id, date) c
+    FULL JOIN daily_stats s ON s.user_id = c.user_id AND s.date = c.date
+) d
+CROSS JOIN (VALUES ('week'), ('month')) AS g(granularity)
+WHERE d.user_id IS NOT NULL
+GROUP BY d.user_id, g.granularity, 3
+ON CONFLICT DO NOTHING;
+
+INSERT INTO stats_rollups (user_id, granularity, period_start, contributions, commits, prs, issues, stars_received, active_days)
+SELECT user_id, 'year', date_trunc('year', period_start::timestamp)::date, SUM(contributions), SUM(commits), SUM(prs),
+    SUM(issues), SUM(stars_received), SUM(active_days)
+FROM stats_rollups WHERE granularity = 'month'
+GROUP BY user_id, 3
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,16 @@
+-- streak settings: weekends as rest days and the minimum streak length kept in history
+CREATE TABLE IF NOT EXISTS streak_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    rest_weekends BOOLEAN NOT NULL DEFAULT false,
+    min_length INTEGER NOT NULL DEFAULT 3,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- declared rest days (vacations etc.) do not break a streak
+CREATE TABLE IF NOT EXISTS rest_days (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, day)
+);
@@ -0,0 +1,19 @@
+-- signals about sharp changes in work rhythm (late work, weekends, activity drop, PR spike);
+-- one 7-day window per signal kind so repeated checks do not create duplicates
+CREATE TABLE IF NOT EXISTS activity_signals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(30) NOT NULL,
+    severity VARCHAR(10) NOT NULL,
+    window_start DATE NOT NULL,
+    window_end DATE NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    baseline DOUBLE PRECISION NOT NULL,
+    stddev DOUBLE PRECISION NOT NULL,
+    z_score DOUBLE PRECISION NOT NULL,
+    explanation TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (user_id, kind, window_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_signals_user ON activity_signals(user_id, created_at DESC);
@@ -0,0 +1,26 @@
+-- user goals: target units of a metric per calendar week or month
+CREATE TABLE IF NOT EXISTS goals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    metric VARCHAR(20) NOT NULL,
+    target INTEGER NOT NULL,
+    period VARCHAR(10) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_user ON goals(user_id);
+
+-- per-period results, recomputed after every sync (current and previous period)
+CREATE TABLE IF NOT EXISTS goal_periods (
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    value INTEGER NOT NULL,
+    target INTEGER NOT NULL,
+    met BOOLEAN NOT NULL,
+    reached_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (goal_id, period_start)
+);
@@ -0,0 +1,9 @@
+-- achievements: progress per rule; unlocked_at is set once and never cleared
+CREATE TABLE IF NOT EXISTS user_achievements (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    achievement VARCHAR(50) NOT NULL,
+    progress INTEGER NOT NULL DEFAULT 0,
+    unlocked_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, achievement)
+);
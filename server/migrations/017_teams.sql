@@ -0,0 +1,35 @@
+-- teams: the owner, admins and members see aggregated stats
+CREATE TABLE IF NOT EXISTS teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL,
+    joined_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_members_user ON team_members(user_id);
+
+-- invitations by GitHub login; the user may not be registered yet
+CREATE TABLE IF NOT EXISTS team_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    username VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    responded_at TIMESTAMPTZ
+);
+
+-- one pending invitation per login per team
+CREATE UNIQUE INDEX IF NOT EXISTS idx_team_invitations_pending
+    ON team_invitations(team_id, LOWER(username)) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_team_invitations_username ON team_invitations(LOWER(username)) WHERE status = 'pending';
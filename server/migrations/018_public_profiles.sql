@@ -0,0 +1,10 @@
+-- public profiles: closed by default; sections are visible without signing in
+CREATE TABLE IF NOT EXISTS profile_visibility (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    public BOOLEAN NOT NULL DEFAULT false,
+    sections TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- profiles are looked up by login case-insensitively
+CREATE INDEX IF NOT EXISTS idx_users_username_lower ON users(LOWER(username));
@@ -0,0 +1,7 @@
+-- total stars on the user's own repositories per day; the day-over-day difference is daily_stats.stars_received
+CREATE TABLE IF NOT EXISTS star_snapshots (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    stars INTEGER NOT NULL,
+    PRIMARY KEY (user_id, date)
+);
@@ -1,11 +1,15 @@
 package github
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -18,13 +22,53 @@ const (
 type Client struct {
 	httpClient *http.Client
 	token      string
+	limits     *Limits
 }
 
 func NewClient(token string) *Client {
+	return NewLimitedClient(token, nil)
+}
+
+// NewLimitedClient — клиент, который перед каждым запросом сверяется с limits
+// и запоминает в них X-RateLimit-* из ответов.
+func NewLimitedClient(token string, limits *Limits) *Client {
 	return &Client{
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		token:      token,
+		limits:     limits,
+	}
+}
+
+// APIError — ответ GitHub API с кодом, отличным от 200.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("github api error %d: %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound — ресурс (файл, репозиторий) не найден или пуст.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsRateLimited — запрос упёрся в лимит GitHub (свой или вторичный).
+func IsRateLimited(err error) bool {
+	if errors.Is(err, ErrRateLimited) {
+		return true
 	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusTooManyRequests ||
+		apiErr.StatusCode == http.StatusForbidden && strings.Contains(strings.ToLower(apiErr.Body), "rate limit"))
+}
+
+// IsUnauthorized сообщает, что токен истёк или отозван (GitHub вернул 401).
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
 }
 
 type GitHubUser struct {
@@ -36,19 +80,8 @@ type GitHubUser struct {
 }
 
 func (c *Client) GetUser(ctx context.Context) (*GitHubUser, error) {
-	req, _ := http.NewRequestWithContext(ctx, "GET", APIBase+"/user", nil)
-	c.setHeaders(req)
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("github api error %d: %s", resp.StatusCode, string(body))
-	}
 	var u GitHubUser
-	if err := json.NewDecoder(resp.Body).Decode(&u); err != nil {
+	if err := c.getJSON(ctx, APIBase+"/user", &u); err != nil {
 		return nil, err
 	}
 	return &u, nil
@@ -64,30 +97,32 @@ type GitHubRepo struct {
 	Language    *string `json:"language"`
 	Private     bool    `json:"private"`
 	UpdatedAt   string  `json:"updated_at"`
+	Fork        bool    `json:"fork"`
+	Archived    bool    `json:"archived"`
+	IsTemplate  bool    `json:"is_template"`
+	Topics      []string `json:"topics"`
+	License     *struct {
+		SPDXID string `json:"spdx_id"`
+		Name   string `json:"name"`
+	} `json:"license"`
+	Size          int     `json:"size"` // KB
+	OpenIssues    int     `json:"open_issues_count"`
+	Homepage      *string `json:"homepage"`
+	DefaultBranch string  `json:"default_branch"`
+	CreatedAt     string  `json:"created_at"`
+	PushedAt      *string `json:"pushed_at"`
 }
 
 func (c *Client) GetUserRepos(ctx context.Context, page int) ([]GitHubRepo, error) {
-	url := fmt.Sprintf("%s/user/repos?per_page=100&page=%d&sort=updated", APIBase, page)
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	c.setHeaders(req)
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("github api error %d: %s", resp.StatusCode, string(body))
-	}
 	var repos []GitHubRepo
-	if err := json.NewDecoder(resp.Body).Decode(&repos); err != nil {
-		return nil, err
-	}
-	return repos, nil
+	err := c.getJSON(ctx, fmt.Sprintf("%s/user/repos?per_page=100&page=%d&sort=updated", APIBase, page), &repos)
+	return repos, err
 }
 
 type GitHubEvent struct {
+	ID   string `json:"id"`
 	Type string `json:"type"`
+	Public bool `json:"public"`
 	Repo struct {
 		ID   int64  `json:"id"`
 		Name string `json:"name"`
@@ -96,7 +131,8 @@ type GitHubEvent struct {
 		Size       int `json:"size"`        // PushEvent commits
 		Action     string `json:"action"`
 		PullRequest *struct {
-			ID int64 `json:"id"`
+			ID     int64 `json:"id"`
+			Merged bool  `json:"merged"` // для action = closed
 		} `json:"pull_request"`
 		Issue *struct {
 			ID int64 `json:"id"`
@@ -106,23 +142,9 @@ type GitHubEvent struct {
 }
 
 func (c *Client) GetUserEvents(ctx context.Context, username string, page int) ([]GitHubEvent, error) {
-	url := fmt.Sprintf("%s/users/%s/events?per_page=100&page=%d", APIBase, username, page)
-	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	c.setHeaders(req)
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("github api error %d: %s", resp.StatusCode, string(body))
-	}
 	var events []GitHubEvent
-	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
-		return nil, err
-	}
-	return events, nil
+	err := c.getJSON(ctx, fmt.Sprintf("%s/users/%s/events?per_page=100&page=%d", APIBase, username, page), &events)
+	return events, err
 }
 
 type GitHubContrib struct {
@@ -140,8 +162,7 @@ type GitHubContribWeek struct {
 func (c *Client) GetContributions(ctx context.Context, username string) ([]GitHubContribWeek, error) {
 	url := fmt.Sprintf("%s/users/%s/events/public", APIBase, username)
 	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
-	c.setHeaders(req)
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -154,8 +175,270 @@ func (c *Client) GetContributions(ctx context.Context, username string) ([]GitHu
 	return nil, nil
 }
 
+type ContentEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"` // file, dir, symlink, submodule
+	Size int    `json:"size"`
+}
+
+// ListRootContents — файлы в корне репозитория на ветке ref.
+func (c *Client) ListRootContents(ctx context.Context, fullName, ref string) ([]ContentEntry, error) {
+	var entries []ContentEntry
+	err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/contents/?ref=%s", APIBase, fullName, url.QueryEscape(ref)), &entries)
+	return entries, err
+}
+
+// GetRawFile — содержимое файла без base64-обёртки Contents API (до 1 МБ).
+func (c *Client) GetRawFile(ctx context.Context, fullName, path, ref string) ([]byte, error) {
+	u := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", APIBase, fullName, path, url.QueryEscape(ref))
+	req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
+	req.Header.Set("Accept", "application/vnd.github.raw")
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type Contributor struct {
+	ID            int64  `json:"id"`
+	Login         string `json:"login"`
+	AvatarURL     string `json:"avatar_url"`
+	Type          string `json:"type"` // User, Bot
+	Contributions int    `json:"contributions"`
+}
+
+// GetRepoContributors — до 100 самых активных авторов коммитов репозитория.
+func (c *Client) GetRepoContributors(ctx context.Context, fullName string) ([]Contributor, error) {
+	var out []Contributor
+	err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/contributors?per_page=100", APIBase, fullName), &out)
+	return out, err
+}
+
+type PullRequestUser struct {
+	Login     string `json:"login"`
+	AvatarURL string `json:"avatar_url"`
+	Type      string `json:"type"`
+}
+
+type PullRequest struct {
+	Number             int               `json:"number"`
+	User               PullRequestUser   `json:"user"`
+	RequestedReviewers []PullRequestUser `json:"requested_reviewers"`
+	CreatedAt          string            `json:"created_at"`
+	MergedAt           *string           `json:"merged_at"`
+}
+
+// ListPullRequests — последние обновлённые PR репозитория (открытые и закрытые).
+func (c *Client) ListPullRequests(ctx context.Context, fullName string, perPage int) ([]PullRequest, error) {
+	var out []PullRequest
+	err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/pulls?state=all&sort=updated&direction=desc&per_page=%d", APIBase, fullName, perPage), &out)
+	return out, err
+}
+
+type Review struct {
+	User        PullRequestUser `json:"user"`
+	State       string          `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED
+	SubmittedAt string          `json:"submitted_at"`
+}
+
+func (c *Client) ListReviews(ctx context.Context, fullName string, number int) ([]Review, error) {
+	var out []Review
+	err := c.getJSON(ctx, fmt.Sprintf("%s/repos/%s/pulls/%d/reviews?per_page=100", APIBase, fullName, number), &out)
+	return out, err
+}
+
+type CommitAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"` // RFC3339 со смещением автора
+}
+
+type CommitSearchItem struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author CommitAuthor `json:"author"`
+	} `json:"commit"`
+	Repository struct {
+		ID       int64  `json:"id"`
+		FullName string `json:"full_name"`
+		Private  bool   `json:"private"`
+	} `json:"repository"`
+}
+
+type CommitSearchResult struct {
+	TotalCount        int                `json:"total_count"`
+	IncompleteResults bool               `json:"incomplete_results"`
+	Items             []CommitSearchItem `json:"items"`
+}
+
+// SearchCommits — поиск коммитов (только ветки по умолчанию публичных репозиториев, до 1000 результатов на запрос).
+func (c *Client) SearchCommits(ctx context.Context, query string, page int) (*CommitSearchResult, error) {
+	var out CommitSearchResult
+	u := fmt.Sprintf("%s/search/commits?q=%s&sort=author-date&order=desc&per_page=100&page=%d", APIBase, url.QueryEscape(query), page)
+	if err := c.getJSON(ctx, u, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchIssuesCount — число issues и PR по запросу поиска (total_count, без самих результатов).
+func (c *Client) SearchIssuesCount(ctx context.Context, query string) (int, error) {
+	var out struct {
+		TotalCount int `json:"total_count"`
+	}
+	u := fmt.Sprintf("%s/search/issues?q=%s&per_page=1", APIBase, url.QueryEscape(query))
+	if err := c.getJSON(ctx, u, &out); err != nil {
+		return 0, err
+	}
+	return out.TotalCount, nil
+}
+
+type RepoCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author CommitAuthor `json:"author"`
+	} `json:"commit"`
+}
+
+// ListCommits — коммиты автора в репозитории за [since, until). Пустой репозиторий отдаёт 409 — это пустой список.
+func (c *Client) ListCommits(ctx context.Context, fullName, author string, since, until time.Time, page int) ([]RepoCommit, error) {
+	var out []RepoCommit
+	u := fmt.Sprintf("%s/repos/%s/commits?author=%s&since=%s&until=%s&per_page=100&page=%d", APIBase, fullName,
+		url.QueryEscape(author), since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339), page)
+	err := c.getJSON(ctx, u, &out)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		return nil, nil
+	}
+	return out, err
+}
+
+type CalendarDay struct {
+	Date              string `json:"date"` // YYYY-MM-DD
+	ContributionCount int    `json:"contributionCount"`
+}
+
+const calendarQuery = `query($login: String!, $from: DateTime!, $to: DateTime!) {
+  user(login: $login) {
+    contributionsCollection(from: $from, to: $to) {
+      contributionCalendar { weeks { contributionDays { date contributionCount } } }
+    }
+  }
+}`
+
+// ContributionCalendar — дни графика вкладов за [from, to] (не больше года) через GraphQL API.
+func (c *Client) ContributionCalendar(ctx context.Context, login string, from, to time.Time) ([]CalendarDay, error) {
+	var out struct {
+		User *struct {
+			ContributionsCollection struct {
+				ContributionCalendar struct {
+					Weeks []struct {
+						ContributionDays []CalendarDay `json:"contributionDays"`
+					} `json:"weeks"`
+				} `json:"contributionCalendar"`
+			} `json:"contributionsCollection"`
+		} `json:"user"`
+	}
+	vars := map[string]interface{}{"login": login, "from": from.UTC().Format(time.RFC3339), "to": to.UTC().Format(time.RFC3339)}
+	if err := c.graphQL(ctx, calendarQuery, vars, &out); err != nil {
+		return nil, err
+	}
+	if out.User == nil {
+		return nil, &APIError{StatusCode: http.StatusNotFound, Body: "user " + login + " not found"}
+	}
+	var days []CalendarDay
+	for _, w := range out.User.ContributionsCollection.ContributionCalendar.Weeks {
+		days = append(days, w.ContributionDays...)
+	}
+	return days, nil
+}
+
+// graphQL выполняет запрос к GraphQL API; ошибки из поля errors возвращаются как APIError.
+func (c *Client) graphQL(ctx context.Context, query string, vars map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return err
+	}
+	req, _ := http.NewRequestWithContext(ctx, "POST", APIBase+"/graphql", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+	var out struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return err
+	}
+	if len(out.Errors) > 0 {
+		if out.Errors[0].Type == "RATE_LIMITED" {
+			return &RateLimitError{Resource: "graphql", Reset: time.Now().Add(time.Minute)}
+		}
+		return &APIError{StatusCode: http.StatusUnprocessableEntity, Body: out.Errors[0].Message}
+	}
+	return json.Unmarshal(out.Data, v)
+}
+
+// getJSON выполняет GET и декодирует ответ; 204 No Content оставляет v пустым.
+func (c *Client) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, _ := http.NewRequestWithContext(ctx, "GET", u, nil)
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// do — единая точка выхода к API: ждёт лимиты, ставит заголовки, запоминает X-RateLimit-*.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if err := c.limits.wait(req.Context(), c.token, resourceFor(req)); err != nil {
+		return nil, err
+	}
+	c.setHeaders(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if rl, ok := parseRateLimit(resp.Header); ok && c.limits != nil {
+		c.limits.record(c.token, rl)
+	}
+	return resp, nil
+}
+
+func newAPIError(resp *http.Response) error {
+	if rl := rateLimitFromResponse(resp); rl != nil {
+		return rl
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+}
+
 func (c *Client) setHeaders(req *http.Request) {
-	req.Header.Set("Accept", Accept)
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", Accept)
+	}
 	req.Header.Set("User-Agent", UserAgent)
 	if c.token != "" {
 		req.Header.Set("Authorization", "Bearer "+c.token)
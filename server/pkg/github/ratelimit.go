@@ -0,0 +1,208 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited — лимит токена исчерпан, а до сброса дольше, чем Limits.MaxWait.
+var ErrRateLimited = errors.New("github rate limit exhausted")
+
+// RateLimitError — лимит исчерпан до Reset; errors.Is(err, ErrRateLimited) == true.
+type RateLimitError struct {
+	Resource string
+	Reset    time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v: %s resets at %s", ErrRateLimited, e.Resource, e.Reset.Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrRateLimited }
+
+// RetryAt — когда лимит сбросится; нулевое время, если неизвестно.
+func RetryAt(err error) time.Time {
+	var rl *RateLimitError
+	if errors.As(err, &rl) {
+		return rl.Reset
+	}
+	return time.Time{}
+}
+
+// rateLimitFromResponse распознаёт ответ 403/429 из-за лимита: основного (X-RateLimit-Remaining: 0)
+// или вторичного (Retry-After).
+func rateLimitFromResponse(resp *http.Response) *RateLimitError {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return &RateLimitError{Resource: resourceFor(resp.Request), Reset: time.Now().Add(time.Duration(secs) * time.Second)}
+	}
+	if rl, ok := parseRateLimit(resp.Header); ok && rl.Remaining == 0 {
+		return &RateLimitError{Resource: rl.Resource, Reset: rl.Reset}
+	}
+	return nil
+}
+
+// RateLimit — состояние лимита из заголовков X-RateLimit-*.
+type RateLimit struct {
+	Resource  string // core, search, graphql — у каждого свой лимит
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+func parseRateLimit(h http.Header) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	resource := h.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	return RateLimit{Resource: resource, Limit: limit, Remaining: remaining, Reset: time.Unix(reset, 0)}, true
+}
+
+// resourceFor — какой лимит GitHub расходует запрос.
+func resourceFor(req *http.Request) string {
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/search/"):
+		return "search"
+	case req.URL.Path == "/graphql":
+		return "graphql"
+	default:
+		return "core"
+	}
+}
+
+// Budget — общий для процесса лимит запросов к GitHub (token bucket).
+type Budget struct {
+	mu     sync.Mutex
+	rate   float64 // запросов в секунду
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewBudget — не больше perHour запросов в час, до burst подряд. perHour <= 0 — без ограничения (nil).
+func NewBudget(perHour, burst int) *Budget {
+	if perHour <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &Budget{rate: float64(perHour) / 3600, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait блокируется до появления свободного запроса или отмены ctx.
+func (b *Budget) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Limits — общий бюджет плюс состояние лимита каждого токена; один экземпляр на процесс.
+type Limits struct {
+	Budget  *Budget
+	Reserve int           // сколько запросов токена оставлять нетронутыми (на вход пользователя и т.п.)
+	MaxWait time.Duration // дольше ждать сброса лимита не будем — вернём ErrRateLimited
+
+	mu     sync.Mutex
+	tokens map[limitKey]RateLimit
+}
+
+type limitKey struct {
+	token    [32]byte
+	resource string
+}
+
+func NewLimits(budget *Budget) *Limits {
+	return &Limits{
+		Budget:  budget,
+		Reserve: 50,
+		MaxWait: 2 * time.Minute,
+		tokens:  make(map[limitKey]RateLimit),
+	}
+}
+
+// Сами токены в памяти не храним — только хэш.
+func tokenKey(token, resource string) limitKey {
+	return limitKey{token: sha256.Sum256([]byte(token)), resource: resource}
+}
+
+// Get — последнее известное состояние лимита токена по ресурсу (core, search, graphql).
+func (l *Limits) Get(token, resource string) (RateLimit, bool) {
+	if l == nil {
+		return RateLimit{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rl, ok := l.tokens[tokenKey(token, resource)]
+	return rl, ok
+}
+
+func (l *Limits) record(token string, rl RateLimit) {
+	l.mu.Lock()
+	l.tokens[tokenKey(token, rl.Resource)] = rl
+	l.mu.Unlock()
+}
+
+// wait ждёт сброса лимита токена, если он почти исчерпан, затем — места в общем бюджете.
+func (l *Limits) wait(ctx context.Context, token, resource string) error {
+	if l == nil {
+		return nil
+	}
+	reserve := l.Reserve
+	if resource == "search" {
+		reserve = 0 // у поиска всего 30 запросов в минуту, резерв не нужен
+	}
+	if rl, ok := l.Get(token, resource); ok && rl.Remaining <= reserve {
+		if d := time.Until(rl.Reset); d > 0 {
+			if d > l.MaxWait {
+				return &RateLimitError{Resource: resource, Reset: rl.Reset}
+			}
+			if err := sleep(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+	return l.Budget.Wait(ctx)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,321 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	EcosystemGo     = "go"
+	EcosystemNPM    = "npm"
+	EcosystemPyPI   = "pypi"
+	EcosystemCargo  = "cargo"
+	EcosystemMaven  = "maven"
+)
+
+type Dependency struct {
+	Ecosystem string
+	Name      string
+	Version   string
+	Dev       bool
+}
+
+// Files — манифесты в корне репозитория, которые умеет разбирать Parse.
+var Files = []string{"go.mod", "package.json", "requirements.txt", "pyproject.toml", "Cargo.toml", "pom.xml"}
+
+func Supported(filename string) bool {
+	for _, f := range Files {
+		if f == filename {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse разбирает манифест по имени файла. Дубликаты (одна зависимость в нескольких секциях) схлопываются.
+func Parse(filename string, data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	var err error
+	switch filename {
+	case "go.mod":
+		deps, err = parseGoMod(data)
+	case "package.json":
+		deps, err = parsePackageJSON(data)
+	case "requirements.txt":
+		deps, err = parseRequirements(data)
+	case "pyproject.toml":
+		deps, err = parsePyProject(data)
+	case "Cargo.toml":
+		deps, err = parseCargo(data)
+	case "pom.xml":
+		deps, err = parsePom(data)
+	default:
+		return nil, fmt.Errorf("manifest: unsupported file %s", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", filename, err)
+	}
+	return dedupe(deps), nil
+}
+
+func parseGoMod(data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	inBlock := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			// косвенные зависимости не используются модулем напрямую
+			if strings.HasPrefix(strings.TrimSpace(line[i+2:]), "indirect") {
+				continue
+			}
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "require ("), line == "require(":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+		case !inBlock:
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		deps = append(deps, Dependency{Ecosystem: EcosystemGo, Name: fields[0], Version: fields[1]})
+	}
+	return deps, sc.Err()
+}
+
+func parsePackageJSON(data []byte) ([]Dependency, error) {
+	var pkg struct {
+		Dependencies     map[string]string `json:"dependencies"`
+		DevDependencies  map[string]string `json:"devDependencies"`
+		PeerDependencies map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	for name, v := range pkg.Dependencies {
+		deps = append(deps, Dependency{Ecosystem: EcosystemNPM, Name: name, Version: v})
+	}
+	for name, v := range pkg.PeerDependencies {
+		deps = append(deps, Dependency{Ecosystem: EcosystemNPM, Name: name, Version: v})
+	}
+	for name, v := range pkg.DevDependencies {
+		deps = append(deps, Dependency{Ecosystem: EcosystemNPM, Name: name, Version: v, Dev: true})
+	}
+	return deps, nil
+}
+
+func parseRequirements(data []byte) ([]Dependency, error) {
+	var deps []Dependency
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		// -r other.txt, -e git+..., --index-url и прочие опции pip пропускаем
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if d, ok := parsePEP508(line); ok {
+			deps = append(deps, d)
+		}
+	}
+	return deps, sc.Err()
+}
+
+func parsePyProject(data []byte) ([]Dependency, error) {
+	var doc struct {
+		Project struct {
+			Dependencies         []string            `toml:"dependencies"`
+			OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+		} `toml:"project"`
+		Tool struct {
+			Poetry struct {
+				Dependencies    map[string]interface{} `toml:"dependencies"`
+				DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+				Group           map[string]struct {
+					Dependencies map[string]interface{} `toml:"dependencies"`
+				} `toml:"group"`
+			} `toml:"poetry"`
+		} `toml:"tool"`
+	}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	for _, spec := range doc.Project.Dependencies {
+		if d, ok := parsePEP508(spec); ok {
+			deps = append(deps, d)
+		}
+	}
+	for _, group := range doc.Project.OptionalDependencies {
+		for _, spec := range group {
+			if d, ok := parsePEP508(spec); ok {
+				d.Dev = true
+				deps = append(deps, d)
+			}
+		}
+	}
+	for name, v := range doc.Tool.Poetry.Dependencies {
+		if strings.EqualFold(name, "python") {
+			continue
+		}
+		deps = append(deps, Dependency{Ecosystem: EcosystemPyPI, Name: strings.ToLower(name), Version: tableVersion(v)})
+	}
+	for name, v := range doc.Tool.Poetry.DevDependencies {
+		deps = append(deps, Dependency{Ecosystem: EcosystemPyPI, Name: strings.ToLower(name), Version: tableVersion(v), Dev: true})
+	}
+	for _, g := range doc.Tool.Poetry.Group {
+		for name, v := range g.Dependencies {
+			deps = append(deps, Dependency{Ecosystem: EcosystemPyPI, Name: strings.ToLower(name), Version: tableVersion(v), Dev: true})
+		}
+	}
+	return deps, nil
+}
+
+// parsePEP508 — "requests[socks]>=2.0; python_version<'3.8'" → requests, >=2.0
+func parsePEP508(spec string) (Dependency, bool) {
+	if i := strings.Index(spec, ";"); i >= 0 {
+		spec = spec[:i]
+	}
+	spec = strings.TrimSpace(spec)
+	end := strings.IndexAny(spec, "[<>=!~ (@")
+	name, version := spec, ""
+	if end >= 0 {
+		name = spec[:end]
+		version = strings.TrimSpace(spec[end:])
+		if strings.HasPrefix(version, "[") {
+			if j := strings.Index(version, "]"); j >= 0 {
+				version = strings.TrimSpace(version[j+1:])
+			}
+		}
+		version = strings.Trim(version, "() ")
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || strings.Contains(name, "/") {
+		return Dependency{}, false
+	}
+	return Dependency{Ecosystem: EcosystemPyPI, Name: name, Version: version}, true
+}
+
+func parseCargo(data []byte) ([]Dependency, error) {
+	var doc struct {
+		Dependencies      map[string]interface{} `toml:"dependencies"`
+		DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+		BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+		Workspace         struct {
+			Dependencies map[string]interface{} `toml:"dependencies"`
+		} `toml:"workspace"`
+	}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	for _, m := range []map[string]interface{}{doc.Dependencies, doc.Workspace.Dependencies} {
+		for name, v := range m {
+			deps = append(deps, Dependency{Ecosystem: EcosystemCargo, Name: cargoName(name, v), Version: tableVersion(v)})
+		}
+	}
+	for _, m := range []map[string]interface{}{doc.DevDependencies, doc.BuildDependencies} {
+		for name, v := range m {
+			deps = append(deps, Dependency{Ecosystem: EcosystemCargo, Name: cargoName(name, v), Version: tableVersion(v), Dev: true})
+		}
+	}
+	return deps, nil
+}
+
+// cargoName учитывает переименование: foo = { package = "bar", ... }
+func cargoName(key string, v interface{}) string {
+	if t, ok := v.(map[string]interface{}); ok {
+		if p, ok := t["package"].(string); ok && p != "" {
+			return p
+		}
+	}
+	return key
+}
+
+// tableVersion — версия из "1.0" или { version = "1.0", ... }
+func tableVersion(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		if s, ok := t["version"].(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+func parsePom(data []byte) ([]Dependency, error) {
+	type pomDep struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+		Scope      string `xml:"scope"`
+	}
+	// parent и dependencyManagement только задают версии — используются лишь <dependencies>
+	var pom struct {
+		Dependencies []pomDep `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+	var deps []Dependency
+	add := func(d pomDep) {
+		if d.GroupID == "" || d.ArtifactID == "" {
+			return
+		}
+		version := d.Version
+		if strings.HasPrefix(version, "${") {
+			version = "" // версия из properties — не раскрываем
+		}
+		deps = append(deps, Dependency{
+			Ecosystem: EcosystemMaven,
+			Name:      d.GroupID + ":" + d.ArtifactID,
+			Version:   version,
+			Dev:       d.Scope == "test" || d.Scope == "provided",
+		})
+	}
+	for _, d := range pom.Dependencies {
+		add(d)
+	}
+	return deps, nil
+}
+
+func dedupe(deps []Dependency) []Dependency {
+	seen := make(map[string]int, len(deps))
+	out := deps[:0]
+	for _, d := range deps {
+		key := d.Ecosystem + "\x00" + d.Name
+		if i, ok := seen[key]; ok {
+			// runtime-зависимость важнее dev
+			if out[i].Dev && !d.Dev {
+				out[i] = d
+			}
+			continue
+		}
+		seen[key] = len(out)
+		out = append(out, d)
+	}
+	return out
+}
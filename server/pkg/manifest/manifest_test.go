@@ -0,0 +1,189 @@
+package manifest
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// parse разбирает манифест и сортирует результат: порядок секций из map не определён.
+func parse(t *testing.T, filename, data string) []Dependency {
+	t.Helper()
+	deps, err := Parse(filename, []byte(data))
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", filename, err)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps
+}
+
+func check(t *testing.T, got, want []Dependency) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("deps:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	got := parse(t, "go.mod", `module example.com/app
+
+go 1.21
+
+require github.com/google/uuid v1.6.0
+
+require (
+	github.com/jackc/pgx/v5 v5.5.0 // основной драйвер
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace example.com/old => ./old
+`)
+	check(t, got, []Dependency{
+		{Ecosystem: EcosystemGo, Name: "github.com/google/uuid", Version: "v1.6.0"},
+		{Ecosystem: EcosystemGo, Name: "github.com/jackc/pgx/v5", Version: "v5.5.0"},
+	})
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	got := parse(t, "package.json", `{
+		"name": "client",
+		"dependencies": {"react": "^18.2.0"},
+		"peerDependencies": {"react-dom": "^18.0.0"},
+		"devDependencies": {"typescript": "~5.3.0", "react": "^18.2.0"}
+	}`)
+	check(t, got, []Dependency{
+		{Ecosystem: EcosystemNPM, Name: "react", Version: "^18.2.0"},
+		{Ecosystem: EcosystemNPM, Name: "react-dom", Version: "^18.0.0"},
+		{Ecosystem: EcosystemNPM, Name: "typescript", Version: "~5.3.0", Dev: true},
+	})
+}
+
+func TestParseRequirements(t *testing.T) {
+	got := parse(t, "requirements.txt", `# зависимости
+-r base.txt
+--index-url https://pypi.example.com/simple
+-e git+https://github.com/org/pkg.git#egg=pkg
+Django>=4.2,<5  # LTS
+requests[socks] == 2.31.0 ; python_version >= "3.8"
+numpy
+`)
+	check(t, got, []Dependency{
+		{Ecosystem: EcosystemPyPI, Name: "django", Version: ">=4.2,<5"},
+		{Ecosystem: EcosystemPyPI, Name: "numpy"},
+		{Ecosystem: EcosystemPyPI, Name: "requests", Version: "== 2.31.0"},
+	})
+}
+
+func TestParsePyProject(t *testing.T) {
+	got := parse(t, "pyproject.toml", `
+[project]
+dependencies = ["httpx (>=0.27)", "pydantic>=2"]
+
+[project.optional-dependencies]
+test = ["pytest>=8", "httpx"]
+
+[tool.poetry.dependencies]
+python = "^3.11"
+FastAPI = "^0.110"
+
+[tool.poetry.group.lint.dependencies]
+ruff = { version = "^0.3" }
+`)
+	check(t, got, []Dependency{
+		{Ecosystem: EcosystemPyPI, Name: "fastapi", Version: "^0.110"},
+		{Ecosystem: EcosystemPyPI, Name: "httpx", Version: ">=0.27"},
+		{Ecosystem: EcosystemPyPI, Name: "pydantic", Version: ">=2"},
+		{Ecosystem: EcosystemPyPI, Name: "pytest", Version: ">=8", Dev: true},
+		{Ecosystem: EcosystemPyPI, Name: "ruff", Version: "^0.3", Dev: true},
+	})
+}
+
+func TestParseCargo(t *testing.T) {
+	got := parse(t, "Cargo.toml", `
+[package]
+name = "app"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+tokio = "1"
+rand_old = { package = "rand", version = "0.7" }
+
+[dev-dependencies]
+criterion = "0.5"
+
+[build-dependencies]
+cc = { git = "https://github.com/rust-lang/cc-rs" }
+`)
+	check(t, got, []Dependency{
+		{Ecosystem: EcosystemCargo, Name: "cc", Dev: true},
+		{Ecosystem: EcosystemCargo, Name: "criterion", Version: "0.5", Dev: true},
+		{Ecosystem: EcosystemCargo, Name: "rand", Version: "0.7"},
+		{Ecosystem: EcosystemCargo, Name: "serde", Version: "1.0"},
+		{Ecosystem: EcosystemCargo, Name: "tokio", Version: "1"},
+	})
+}
+
+func TestParsePom(t *testing.T) {
+	got := parse(t, "pom.xml", `<?xml version="1.0"?>
+<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.2.0</version>
+  </parent>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson</groupId>
+        <artifactId>jackson-bom</artifactId>
+        <version>2.16.0</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+      <version>${guava.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>5.10.1</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+</project>`)
+	check(t, got, []Dependency{
+		{Ecosystem: EcosystemMaven, Name: "com.google.guava:guava"},
+		{Ecosystem: EcosystemMaven, Name: "org.junit.jupiter:junit-jupiter", Version: "5.10.1", Dev: true},
+		{Ecosystem: EcosystemMaven, Name: "org.springframework.boot:spring-boot-starter-web"},
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse("build.gradle", nil); err == nil {
+		t.Error("Parse of unsupported file succeeded")
+	}
+	for _, f := range []string{"package.json", "pyproject.toml", "Cargo.toml", "pom.xml"} {
+		if _, err := Parse(f, []byte("{[<")); err == nil {
+			t.Errorf("Parse(%s) of malformed data succeeded", f)
+		}
+	}
+}
+
+func TestDedupePrefersRuntime(t *testing.T) {
+	got := dedupe([]Dependency{
+		{Ecosystem: EcosystemNPM, Name: "react", Version: "^18.2.0", Dev: true},
+		{Ecosystem: EcosystemNPM, Name: "react", Version: "^18.2.0"},
+		{Ecosystem: EcosystemPyPI, Name: "react"},
+	})
+	check(t, got, []Dependency{
+		{Ecosystem: EcosystemNPM, Name: "react", Version: "^18.2.0"},
+		{Ecosystem: EcosystemPyPI, Name: "react"},
+	})
+}
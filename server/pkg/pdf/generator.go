@@ -8,12 +8,57 @@ import (
 
 type ReportData struct {
 	Username        string
+	Period          string // week, month, year; пусто — year
 	TotalRepos      int
 	TotalStars      int
 	TotalForks      int
 	ContributionSum int
 	TopRepos        []RepoSummary
 	Languages       []LangSummary
+	Frameworks      []TechSummary
+	Libraries       []TechSummary
+	CurrentStreak   StreakSummary
+	LongestStreak   StreakSummary
+	Trends          []TrendSummary // относительно предыдущего периода
+	Goals           []GoalSummary
+	Members         []MemberSummary // только в отчётах команды
+}
+
+// MemberSummary — вклад участника команды за период.
+type MemberSummary struct {
+	Username      string
+	Role          string
+	Contributions int
+	Commits       int
+	PRs           int
+	Share         float64 // %
+}
+
+type GoalSummary struct {
+	Label  string // «20 commits per week»
+	Value  int
+	Target int
+	Met    bool
+}
+
+type TrendSummary struct {
+	Label    string
+	Current  int
+	Previous int
+	Percent  *float64 // nil — в прошлом периоде был 0
+}
+
+// StreakSummary — серия активных дней; Length 0 — серии нет.
+type StreakSummary struct {
+	Length int
+	Start  string
+	End    string
+}
+
+type TechSummary struct {
+	Name      string
+	Ecosystem string
+	Repos     int
 }
 
 type RepoSummary struct {
@@ -28,6 +73,34 @@ type LangSummary struct {
 	Percent  float64
 }
 
+// ComparisonData — сравнение пользователей: столбцы таблиц идут в порядке Users.
+type ComparisonData struct {
+	Period    string
+	Users     []ComparedSummary
+	Languages []string
+	Buckets   []string // подписи оси рядов
+}
+
+// ComparedSummary — пользователь сравнения; nil-поля скрыты в его публичном профиле.
+type ComparedSummary struct {
+	Username      string
+	Access        string
+	Contributions *int
+	Commits       *int
+	PRs           *int
+	Issues        *int
+	Reviews       *int
+	ActiveDays    *int
+	CurrentStreak *int
+	LongestStreak *int
+	Languages     []float64 // %, по ComparisonData.Languages
+	Series        []float64 // нормированные 0–1, по ComparisonData.Buckets
+}
+
+// seriesColors — цвета линий пользователей на графике сравнения.
+var seriesColors = [][3]int{{33, 102, 172}, {214, 96, 77}, {27, 158, 119}, {117, 112, 179}, {230, 171, 2},
+	{102, 166, 30}, {231, 41, 138}, {166, 118, 29}, {102, 102, 102}, {0, 139, 139}}
+
 type Generator struct{}
 
 func NewGenerator() *Generator {
@@ -35,6 +108,9 @@ func NewGenerator() *Generator {
 }
 
 func (g *Generator) Generate(username string, data interface{}) ([]byte, error) {
+	if cd, ok := data.(*ComparisonData); ok {
+		return g.generateComparison(cd)
+	}
 	rd, ok := data.(*ReportData)
 	if !ok {
 		return nil, fmt.Errorf("invalid report data")
@@ -48,7 +124,11 @@ func (g *Generator) Generate(username string, data interface{}) ([]byte, error)
 	pdf.CellFormat(0, 8, fmt.Sprintf("Total Repositories: %d", rd.TotalRepos), "", 1, "L", false, 0, "")
 	pdf.CellFormat(0, 8, fmt.Sprintf("Total Stars: %d", rd.TotalStars), "", 1, "L", false, 0, "")
 	pdf.CellFormat(0, 8, fmt.Sprintf("Total Forks: %d", rd.TotalForks), "", 1, "L", false, 0, "")
-	pdf.CellFormat(0, 8, fmt.Sprintf("Contributions (year): %d", rd.ContributionSum), "", 1, "L", false, 0, "")
+	period := rd.Period
+	if period == "" {
+		period = "year"
+	}
+	pdf.CellFormat(0, 8, fmt.Sprintf("Contributions (%s): %d", period, rd.ContributionSum), "", 1, "L", false, 0, "")
 	pdf.Ln(5)
 	pdf.SetFont("Arial", "B", 12)
 	pdf.CellFormat(0, 8, "Top Repositories", "", 1, "L", false, 0, "")
@@ -63,6 +143,168 @@ func (g *Generator) Generate(username string, data interface{}) ([]byte, error)
 	for _, l := range rd.Languages {
 		pdf.CellFormat(0, 6, fmt.Sprintf("- %s: %.1f%%", l.Language, l.Percent), "", 1, "L", false, 0, "")
 	}
+	if len(rd.Frameworks) > 0 || len(rd.Libraries) > 0 {
+		pdf.Ln(3)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, "Tech Stack", "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		for _, t := range rd.Frameworks {
+			pdf.CellFormat(0, 6, fmt.Sprintf("- %s (%s): %d repos", t.Name, t.Ecosystem, t.Repos), "", 1, "L", false, 0, "")
+		}
+		if len(rd.Libraries) > 0 {
+			pdf.SetFont("Arial", "I", 10)
+			pdf.CellFormat(0, 6, "Libraries", "", 1, "L", false, 0, "")
+			pdf.SetFont("Arial", "", 10)
+			for _, t := range rd.Libraries {
+				pdf.CellFormat(0, 6, fmt.Sprintf("- %s (%s): %d repos", t.Name, t.Ecosystem, t.Repos), "", 1, "L", false, 0, "")
+			}
+		}
+	}
+	if len(rd.Trends) > 0 {
+		pdf.Ln(3)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, "Trend vs previous "+period, "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		for _, t := range rd.Trends {
+			line := fmt.Sprintf("- %s: %d (was %d, %+d", t.Label, t.Current, t.Previous, t.Current-t.Previous)
+			if t.Percent != nil {
+				line += fmt.Sprintf(", %+.1f%%", *t.Percent)
+			}
+			pdf.CellFormat(0, 6, line+")", "", 1, "L", false, 0, "")
+		}
+	}
+	if len(rd.Goals) > 0 {
+		pdf.Ln(3)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, "Goals", "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		for _, g := range rd.Goals {
+			status := "in progress"
+			if g.Met {
+				status = "met"
+			}
+			pdf.CellFormat(0, 6, fmt.Sprintf("- %s: %d/%d (%s)", g.Label, g.Value, g.Target, status), "", 1, "L", false, 0, "")
+		}
+	}
+	if len(rd.Members) > 0 {
+		pdf.Ln(3)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, "Members", "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		for _, m := range rd.Members {
+			pdf.CellFormat(0, 6, fmt.Sprintf("- %s (%s): %d contributions, %d commits, %d PRs (%.1f%%)",
+				m.Username, m.Role, m.Contributions, m.Commits, m.PRs, m.Share), "", 1, "L", false, 0, "")
+		}
+	}
+	if rd.LongestStreak.Length > 0 {
+		pdf.Ln(3)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, "Streaks", "", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		pdf.CellFormat(0, 6, fmt.Sprintf("- Current: %d days", rd.CurrentStreak.Length), "", 1, "L", false, 0, "")
+		pdf.CellFormat(0, 6, fmt.Sprintf("- Longest: %d days (%s - %s)", rd.LongestStreak.Length, rd.LongestStreak.Start, rd.LongestStreak.End), "", 1, "L", false, 0, "")
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// generateComparison — альбомный лист: таблица показателей, языки и нормированные ряды вкладов.
+func (g *Generator) generateComparison(cd *ComparisonData) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 16)
+	pdf.CellFormat(0, 10, "DevSync Comparison", "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(0, 7, "Period: "+cd.Period, "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	const labelW = 45.0
+	colW := (277 - labelW) / float64(len(cd.Users))
+	header := func(title string) {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(labelW, 7, title, "1", 0, "L", false, 0, "")
+		for _, u := range cd.Users {
+			pdf.CellFormat(colW, 7, fmt.Sprintf("%s (%s)", u.Username, u.Access), "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 10)
+	}
+	row := func(label string, cell func(u ComparedSummary) string) {
+		pdf.CellFormat(labelW, 6, label, "1", 0, "L", false, 0, "")
+		for _, u := range cd.Users {
+			pdf.CellFormat(colW, 6, cell(u), "1", 0, "C", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	count := func(v *int) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%d", *v)
+	}
+	header("Metric")
+	row("Contributions", func(u ComparedSummary) string { return count(u.Contributions) })
+	row("Commits", func(u ComparedSummary) string { return count(u.Commits) })
+	row("Pull requests", func(u ComparedSummary) string { return count(u.PRs) })
+	row("Issues", func(u ComparedSummary) string { return count(u.Issues) })
+	row("Reviews", func(u ComparedSummary) string { return count(u.Reviews) })
+	row("Active days", func(u ComparedSummary) string { return count(u.ActiveDays) })
+	row("Current streak", func(u ComparedSummary) string { return count(u.CurrentStreak) })
+	row("Longest streak", func(u ComparedSummary) string { return count(u.LongestStreak) })
+
+	if len(cd.Languages) > 0 {
+		pdf.Ln(4)
+		header("Language")
+		for i, lang := range cd.Languages {
+			row(lang, func(u ComparedSummary) string {
+				if u.Languages == nil {
+					return "-"
+				}
+				return fmt.Sprintf("%.1f%%", u.Languages[i])
+			})
+		}
+	}
+
+	if len(cd.Buckets) > 1 {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 8, "Contributions over time (each line scaled to its own peak)", "", 1, "L", false, 0, "")
+		x0, y0, w, h := 20.0, 30.0, 250.0, 130.0
+		pdf.SetDrawColor(180, 180, 180)
+		pdf.Rect(x0, y0, w, h, "D")
+		step := w / float64(len(cd.Buckets)-1)
+		pdf.SetFont("Arial", "", 8)
+		pdf.SetTextColor(0, 0, 0)
+		// Подписи оси: первая, последняя и несколько между ними
+		every := (len(cd.Buckets) + 5) / 6
+		for i, b := range cd.Buckets {
+			if i%every == 0 || i == len(cd.Buckets)-1 {
+				pdf.Text(x0+float64(i)*step-8, y0+h+5, b)
+			}
+		}
+		pdf.SetLineWidth(0.6)
+		for n, u := range cd.Users {
+			c := seriesColors[n%len(seriesColors)]
+			pdf.SetDrawColor(c[0], c[1], c[2])
+			if len(u.Series) == len(cd.Buckets) {
+				for i := 1; i < len(u.Series); i++ {
+					pdf.Line(x0+float64(i-1)*step, y0+h-u.Series[i-1]*h, x0+float64(i)*step, y0+h-u.Series[i]*h)
+				}
+			}
+			// Легенда под графиком; без ряда — раздел скрыт
+			ly := y0 + h + 12 + float64(n)*6
+			pdf.Line(x0, ly-1, x0+10, ly-1)
+			label := u.Username
+			if len(u.Series) != len(cd.Buckets) {
+				label += " (hidden)"
+			}
+			pdf.Text(x0+13, ly, label)
+		}
+	}
+
 	var buf bytes.Buffer
 	if err := pdf.Output(&buf); err != nil {
 		return nil, err